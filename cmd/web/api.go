@@ -0,0 +1,539 @@
+package main
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"vellum.forge/internal/apikey"
+	"vellum.forge/internal/content"
+	"vellum.forge/internal/pagination"
+	"vellum.forge/internal/response"
+)
+
+const (
+	// apiDefaultLimit is the page size of API listings when no limit is given
+	apiDefaultLimit = 15
+
+	// apiMaxLimit is the largest page size a client can ask for
+	apiMaxLimit = 100
+)
+
+// contentFields are the fields of a post or page that can be selected with ?fields=
+var contentFields = []string{
+	"slug", "title", "url", "description", "excerpt", "html", "cover",
+	"date", "updated_at", "status", "tags", "authors", "reading_time", "word_count",
+	"lang", "translations",
+}
+
+// tagFields are the fields of a tag that can be selected with ?fields=
+var tagFields = []string{"slug", "name", "url", "count"}
+
+// apiQuery holds the listing options parsed from an API request's query string
+type apiQuery struct {
+	fields []string  // Fields to include, all when empty
+	tag    string    // Only content with this tag slug
+	author string    // Only content by this author slug
+	since  time.Time // Only content dated at or after this time
+	until  time.Time // Only content dated before this time
+	order  string    // Field to sort by
+	desc   bool      // Sort in descending order
+	page   int
+	limit  int
+}
+
+// parseAPIQuery reads the fields, filter, order and pagination parameters of a listing request.
+// orders lists the fields the resource can be sorted by, the first being the default.
+// Fields sort in ascending order unless they are in descByDefault or the order says otherwise.
+func parseAPIQuery(r *http.Request, fields, orders, descByDefault []string) (apiQuery, error) {
+	values := r.URL.Query()
+	q := apiQuery{page: 1, limit: apiDefaultLimit}
+
+	var err error
+	q.fields, err = parseAPIFields(values.Get("fields"), fields)
+	if err != nil {
+		return q, err
+	}
+
+	q.tag = content.TagSlug(values.Get("tag"))
+	q.author = values.Get("author")
+
+	if since := values.Get("since"); since != "" {
+		q.since, _, err = parseAPIDate(since)
+		if err != nil {
+			return q, fmt.Errorf("invalid since date %q", since)
+		}
+	}
+	if until := values.Get("until"); until != "" {
+		var dateOnly bool
+		q.until, dateOnly, err = parseAPIDate(until)
+		if err != nil {
+			return q, fmt.Errorf("invalid until date %q", until)
+		}
+		if dateOnly {
+			q.until = q.until.AddDate(0, 0, 1) // Include the whole day
+		}
+	}
+
+	order := strings.Fields(values.Get("order"))
+	q.order = orders[0]
+	if len(order) > 0 {
+		q.order = order[0]
+	}
+	if !slices.Contains(orders, q.order) {
+		return q, fmt.Errorf("cannot order by %q, expected one of %s", q.order, strings.Join(orders, ", "))
+	}
+	q.desc = slices.Contains(descByDefault, q.order)
+	if len(order) > 1 {
+		switch strings.ToLower(order[1]) {
+		case "asc":
+			q.desc = false
+		case "desc":
+			q.desc = true
+		default:
+			return q, fmt.Errorf("invalid order direction %q, expected asc or desc", order[1])
+		}
+	}
+
+	if page := values.Get("page"); page != "" {
+		q.page, err = strconv.Atoi(page)
+		if err != nil || q.page < 1 {
+			return q, fmt.Errorf("invalid page %q", page)
+		}
+	}
+	if limit := values.Get("limit"); limit != "" {
+		q.limit, err = strconv.Atoi(limit)
+		if err != nil || q.limit < 1 || q.limit > apiMaxLimit {
+			return q, fmt.Errorf("invalid limit %q, expected 1 to %d", limit, apiMaxLimit)
+		}
+	}
+
+	return q, nil
+}
+
+// parseAPIFields splits a comma-separated field list, checking each is one of known
+func parseAPIFields(list string, known []string) ([]string, error) {
+	if list == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if !slices.Contains(known, field) {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// parseAPIDate accepts a date (2006-01-02) or a full RFC 3339 timestamp, reporting which it was
+func parseAPIDate(s string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	return t, false, err
+}
+
+// matches reports whether content passes the query's tag, author and date filters
+func (q apiQuery) matches(c *content.Content) bool {
+	if q.tag != "" && !slices.ContainsFunc(c.Tags(), func(t *content.Tag) bool { return t.Slug == q.tag }) {
+		return false
+	}
+	if q.author != "" && !slices.ContainsFunc(c.Authors, func(a *content.Author) bool { return a.Slug == q.author }) {
+		return false
+	}
+	if !q.since.IsZero() && c.Frontmatter.Date.Before(q.since) {
+		return false
+	}
+	if !q.until.IsZero() && !c.Frontmatter.Date.Before(q.until) {
+		return false
+	}
+	return true
+}
+
+// sortContent orders a copy of contents as the query asks
+func (q apiQuery) sortContent(contents []*content.Content) []*content.Content {
+	sorted := slices.Clone(contents)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if q.desc {
+			a, b = b, a
+		}
+		switch q.order {
+		case "title":
+			return strings.ToLower(a.Frontmatter.Title) < strings.ToLower(b.Frontmatter.Title)
+		case "slug":
+			return a.Frontmatter.Slug < b.Frontmatter.Slug
+		case "updated_at":
+			return a.ModTime.Before(b.ModTime)
+		default:
+			return a.Frontmatter.Date.Before(b.Frontmatter.Date)
+		}
+	})
+	return sorted
+}
+
+// apiPaginate returns the page of items the query asks for along with the pagination metadata
+func apiPaginate[T any](items []T, q apiQuery) ([]T, map[string]any) {
+	paging := pagination.New("", len(items), q.limit, q.page, 0)
+
+	meta := map[string]any{
+		"page":  paging.CurrentPage,
+		"limit": paging.PerPage,
+		"pages": paging.TotalPages,
+		"total": paging.TotalItems,
+		"next":  nil,
+		"prev":  nil,
+	}
+	if paging.HasNext {
+		meta["next"] = paging.NextPage
+	}
+	if paging.HasPrev {
+		meta["prev"] = min(paging.PrevPage, paging.TotalPages)
+	}
+
+	page := pagination.Paginate(items, paging)
+	if page == nil {
+		page = []T{}
+	}
+	return page, map[string]any{"pagination": meta}
+}
+
+// selectFields keeps only the requested fields of a resource, or all of them if none were requested
+func selectFields(resource map[string]any, fields []string) map[string]any {
+	if len(fields) == 0 {
+		return resource
+	}
+
+	selected := make(map[string]any, len(fields))
+	for _, field := range fields {
+		selected[field] = resource[field]
+	}
+	return selected
+}
+
+// absoluteURL prefixes a site path with the configured base URL
+func (app *application) absoluteURL(path string) string {
+	return strings.TrimRight(app.config.baseURL, "/") + path
+}
+
+// apiSection describes how the API reads posts or pages from a language's content store
+type apiSection struct {
+	key       string // Name of the resources, as the key of responses
+	path      string // Path the content is served under, after the language prefix
+	published func(*content.Store) []*content.Content
+	all       func(*content.Store) []*content.Content
+	lookup    func(*content.Store, string) (*content.Content, bool)
+	preview   func(*content.Store, string) (*content.Content, bool)
+}
+
+var (
+	apiPostSection = apiSection{"posts", "/blog/", (*content.Store).Posts, (*content.Store).AllPosts, (*content.Store).Post, (*content.Store).PreviewPost}
+	apiPageSection = apiSection{"pages", "/", (*content.Store).Pages, (*content.Store).AllPages, (*content.Store).Page, (*content.Store).PreviewPage}
+)
+
+// apiStore returns the content store of the language chosen with ?lang=, the default
+// language's when none is
+func (app *application) apiStore(r *http.Request) (*content.Store, error) {
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		return app.contentStore, nil
+	}
+
+	store, found := app.stores[content.NormalizeLanguage(lang)]
+	if !found {
+		return nil, fmt.Errorf("unknown language %q, expected one of %s", lang, strings.Join(app.config.site.languages, ", "))
+	}
+	return store, nil
+}
+
+// apiContent converts a post or page from store into its API representation
+func (app *application) apiContent(store *content.Store, section apiSection, c *content.Content) map[string]any {
+	prefix := store.URLPrefix()
+
+	tags := []map[string]any{}
+	for _, tag := range c.Tags() {
+		tags = append(tags, map[string]any{"name": tag.Name, "slug": tag.Slug, "url": app.absoluteURL(prefix + tag.URL())})
+	}
+
+	authors := []map[string]any{}
+	for _, author := range c.Authors {
+		authors = append(authors, map[string]any{"slug": author.Slug, "name": author.DisplayName(), "url": app.absoluteURL(prefix + author.URL())})
+	}
+
+	translations := []map[string]any{}
+	for _, t := range app.translations(store, section.lookup, section.path, c.Frontmatter.Slug) {
+		translations = append(translations, map[string]any{"lang": t.Lang, "title": t.Title, "url": app.absoluteURL(t.URL)})
+	}
+
+	var date any
+	if !c.Frontmatter.Date.IsZero() {
+		date = c.Frontmatter.Date
+	}
+
+	return map[string]any{
+		"slug":         c.Frontmatter.Slug,
+		"title":        c.Frontmatter.Title,
+		"url":          app.absoluteURL(prefix + section.path + c.Frontmatter.Slug),
+		"description":  c.Frontmatter.Description,
+		"excerpt":      c.Excerpt,
+		"html":         c.HTML,
+		"cover":        c.Frontmatter.Cover,
+		"date":         date,
+		"updated_at":   c.ModTime,
+		"status":       c.Status(time.Now()),
+		"tags":         tags,
+		"authors":      authors,
+		"reading_time": c.ReadingTime,
+		"word_count":   c.WordCount,
+		"lang":         cmp.Or(store.Language(), app.config.site.languages.Default()),
+		"translations": translations,
+	}
+}
+
+// contentStatuses are the values of the status parameter, all but published need the read_drafts scope
+var contentStatuses = []string{content.StatusPublished, content.StatusDraft, content.StatusScheduled, content.StatusExpired, "all"}
+
+// canReadDrafts reports whether the request's API key may see unpublished content
+func canReadDrafts(r *http.Request) bool {
+	key := contextAPIKey(r)
+	return key != nil && key.HasScope(apikey.ScopeReadDrafts)
+}
+
+// apiContentList serves a filtered, ordered and paginated listing of posts or pages in the
+// requested language. Everyone can list published content, keys with the read_drafts scope
+// the rest too.
+func (app *application) apiContentList(w http.ResponseWriter, r *http.Request, section apiSection) {
+	q, err := parseAPIQuery(r, contentFields, []string{"date", "title", "slug", "updated_at"}, []string{"date", "updated_at"})
+	if err != nil {
+		app.apiError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	store, err := app.apiStore(r)
+	if err != nil {
+		app.apiError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = content.StatusPublished
+	}
+	if !slices.Contains(contentStatuses, status) {
+		app.apiError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid status %q, expected one of %s", status, strings.Join(contentStatuses, ", ")))
+		return
+	}
+
+	contents := section.published(store)
+	if status != content.StatusPublished {
+		if !canReadDrafts(r) {
+			app.apiError(w, r, http.StatusForbidden, fmt.Sprintf("API key does not have the %s scope", apikey.ScopeReadDrafts))
+			return
+		}
+		contents = section.all(store)
+	}
+
+	now := time.Now()
+	var filtered []*content.Content
+	for _, c := range contents {
+		if q.matches(c) && (status == "all" || c.Status(now) == status) {
+			filtered = append(filtered, c)
+		}
+	}
+
+	page, meta := apiPaginate(q.sortContent(filtered), q)
+
+	resources := make([]map[string]any, 0, len(page))
+	for _, c := range page {
+		resources = append(resources, selectFields(app.apiContent(store, section, c), q.fields))
+	}
+
+	app.apiJSON(w, r, map[string]any{section.key: resources, "meta": meta})
+}
+
+// apiContentItem serves a single post or page in the requested language, wrapped in a list like
+// the listings. Keys with the read_drafts scope can also get unpublished content.
+func (app *application) apiContentItem(w http.ResponseWriter, r *http.Request, section apiSection, slug string) {
+	store, err := app.apiStore(r)
+	if err != nil {
+		app.apiError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c, found := section.lookup(store, slug)
+	if !found && canReadDrafts(r) {
+		c, found = section.preview(store, slug)
+	}
+	if !found {
+		app.apiError(w, r, http.StatusNotFound, fmt.Sprintf("%s %q not found", strings.TrimSuffix(section.key, "s"), slug))
+		return
+	}
+
+	fields, err := parseAPIFields(r.URL.Query().Get("fields"), contentFields)
+	if err != nil {
+		app.apiError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resource := selectFields(app.apiContent(store, section, c), fields)
+	app.apiJSON(w, r, map[string]any{section.key: []map[string]any{resource}})
+}
+
+func (app *application) apiPosts(w http.ResponseWriter, r *http.Request) {
+	app.apiContentList(w, r, apiPostSection)
+}
+
+func (app *application) apiPost(w http.ResponseWriter, r *http.Request) {
+	app.apiContentItem(w, r, apiPostSection, chi.URLParam(r, "slug"))
+}
+
+func (app *application) apiPages(w http.ResponseWriter, r *http.Request) {
+	app.apiContentList(w, r, apiPageSection)
+}
+
+func (app *application) apiPage(w http.ResponseWriter, r *http.Request) {
+	app.apiContentItem(w, r, apiPageSection, chi.URLParam(r, "*"))
+}
+
+// apiKey describes the key a request was made with, including its usage counters
+func (app *application) apiKey(w http.ResponseWriter, r *http.Request) {
+	key := contextAPIKey(r)
+
+	origins := key.Origins
+	if origins == nil {
+		origins = []string{}
+	}
+
+	// Usage changes with every request, so this is never cached
+	err := response.JSON(w, http.StatusOK, map[string]any{
+		"key": map[string]any{
+			"name":    key.Name,
+			"scopes":  key.Scopes,
+			"origins": origins,
+			"usage":   key.Usage(),
+		},
+	})
+	if err != nil {
+		app.apiServerError(w, r, err)
+	}
+}
+
+func (app *application) apiTags(w http.ResponseWriter, r *http.Request) {
+	q, err := parseAPIQuery(r, tagFields, []string{"name", "count"}, []string{"count"})
+	if err != nil {
+		app.apiError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	store, err := app.apiStore(r)
+	if err != nil {
+		app.apiError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tags := slices.Clone(store.Tags())
+	sort.SliceStable(tags, func(i, j int) bool {
+		a, b := tags[i], tags[j]
+		if q.desc {
+			a, b = b, a
+		}
+		if q.order == "count" {
+			return a.Count < b.Count
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+
+	page, meta := apiPaginate(tags, q)
+
+	resources := make([]map[string]any, 0, len(page))
+	for _, tag := range page {
+		resources = append(resources, selectFields(map[string]any{
+			"slug":  tag.Slug,
+			"name":  tag.Name,
+			"url":   app.absoluteURL(store.URLPrefix() + tag.URL()),
+			"count": tag.Count,
+		}, q.fields))
+	}
+
+	app.apiJSON(w, r, map[string]any{"tags": resources, "meta": meta})
+}
+
+// apiJSON writes an API response with an ETag, answering a matching If-None-Match with 304 Not Modified.
+// Clients may keep responses but must revalidate them, since content can change at any time.
+// Responses depend on the API key, so shared caches must not keep them.
+func (app *application) apiJSON(w http.ResponseWriter, r *http.Request, data any) {
+	js, err := json.Marshal(data)
+	if err != nil {
+		app.apiServerError(w, r, err)
+		return
+	}
+	sum := sha256.Sum256(js)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	// Replace the no-store default set for API endpoints by cacheControlMiddleware
+	w.Header().Del("Pragma")
+	w.Header().Del("Expires")
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("ETag", etag)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	err = response.JSON(w, http.StatusOK, data)
+	if err != nil {
+		app.apiServerError(w, r, err)
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists the ETag, comparing weakly
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// apiError writes an error response in the same shape for every API endpoint
+func (app *application) apiError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	err := response.JSON(w, status, map[string]any{
+		"errors": []map[string]string{{"message": message}},
+	})
+	if err != nil {
+		app.reportServerError(r, err)
+	}
+}
+
+func (app *application) apiServerError(w http.ResponseWriter, r *http.Request, err error) {
+	app.reportServerError(r, err)
+	app.apiError(w, r, http.StatusInternalServerError, "the server encountered a problem and could not process your request")
+}
+
+func (app *application) apiNotFound(w http.ResponseWriter, r *http.Request) {
+	app.apiError(w, r, http.StatusNotFound, "the requested resource could not be found")
+}
+
+func (app *application) apiMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	app.apiError(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("the %s method is not supported for this resource", r.Method))
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"vellum.forge/internal/apikey"
+	"vellum.forge/internal/assert"
+	"vellum.forge/internal/content"
+)
+
+const (
+	testReaderKey = "reader-secret"
+	testDraftsKey = "drafts-secret"
+	testAdminKey  = "admin-secret"
+	testOriginKey = "origin-secret"
+)
+
+func newTestAPIApplication(t *testing.T) *application {
+	app := newTestApplication(t)
+	app.config.baseURL = "https://example.com"
+
+	keys, err := apikey.NewKeyring([]*apikey.Key{
+		{Name: "reader", Secret: testReaderKey, Scopes: []apikey.Scope{apikey.ScopeReadPosts}},
+		{Name: "drafts", Secret: testDraftsKey, Scopes: []apikey.Scope{apikey.ScopeReadPosts, apikey.ScopeReadDrafts}},
+		{Name: "admin", Secret: testAdminKey, Scopes: []apikey.Scope{apikey.ScopeAdminCache}},
+		{Name: "origin", Secret: testOriginKey, Scopes: []apikey.Scope{apikey.ScopeReadPosts}, Origins: []string{"https://app.example.com"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.apiKeys = keys
+	app.contentStore = newTestContentStore(t, map[string]string{
+		"authors/jane.md": "---\nname: Jane Doe\n---\n\nJane writes about Go.",
+		"blog/first.md":   "---\ntitle: First\ndate: 2024-01-15T10:00:00Z\ntags: [\"Go\"]\nauthor: jane\n---\n\nFirst post",
+		"blog/second.md":  "---\ntitle: Second\ndate: 2024-02-15T10:00:00Z\ntags: [\"go\", \"web\"]\n---\n\nSecond post",
+		"blog/third.md":   "---\ntitle: Third\ndate: 2024-03-15T10:00:00Z\ntags: [\"web\"]\n---\n\nThird post",
+		"blog/draft.md":   "---\ntitle: Draft\ndate: 2024-04-15T10:00:00Z\ndraft: true\n---\n\nWIP",
+		"pages/about.md":  "---\ntitle: About\n---\n\nAbout this site",
+	})
+	return app
+}
+
+// newAPIRequest creates a GET request authenticated with the reader key
+func newAPIRequest(t *testing.T, path string) *http.Request {
+	return newKeyRequest(t, path, testReaderKey)
+}
+
+// newKeyRequest creates a GET request sending key as a Bearer token
+func newKeyRequest(t *testing.T, path, key string) *http.Request {
+	req := newTestRequest(t, http.MethodGet, path)
+	req.Header.Set("Authorization", "Bearer "+key)
+	return req
+}
+
+// apiListResponse decodes a listing, keeping the resources under key
+func apiListResponse(t *testing.T, body, key string) ([]map[string]any, map[string]any) {
+	t.Helper()
+
+	var decoded map[string]any
+	err := json.Unmarshal([]byte(body), &decoded)
+	assert.Nil(t, err)
+
+	var resources []map[string]any
+	items, _ := decoded[key].([]any)
+	for _, item := range items {
+		resources = append(resources, item.(map[string]any))
+	}
+
+	var pagination map[string]any
+	if meta, ok := decoded["meta"].(map[string]any); ok {
+		pagination, _ = meta["pagination"].(map[string]any)
+	}
+
+	return resources, pagination
+}
+
+func slugsOf(resources []map[string]any) []string {
+	var slugs []string
+	for _, resource := range resources {
+		slugs = append(slugs, resource["slug"].(string))
+	}
+	return slugs
+}
+
+func TestAPIPosts(t *testing.T) {
+	app := newTestAPIApplication(t)
+
+	t.Run("Lists published posts newest first", func(t *testing.T) {
+		res := send(t, newAPIRequest(t, "/api/v1/posts"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.Equal(t, res.Header.Get("Content-Type"), "application/json")
+
+		posts, pagination := apiListResponse(t, res.Body, "posts")
+		assert.Equal(t, slugsOf(posts), []string{"third", "second", "first"})
+		assert.Equal[any](t, posts[2]["url"], "https://example.com/blog/first")
+		assert.Equal[any](t, pagination["total"], float64(3))
+	})
+
+	t.Run("Filters by tag, author and date", func(t *testing.T) {
+		tests := []struct {
+			query string
+			want  []string
+		}{
+			{"tag=go", []string{"second", "first"}},
+			{"tag=Web", []string{"third", "second"}},
+			{"author=jane", []string{"first"}},
+			{"since=2024-02-01", []string{"third", "second"}},
+			{"until=2024-02-15", []string{"second", "first"}},
+			{"tag=go&since=2024-02-01T00:00:00Z", []string{"second"}},
+		}
+
+		for _, tt := range tests {
+			res := send(t, newAPIRequest(t, "/api/v1/posts?"+tt.query), app.routes())
+			assert.Equal(t, res.StatusCode, http.StatusOK)
+
+			posts, _ := apiListResponse(t, res.Body, "posts")
+			assert.Equal(t, slugsOf(posts), tt.want)
+		}
+	})
+
+	t.Run("Orders, paginates and selects fields", func(t *testing.T) {
+		res := send(t, newAPIRequest(t, "/api/v1/posts?order=title+asc&limit=2&page=2&fields=slug,title"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		posts, pagination := apiListResponse(t, res.Body, "posts")
+		assert.Equal(t, len(posts), 1)
+		assert.Equal(t, posts[0], map[string]any{"slug": "third", "title": "Third"})
+		assert.Equal[any](t, pagination["pages"], float64(2))
+		assert.Equal[any](t, pagination["prev"], float64(1))
+		assert.Nil(t, pagination["next"])
+	})
+
+	t.Run("Rejects invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"fields=password", "order=random", "order=date+sideways", "page=0", "limit=1000", "since=yesterday"} {
+			res := send(t, newAPIRequest(t, "/api/v1/posts?"+query), app.routes())
+			assert.Equal(t, res.StatusCode, http.StatusBadRequest)
+			assert.True(t, strings.Contains(res.Body, `"errors"`))
+		}
+	})
+
+	t.Run("Gets a single post", func(t *testing.T) {
+		res := send(t, newAPIRequest(t, "/api/v1/posts/first"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		posts, _ := apiListResponse(t, res.Body, "posts")
+		assert.Equal(t, len(posts), 1)
+		assert.Equal[any](t, posts[0]["title"], "First")
+		authors := posts[0]["authors"].([]any)
+		assert.Equal[any](t, authors[0].(map[string]any)["name"], "Jane Doe")
+	})
+
+	t.Run("Returns JSON errors for unknown posts and routes", func(t *testing.T) {
+		for _, path := range []string{"/api/v1/posts/draft", "/api/v1/posts/missing", "/api/v1/unknown"} {
+			res := send(t, newAPIRequest(t, path), app.routes())
+			assert.Equal(t, res.StatusCode, http.StatusNotFound)
+			assert.Equal(t, res.Header.Get("Content-Type"), "application/json")
+		}
+	})
+}
+
+func TestAPIPagesAndTags(t *testing.T) {
+	app := newTestAPIApplication(t)
+
+	t.Run("Lists pages", func(t *testing.T) {
+		res := send(t, newAPIRequest(t, "/api/v1/pages"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		pages, _ := apiListResponse(t, res.Body, "pages")
+		assert.Equal(t, slugsOf(pages), []string{"about"})
+		assert.Equal[any](t, pages[0]["url"], "https://example.com/about")
+	})
+
+	t.Run("Gets a single page", func(t *testing.T) {
+		res := send(t, newAPIRequest(t, "/api/v1/pages/about?fields=title"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		pages, _ := apiListResponse(t, res.Body, "pages")
+		assert.Equal(t, pages[0], map[string]any{"title": "About"})
+	})
+
+	t.Run("Lists tags by name or count", func(t *testing.T) {
+		res := send(t, newAPIRequest(t, "/api/v1/tags"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		tags, _ := apiListResponse(t, res.Body, "tags")
+		assert.Equal(t, slugsOf(tags), []string{"go", "web"})
+		assert.Equal[any](t, tags[0]["count"], float64(2))
+
+		res = send(t, newAPIRequest(t, "/api/v1/tags?order=name+desc"), app.routes())
+		tags, _ = apiListResponse(t, res.Body, "tags")
+		assert.Equal(t, slugsOf(tags), []string{"web", "go"})
+	})
+}
+
+func TestAPILanguages(t *testing.T) {
+	app := newTestAPIApplication(t)
+	app.config.site.languages = content.ParseLanguages("en", "fr")
+	app.config.dataDir = newTestDataDir(t, map[string]string{
+		"blog/hello.md":     "---\ntitle: Hello\ndate: 2024-01-15T10:00:00Z\ntags: [go]\n---\n\nHello there",
+		"blog/hello.fr.md":  "---\ntitle: Bonjour\ndate: 2024-01-15T10:00:00Z\ntags: [go]\n---\n\nBonjour",
+		"blog/salut.md":     "---\ntitle: Salut\nlang: fr\ndate: 2024-01-16T10:00:00Z\n---\n\nSalut",
+		"pages/about.fr.md": "---\ntitle: À propos\n---\n\nÀ propos de nous",
+	})
+	app.stores = app.config.contentStores(content.NewLoader(), app.logger)
+	for _, store := range app.stores {
+		if err := store.Load(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	app.contentStore = app.stores["en"]
+
+	t.Run("Lists the default language without a lang parameter", func(t *testing.T) {
+		res := send(t, newAPIRequest(t, "/api/v1/posts"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		posts, _ := apiListResponse(t, res.Body, "posts")
+		assert.Equal(t, slugsOf(posts), []string{"hello"})
+		assert.Equal[any](t, posts[0]["lang"], "en")
+		assert.Equal[any](t, posts[0]["translations"], []any{
+			map[string]any{"lang": "fr", "title": "Bonjour", "url": "https://example.com/fr/blog/hello"},
+		})
+	})
+
+	t.Run("Chooses the language with the lang parameter", func(t *testing.T) {
+		res := send(t, newAPIRequest(t, "/api/v1/posts?lang=fr&fields=slug,url,lang"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		posts, _ := apiListResponse(t, res.Body, "posts")
+		assert.Equal(t, posts, []map[string]any{
+			{"slug": "salut", "url": "https://example.com/fr/blog/salut", "lang": "fr"},
+			{"slug": "hello", "url": "https://example.com/fr/blog/hello", "lang": "fr"},
+		})
+
+		res = send(t, newAPIRequest(t, "/api/v1/pages/about?lang=fr&fields=title"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		pages, _ := apiListResponse(t, res.Body, "pages")
+		assert.Equal(t, pages[0], map[string]any{"title": "À propos"})
+
+		res = send(t, newAPIRequest(t, "/api/v1/pages/about"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusNotFound)
+
+		res = send(t, newAPIRequest(t, "/api/v1/tags?lang=fr&fields=url"), app.routes())
+		tags, _ := apiListResponse(t, res.Body, "tags")
+		assert.Equal(t, tags, []map[string]any{{"url": "https://example.com/fr/tag/go"}})
+	})
+
+	t.Run("Rejects unknown languages", func(t *testing.T) {
+		res := send(t, newAPIRequest(t, "/api/v1/posts?lang=de"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusBadRequest)
+		assert.True(t, strings.Contains(res.Body, `unknown language \"de\", expected one of en, fr`))
+	})
+}
+
+func TestAPICaching(t *testing.T) {
+	app := newTestAPIApplication(t)
+
+	res := send(t, newAPIRequest(t, "/api/v1/posts"), app.routes())
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.Equal(t, res.Header.Get("Cache-Control"), "private, no-cache")
+	assert.Equal(t, res.Header.Get("Pragma"), "")
+
+	etag := res.Header.Get("ETag")
+	assert.NotEqual(t, etag, "")
+
+	req := newAPIRequest(t, "/api/v1/posts")
+	req.Header.Set("If-None-Match", etag)
+	res = send(t, req, app.routes())
+	assert.Equal(t, res.StatusCode, http.StatusNotModified)
+
+	req = newAPIRequest(t, "/api/v1/posts?tag=go")
+	req.Header.Set("If-None-Match", etag)
+	res = send(t, req, app.routes())
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+}
+
+func TestAPIKeys(t *testing.T) {
+	app := newTestAPIApplication(t)
+
+	t.Run("Rejects requests without a valid key", func(t *testing.T) {
+		res := send(t, newTestRequest(t, http.MethodGet, "/api/v1/posts"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusUnauthorized)
+		assert.Equal(t, res.Header.Get("WWW-Authenticate"), `Bearer realm="api"`)
+
+		res = send(t, newKeyRequest(t, "/api/v1/posts", "wrong"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusUnauthorized)
+	})
+
+	t.Run("Accepts the key as a query parameter", func(t *testing.T) {
+		res := send(t, newTestRequest(t, http.MethodGet, "/api/v1/posts?key="+testReaderKey), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+	})
+
+	t.Run("Requires the scope of the endpoint", func(t *testing.T) {
+		res := send(t, newKeyRequest(t, "/api/v1/posts", testAdminKey), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusForbidden)
+	})
+
+	t.Run("Restricts keys to their origins", func(t *testing.T) {
+		req := newKeyRequest(t, "/api/v1/posts", testOriginKey)
+		req.Header.Set("Origin", "https://evil.example.com")
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusForbidden)
+
+		req = newKeyRequest(t, "/api/v1/posts", testOriginKey)
+		res = send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusForbidden)
+
+		req = newKeyRequest(t, "/api/v1/posts", testOriginKey)
+		req.Header.Set("Origin", "https://app.example.com")
+		res = send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.Equal(t, res.Header.Get("Access-Control-Allow-Origin"), "https://app.example.com")
+	})
+
+	t.Run("Answers preflight requests from allowed origins", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodOptions, "/api/v1/posts")
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		req.Header.Set("Access-Control-Request-Headers", "authorization")
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusNoContent)
+		assert.Equal(t, res.Header.Get("Access-Control-Allow-Origin"), "https://app.example.com")
+		assert.Equal(t, res.Header.Get("Access-Control-Allow-Headers"), "Authorization")
+
+		req = newTestRequest(t, http.MethodOptions, "/api/v1/posts")
+		req.Header.Set("Origin", "https://evil.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		res = send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusForbidden)
+		assert.Equal(t, res.Header.Get("Access-Control-Allow-Origin"), "")
+	})
+
+	t.Run("Varies responses by key", func(t *testing.T) {
+		res := send(t, newAPIRequest(t, "/api/v1/posts"), app.routes())
+		assert.True(t, slices.Contains(res.Header.Values("Vary"), "Authorization"))
+	})
+
+	t.Run("Only shows drafts to keys with the drafts scope", func(t *testing.T) {
+		res := send(t, newAPIRequest(t, "/api/v1/posts?status=draft"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusForbidden)
+
+		res = send(t, newKeyRequest(t, "/api/v1/posts?status=draft", testDraftsKey), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.Equal(t, res.Header.Get("Cache-Control"), "private, no-cache")
+		posts, _ := apiListResponse(t, res.Body, "posts")
+		assert.Equal(t, slugsOf(posts), []string{"draft"})
+		assert.Equal[any](t, posts[0]["status"], "draft")
+
+		res = send(t, newKeyRequest(t, "/api/v1/posts?status=all", testDraftsKey), app.routes())
+		posts, _ = apiListResponse(t, res.Body, "posts")
+		assert.Equal(t, slugsOf(posts), []string{"draft", "third", "second", "first"})
+
+		res = send(t, newAPIRequest(t, "/api/v1/posts/draft"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusNotFound)
+
+		res = send(t, newKeyRequest(t, "/api/v1/posts/draft", testDraftsKey), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+	})
+
+	t.Run("Protects the cache endpoints", func(t *testing.T) {
+		res := send(t, newTestRequest(t, http.MethodGet, "/cache/stats"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusUnauthorized)
+
+		res = send(t, newAPIRequest(t, "/cache/stats"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusForbidden)
+
+		res = send(t, newKeyRequest(t, "/cache/stats", testAdminKey), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		req := newTestRequest(t, http.MethodPost, "/cache/clear")
+		res = send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusUnauthorized)
+	})
+
+	t.Run("Counts requests per key", func(t *testing.T) {
+		app := newTestAPIApplication(t)
+		for range 2 {
+			send(t, newKeyRequest(t, "/api/v1/tags", testDraftsKey), app.routes())
+		}
+
+		res := send(t, newKeyRequest(t, "/api/v1/key", testDraftsKey), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		var body struct {
+			Key struct {
+				Name  string       `json:"name"`
+				Usage apikey.Usage `json:"usage"`
+			} `json:"key"`
+		}
+		err := json.Unmarshal([]byte(res.Body), &body)
+		assert.Nil(t, err)
+		assert.Equal(t, body.Key.Name, "drafts")
+		assert.Equal(t, body.Key.Usage.Requests, int64(3))
+		assert.False(t, body.Key.Usage.LastUsed.IsZero())
+	})
+}
+
+func TestIsApiEndpoint(t *testing.T) {
+	for _, path := range []string{"/api/v1/posts", "/api/v1/posts/hello", "/api/v1/tags", "/cache/stats", "/health"} {
+		assert.True(t, isApiEndpoint(path))
+	}
+	for _, path := range []string{"/", "/blog", "/apiary", "/search"} {
+		assert.False(t, isApiEndpoint(path))
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"vellum.forge/assets"
+	"vellum.forge/internal/content"
+	"vellum.forge/internal/response"
+
+	"github.com/go-chi/chi/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// errCheckFailed is returned by runCheck once it has reported errors, so run exits non-zero
+// without logging anything else
+var errCheckFailed = errors.New("check found problems")
+
+// checkReport is the result of the check subcommand, as written by -json
+type checkReport struct {
+	Posts     int               `json:"posts"`
+	Pages     int               `json:"pages"`
+	Templates int               `json:"templates"`
+	Problems  []content.Problem `json:"problems"`
+}
+
+// runCheck implements the check subcommand, loading all content and theme templates and
+// reporting every problem found. It exits non-zero if there are errors, or warnings too with
+// -strict, so it can gate CI:
+//
+//	web check [-json] [-strict] [-tags data/tags.yaml]
+func runCheck(cfg config, args []string) error {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "write the report as JSON")
+	strict := flags.Bool("strict", false, "fail on warnings as well as errors")
+	tagsFile := flags.String("tags", filepath.Join(cfg.dataDir, "tags.yaml"), "YAML list of the tags posts may use, any tag is allowed if the file doesn't exist")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	knownTags, err := loadTagList(*tagsFile)
+	if err != nil {
+		return fmt.Errorf("failed to load tag list: %w", err)
+	}
+
+	jetRenderer, err := response.NewJetRenderer(filepath.Join(cfg.themeDir, cfg.theme))
+	if err != nil {
+		return fmt.Errorf("failed to initialize Jet renderer: %w", err)
+	}
+
+	// Problems are reported by the check itself rather than logged
+	logger := slog.New(slog.DiscardHandler)
+	stores := cfg.contentStores(content.NewLoader(cfg.parserOptions(jetRenderer)...), logger)
+	app := &application{
+		config:       cfg,
+		logger:       logger,
+		contentStore: stores[cfg.site.languages.Default()],
+		stores:       stores,
+		jetRenderer:  jetRenderer,
+	}
+
+	// Links may lead to content in another language, so every store holds its content before
+	// any is checked
+	for _, store := range app.allStores() {
+		_, err := store.Check(nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to load content: %w", err)
+		}
+	}
+
+	// Every store reads every file, so problems with files in no language or with authors
+	// are found once per language
+	mux := app.routes().(*chi.Mux)
+	var problems []content.Problem
+	seen := make(map[content.Problem]bool)
+	for _, store := range app.allStores() {
+		storeProblems, err := store.Check(knownTags, func(link string) bool {
+			return app.linkExists(mux, link)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to load content: %w", err)
+		}
+		for _, problem := range storeProblems {
+			if !seen[problem] {
+				seen[problem] = true
+				problems = append(problems, problem)
+			}
+		}
+	}
+
+	if _, err := cfg.redirectRules(); err != nil {
+		problems = append(problems, content.Problem{Path: cfg.redirectsFile, Severity: content.SeverityError, Message: err.Error()})
+	}
+
+	templates, err := jetRenderer.CompileAll()
+	if err != nil {
+		return fmt.Errorf("failed to list templates: %w", err)
+	}
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := templates[name]; err != nil {
+			problems = append(problems, content.Problem{Path: name, Severity: content.SeverityError, Message: err.Error()})
+		}
+	}
+
+	report := checkReport{
+		Templates: len(templates),
+		Problems:  problems,
+	}
+	for _, store := range app.allStores() {
+		report.Posts += len(store.AllPosts())
+		report.Pages += len(store.AllPages())
+	}
+	if report.Problems == nil {
+		report.Problems = []content.Problem{}
+	}
+
+	var errorCount, warningCount int
+	for _, problem := range problems {
+		if problem.Severity == content.SeverityWarning {
+			warningCount++
+		} else {
+			errorCount++
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		err = enc.Encode(report)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+		fmt.Printf("Checked %d posts, %d pages and %d templates: %d errors and %d warnings\n", report.Posts, report.Pages, report.Templates, errorCount, warningCount)
+	}
+
+	if errorCount > 0 || (*strict && warningCount > 0) {
+		return errCheckFailed
+	}
+	return nil
+}
+
+// loadTagList reads a YAML list of tag names, returning nil if the file doesn't exist
+func loadTagList(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	err = yaml.Unmarshal(data, &tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return tags, nil
+}
+
+// linkExists reports whether an internal link leads to a route the site serves and, for posts,
+// pages, tags, authors and files, whether the content or file behind it exists in the language
+// of the link's prefix
+func (app *application) linkExists(mux *chi.Mux, link string) bool {
+	rctx := chi.NewRouteContext()
+	pattern := mux.Find(rctx, http.MethodGet, link)
+	slug := rctx.URLParam("slug")
+
+	store := app.contentStore
+	for _, other := range app.allStores() {
+		prefix := other.URLPrefix()
+		if prefix != "" && (link == prefix || strings.HasPrefix(link, prefix+"/")) {
+			store = other
+			if pattern != "" {
+				pattern = cmp.Or(strings.TrimPrefix(pattern, prefix), "/")
+			}
+		}
+	}
+
+	switch pattern {
+	case "":
+		return false
+	case "/blog/{slug}":
+		_, found := store.PreviewPost(slug)
+		return found
+	case "/*":
+		_, found := store.PreviewPage(rctx.URLParam("*"))
+		return found
+	case "/tag/{slug}", "/tag/{slug}/page/{page}":
+		_, found := store.Tag(slug)
+		return found
+	case "/author/{slug}", "/author/{slug}/page/{page}":
+		_, found := store.Author(slug)
+		return found
+	case "/images/*":
+		return app.fileExists(filepath.Join(app.config.dataDir, "attachments"), rctx.URLParam("*"))
+	case "/themes/*":
+		return app.fileExists(filepath.Join(app.config.themeDir, app.config.theme, "assets"), rctx.URLParam("*"))
+	case "/static/*":
+		info, err := fs.Stat(assets.EmbeddedFiles, strings.TrimPrefix(link, "/"))
+		return err == nil && !info.IsDir()
+	}
+
+	return true
+}
+
+// fileExists reports whether a requested path is a file within dir, as the asset handlers check
+func (app *application) fileExists(dir, requestedPath string) bool {
+	cleanPath, err := app.validateAssetPath(requestedPath)
+	if err != nil {
+		return false
+	}
+
+	fullPath := filepath.Join(dir, cleanPath)
+	if !app.isPathSafe(dir, fullPath) {
+		return false
+	}
+
+	info, err := os.Stat(fullPath)
+	return err == nil && !info.IsDir()
+}
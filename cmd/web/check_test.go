@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"vellum.forge/internal/assert"
+	"vellum.forge/internal/content"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRunCheck(t *testing.T) {
+	newConfig := func(t *testing.T, files map[string]string) config {
+		var cfg config
+		cfg.dataDir = t.TempDir()
+		cfg.themeDir = "../../themes"
+		cfg.theme = "default"
+		cfg.markdown.options = content.DefaultMarkdownOptions()
+
+		for name, body := range files {
+			path := filepath.Join(cfg.dataDir, name)
+			err := os.MkdirAll(filepath.Dir(path), 0o755)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = os.WriteFile(path, []byte(body), 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return cfg
+	}
+
+	t.Run("Passes clean content", func(t *testing.T) {
+		cfg := newConfig(t, map[string]string{
+			"blog/first.md":         "---\ntitle: First\ndate: 2024-01-15T10:00:00Z\ntags: [\"go\"]\ncover: /images/cover.jpg\n---\n\nSee [about](/about), [go](/tag/go), [tags](/tags) and [css](/themes/css/theme.css)",
+			"pages/about.md":        "---\ntitle: About\n---\n\nBack to [[first]]",
+			"attachments/cover.jpg": "jpg",
+			"tags.yaml":             "- Go\n",
+		})
+
+		err := runCheck(cfg, []string{"-json"})
+		assert.Nil(t, err)
+	})
+
+	t.Run("Checks links in every language", func(t *testing.T) {
+		cfg := newConfig(t, map[string]string{
+			"blog/first.md":     "---\ntitle: First\ndate: 2024-01-15T10:00:00Z\n---\n\nSee [about](/about), [accueil](/fr) and [en français](/fr/blog/first)",
+			"blog/first.fr.md":  "---\ntitle: Premier\ndate: 2024-01-15T10:00:00Z\n---\n\nVoir [[about]] et [in English](/blog/first)",
+			"pages/about.md":    "---\ntitle: About\n---\n\nAbout",
+			"pages/about.fr.md": "---\ntitle: À propos\n---\n\nÀ propos",
+		})
+		cfg.site.languages = content.ParseLanguages("en", "fr")
+
+		err := runCheck(cfg, []string{"-json"})
+		assert.Nil(t, err)
+
+		cfg = newConfig(t, map[string]string{
+			"blog/first.fr.md": "---\ntitle: Premier\ndate: 2024-01-15T10:00:00Z\n---\n\nVoir [la page](/fr/missing)",
+			"blog/hallo.md":    "---\ntitle: Hallo\nlang: de\n---\n\nHallo",
+		})
+		cfg.site.languages = content.ParseLanguages("en", "fr")
+
+		err = runCheck(cfg, nil)
+		assert.True(t, errors.Is(err, errCheckFailed))
+	})
+
+	t.Run("Fails on warnings only when strict", func(t *testing.T) {
+		cfg := newConfig(t, map[string]string{
+			"blog/first.md": "---\ntitle: First\ndate: 2024-01-15T10:00:00Z\nauthor: ghost\n---\n\nSee [[nowhere]]",
+		})
+
+		err := runCheck(cfg, nil)
+		assert.Nil(t, err)
+
+		err = runCheck(cfg, []string{"-strict"})
+		assert.True(t, errors.Is(err, errCheckFailed))
+	})
+
+	t.Run("Fails on problems", func(t *testing.T) {
+		cfg := newConfig(t, map[string]string{
+			"blog/first.md": "---\ntitle: First\ndate: 2024-01-15T10:00:00Z\n---\n\nSee [missing](/missing) and [[nowhere]]",
+		})
+
+		err := runCheck(cfg, nil)
+		assert.True(t, errors.Is(err, errCheckFailed))
+	})
+}
+
+func TestLinkExists(t *testing.T) {
+	app := newTestApplication(t)
+	app.contentStore = newTestContentStore(t, map[string]string{
+		"blog/first.md":  "---\ntitle: First\ndate: 2024-01-15T10:00:00Z\ntags: [\"go\"]\n---\n\nFirst",
+		"blog/draft.md":  "---\ntitle: Draft\ndate: 2024-01-15T10:00:00Z\ndraft: true\n---\n\nDraft",
+		"pages/about.md": "---\ntitle: About\n---\n\nAbout",
+	})
+	mux := app.routes().(*chi.Mux)
+
+	tests := []struct {
+		link   string
+		exists bool
+	}{
+		{"/", true},
+		{"/blog", true},
+		{"/blog/first", true},
+		{"/blog/draft", true},
+		{"/blog/missing", false},
+		{"/about", true},
+		{"/missing", false},
+		{"/tag/go", true},
+		{"/tag/rust", false},
+		{"/tags", true},
+		{"/blog/2024/01", true},
+		{"/images/missing.jpg", false},
+		{"/static/missing.css", false},
+		{"/api/v1/posts", true},
+		{"/api/v1/nothing", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			assert.Equal(t, app.linkExists(mux, tt.link), tt.exists)
+		})
+	}
+}
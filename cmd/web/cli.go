@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"vellum.forge/internal/content"
+	"vellum.forge/internal/preview"
+	"vellum.forge/internal/response"
+)
+
+// runPreview implements the preview subcommand, printing a signed link that lets anyone
+// holding it view a draft or otherwise unpublished post or page until it expires. The link is
+// for the first language holding the slug, unless -lang picks one:
+//
+//	web preview [-ttl 24h] [-lang fr] <slug>
+func runPreview(cfg config, logger *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("preview", flag.ContinueOnError)
+	ttl := flags.Duration("ttl", 24*time.Hour, "how long the preview link stays valid")
+	lang := flags.String("lang", "", "language of the post or page")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: web preview [-ttl 24h] [-lang fr] <slug>")
+	}
+	slug := flags.Arg(0)
+
+	if !cfg.previewsEnabled() {
+		return errors.New("set COOKIE_SECRET_KEY to a secret of your own before creating preview links, the default one is public")
+	}
+
+	langs := []string(cfg.site.languages)
+	if *lang != "" {
+		if !cfg.site.languages.Contains(*lang) {
+			return fmt.Errorf("unknown language %q", *lang)
+		}
+		langs = []string{content.NormalizeLanguage(*lang)}
+	} else if len(langs) == 0 {
+		langs = []string{""}
+	}
+
+	// Load content the way the server does, so the same files load
+	jetRenderer, err := response.NewJetRenderer(filepath.Join(cfg.themeDir, cfg.theme))
+	if err != nil {
+		return fmt.Errorf("failed to initialize Jet renderer: %w", err)
+	}
+
+	stores := cfg.contentStores(content.NewLoader(cfg.parserOptions(jetRenderer)...), logger)
+	for _, lang := range langs {
+		store := stores[lang]
+		err = store.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load content: %w", err)
+		}
+
+		var path string
+		if _, found := store.PreviewPost(slug); found {
+			path = store.URLPrefix() + "/blog/" + slug
+		} else if _, found := store.PreviewPage(slug); found {
+			path = store.URLPrefix() + "/" + slug
+		} else {
+			continue
+		}
+
+		fmt.Println(preview.URL(cfg.baseURL, path, time.Now().Add(*ttl), cfg.cookie.secretKey))
+		return nil
+	}
+
+	return fmt.Errorf("no post or page with slug %q", slug)
+}
@@ -33,6 +33,10 @@ func (app *application) serverError(w http.ResponseWriter, r *http.Request, err
 }
 
 func (app *application) notFound(w http.ResponseWriter, r *http.Request) {
+	if app.redirect(w, r) {
+		return
+	}
+
 	data := app.newTemplateData(r)
 
 	err := app.jetRenderer.RenderPage(w, http.StatusNotFound, data, "pages/errors/404.jet")
@@ -41,6 +45,15 @@ func (app *application) notFound(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (app *application) gone(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+
+	err := app.jetRenderer.RenderPage(w, http.StatusGone, data, "pages/errors/410.jet")
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
 func (app *application) badRequest(w http.ResponseWriter, r *http.Request, err error) {
 	data := app.newTemplateData(r)
 	data["ErrorMessage"] = err.Error()
@@ -1,18 +1,24 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"vellum.forge/internal/cache"
+	"vellum.forge/internal/content"
 	"vellum.forge/internal/feed"
+	"vellum.forge/internal/pagination"
+	"vellum.forge/internal/preview"
+	"vellum.forge/internal/response"
 	"vellum.forge/internal/sitemap"
 	"vellum.forge/internal/version"
 )
@@ -39,7 +45,7 @@ func (app *application) renderWithCache(w http.ResponseWriter, r *http.Request,
 		}
 
 		// Set caching headers
-		cache.SetCacheHeaders(w, entry, time.Duration(app.config.cacheTTL)*time.Second)
+		cache.SetCacheHeaders(w, entry, app.pageCacheTTL())
 
 		// Write cached response
 		return cache.WriteEntryToResponse(w, entry)
@@ -58,31 +64,42 @@ func (app *application) renderWithCache(w http.ResponseWriter, r *http.Request,
 
 	// Only cache successful responses
 	if statusCode == http.StatusOK {
+		ttl := app.pageCacheTTL()
+
 		// Create cache entry
-		entry := cache.CreateCacheEntry(body, headers, statusCode, time.Duration(app.config.cacheTTL)*time.Second)
+		entry := cache.CreateCacheEntry(body, headers, statusCode, ttl)
 
 		// Set caching headers
-		cache.SetCacheHeaders(responseCapture, entry, time.Duration(app.config.cacheTTL)*time.Second)
+		cache.SetCacheHeaders(responseCapture, entry, ttl)
 
 		// Store in cache
 		app.cache.Set(cacheKey, entry)
-		app.logger.Info("Cache store", "path", r.URL.Path, "size", len(body), "ttl", time.Duration(app.config.cacheTTL)*time.Second)
+		app.logger.Info("Cache store", "path", r.URL.Path, "size", len(body), "ttl", ttl)
 	}
 
 	// Write response to client
 	return responseCapture.Flush()
 }
 
+// pageCacheTTL returns how long a rendered page may be cached. The configured TTL is cut
+// short by the next scheduled publish or expiry, so listings change on time without a manual clear.
+func (app *application) pageCacheTTL() time.Duration {
+	ttl := time.Duration(app.config.cacheTTL) * time.Second
+	for _, store := range app.allStores() {
+		if next := store.NextChange(); !next.IsZero() {
+			ttl = min(ttl, time.Until(next))
+		}
+	}
+	return ttl
+}
+
 func (app *application) home(w http.ResponseWriter, r *http.Request) {
 	var cacheKey string
 	var err error
 
 	// Build cache key if caching is enabled
 	if app.cache != nil && !cache.ShouldBypass(r) {
-		cacheKey, err = app.cacheKeyBuilder.BuildKeyForHome(r)
-		if err != nil {
-			app.logger.Warn("Failed to build cache key for home page", "error", err)
-		}
+		cacheKey = app.cacheKeyBuilder.BuildKeyForHome(r, app.store(r).Generation())
 	}
 
 	renderFunc := func(writer http.ResponseWriter) error {
@@ -102,30 +119,118 @@ func (app *application) home(w http.ResponseWriter, r *http.Request) {
 }
 
 func (app *application) blogIndex(w http.ResponseWriter, r *http.Request) {
+	posts := app.store(r).Posts()
+
+	// First check if the requested page exists - don't cache 404s
+	paging, ok := app.paginate(w, r, "/blog", len(posts))
+	if !ok {
+		return
+	}
+
 	var cacheKey string
 	var err error
 
-	fmt.Println("blogIndex", app.cache != nil, cache.ShouldBypass(r))
-
 	// Build cache key if caching is enabled
 	if app.cache != nil && !cache.ShouldBypass(r) {
-		cacheKey, err = app.cacheKeyBuilder.BuildKeyForBlogIndex(r)
-		if err != nil {
-			app.logger.Warn("Failed to build cache key for blog index", "error", err)
-		}
+		cacheKey = app.cacheKeyBuilder.BuildKeyForBlogIndex(r, app.store(r).Generation(), paging.CurrentPage)
 	}
 
 	renderFunc := func(writer http.ResponseWriter) error {
 		data := app.newTemplateData(r)
+		data["BlogPosts"] = pagination.Paginate(posts, paging)
+		data["Pagination"] = paging
+		return app.jetRenderer.RenderPage(writer, http.StatusOK, data, "pages/blog/index.jet")
+	}
+
+	if cacheKey != "" {
+		err = app.renderWithCache(w, r, cacheKey, renderFunc)
+	} else {
+		err = renderFunc(w)
+	}
+
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+func (app *application) blogArchive(w http.ResponseWriter, r *http.Request) {
+	yearParam := chi.URLParam(r, "year")
+	monthParam := chi.URLParam(r, "month")
 
-		// Load blog posts from content directory (with app.config.dataDir as base directory)
-		blogPosts, _, err := app.contentLoader.LoadBlogPosts(app.config.dataDir)
+	// A post whose slug is a four-digit number takes precedence over its year's listing
+	if yearParam != "" && monthParam == "" && chi.URLParam(r, "page") == "" {
+		if _, found := app.store(r).Post(yearParam); found || r.URL.Query().Has(preview.QueryParam) {
+			chi.RouteContext(r.Context()).URLParams.Add("slug", yearParam)
+			app.blogPost(w, r)
+			return
+		}
+	}
+
+	// Without a year this is the archive overview, otherwise a listing of the period's posts
+	var posts []*content.Content
+	var period, baseURL string
+	if yearParam != "" {
+		year, err := strconv.Atoi(yearParam)
 		if err != nil {
-			return err
+			app.notFound(w, r)
+			return
 		}
 
-		data["BlogPosts"] = blogPosts
-		return app.jetRenderer.RenderPage(writer, http.StatusOK, data, "pages/blog/index.jet")
+		if monthParam == "" {
+			posts = app.store(r).PostsByYear(year)
+			period = yearParam
+			baseURL = fmt.Sprintf("/blog/%04d", year)
+		} else {
+			month, err := strconv.Atoi(monthParam)
+			if err != nil || month < 1 || month > 12 {
+				app.notFound(w, r)
+				return
+			}
+			posts = app.store(r).PostsByMonth(year, time.Month(month))
+			locale := app.jetRenderer.Locale(app.language(r))
+			period = locale.FormatDate(time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC), locale.T("month_format"))
+			baseURL = fmt.Sprintf("/blog/%04d/%02d", year, month)
+		}
+
+		// Periods without posts don't exist - don't cache 404s
+		if len(posts) == 0 {
+			app.notFound(w, r)
+			return
+		}
+	}
+
+	var paging *pagination.Pagination
+	if period != "" {
+		var ok bool
+		paging, ok = app.paginate(w, r, baseURL, len(posts))
+		if !ok {
+			return
+		}
+	}
+
+	var cacheKey string
+	var err error
+
+	// Build cache key if caching is enabled
+	if app.cache != nil && !cache.ShouldBypass(r) {
+		page := 0
+		if paging != nil {
+			page = paging.CurrentPage
+		}
+
+		cacheKey = app.cacheKeyBuilder.BuildKeyForListingPage(r, "pages/blog/archive.jet", app.store(r).Generation(), page)
+	}
+
+	renderFunc := func(writer http.ResponseWriter) error {
+		data := app.newTemplateData(r)
+		data["Archive"] = app.store(r).Archive()
+		data["Period"] = period
+		if paging != nil {
+			data["BlogPosts"] = pagination.Paginate(posts, paging)
+			data["Pagination"] = paging
+		}
+
+		return app.jetRenderer.RenderPage(writer, http.StatusOK, data, "pages/blog/archive.jet")
 	}
 
 	if cacheKey != "" {
@@ -142,17 +247,24 @@ func (app *application) blogIndex(w http.ResponseWriter, r *http.Request) {
 func (app *application) blogPost(w http.ResponseWriter, r *http.Request) {
 	slug := chi.URLParam(r, "slug")
 
+	// Signed preview links can show drafts and unpublished posts
+	if r.URL.Query().Has(preview.QueryParam) {
+		app.renderPreview(w, r, app.store(r).PreviewPost, slug, "Post", "pages/blog/post.jet")
+		return
+	}
+
 	// First check if the blog post exists - don't cache 404s
-	blogPost, _, err := app.contentLoader.LoadBlogPost(app.config.dataDir, slug)
-	if err != nil {
+	blogPost, found := app.store(r).Post(slug)
+	if !found {
 		app.notFound(w, r)
 		return
 	}
 
 	// Now that we know it exists, build cache key if caching is enabled
 	var cacheKey string
+	var err error
 	if app.cache != nil && !cache.ShouldBypass(r) {
-		cacheKey, err = app.cacheKeyBuilder.BuildKeyForBlogPost(r, slug)
+		cacheKey, err = app.cacheKeyBuilder.BuildKeyForBlogPost(r, blogPost.Path)
 		if err != nil {
 			app.logger.Warn("Failed to build cache key for blog post", "slug", slug, "error", err)
 		}
@@ -161,6 +273,7 @@ func (app *application) blogPost(w http.ResponseWriter, r *http.Request) {
 	renderFunc := func(writer http.ResponseWriter) error {
 		data := app.newTemplateData(r)
 		data["Post"] = blogPost
+		data["Translations"] = app.translations(app.store(r), (*content.Store).Post, "/blog/", slug)
 
 		return app.jetRenderer.RenderPage(writer, http.StatusOK, data, "pages/blog/post.jet")
 	}
@@ -176,20 +289,29 @@ func (app *application) blogPost(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// page serves the page at the request path, which the catch-all route passes on in full so
+// pages in sections are served at nested URLs such as /docs/install
 func (app *application) page(w http.ResponseWriter, r *http.Request) {
-	slug := chi.URLParam(r, "slug")
+	slug := chi.URLParam(r, "*")
+
+	// Signed preview links can show drafts and unpublished pages
+	if r.URL.Query().Has(preview.QueryParam) {
+		app.renderPreview(w, r, app.store(r).PreviewPage, slug, "Page", "pages/page.jet")
+		return
+	}
 
 	// First check if the page exists - don't cache 404s
-	page, _, err := app.contentLoader.LoadPage(app.config.dataDir, slug)
-	if err != nil {
+	page, found := app.store(r).Page(slug)
+	if !found {
 		app.notFound(w, r)
 		return
 	}
 
 	// Now that we know it exists, build cache key if caching is enabled
 	var cacheKey string
+	var err error
 	if app.cache != nil && !cache.ShouldBypass(r) {
-		cacheKey, err = app.cacheKeyBuilder.BuildKeyForPage(r, slug)
+		cacheKey, err = app.cacheKeyBuilder.BuildKeyForPage(r, page.Path)
 		if err != nil {
 			app.logger.Warn("Failed to build cache key for page", "slug", slug, "error", err)
 		}
@@ -198,6 +320,7 @@ func (app *application) page(w http.ResponseWriter, r *http.Request) {
 	renderFunc := func(writer http.ResponseWriter) error {
 		data := app.newTemplateData(r)
 		data["Page"] = page
+		data["Translations"] = app.translations(app.store(r), (*content.Store).Page, "/", slug)
 
 		return app.jetRenderer.RenderPage(writer, http.StatusOK, data, "pages/page.jet")
 	}
@@ -213,6 +336,259 @@ func (app *application) page(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// renderPreview renders content looked up by slug for a request carrying a valid preview token.
+// Previews never go through the cache and ask browsers and crawlers not to keep them, and are
+// disabled while the default, public cookie secret is in use.
+func (app *application) renderPreview(w http.ResponseWriter, r *http.Request, lookup func(slug string) (*content.Content, bool), slug, name, template string) {
+	if !app.config.previewsEnabled() {
+		app.notFound(w, r)
+		return
+	}
+
+	err := preview.Verify(r.URL.Path, r.URL.Query().Get(preview.QueryParam), time.Now(), app.config.cookie.secretKey)
+	if err != nil {
+		app.logger.Warn("Rejected preview link", "path", r.URL.Path, "error", err)
+		app.notFound(w, r)
+		return
+	}
+
+	item, found := lookup(slug)
+	if !found {
+		app.notFound(w, r)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data[name] = item
+	data["Preview"] = true
+
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+
+	err = app.jetRenderer.RenderPage(w, http.StatusOK, data, template)
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+func (app *application) authorPage(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+
+	// First check if the author exists - don't cache 404s
+	author, found := app.store(r).Author(slug)
+	if !found {
+		app.notFound(w, r)
+		return
+	}
+
+	posts := app.store(r).PostsByAuthor(slug)
+
+	paging, ok := app.paginate(w, r, author.URL(), len(posts))
+	if !ok {
+		return
+	}
+
+	// Now that we know it exists, build cache key if caching is enabled
+	var cacheKey string
+	var err error
+	if app.cache != nil && !cache.ShouldBypass(r) {
+		cacheKey = app.cacheKeyBuilder.BuildKeyForListingPage(r, "pages/author.jet", app.store(r).Generation(), paging.CurrentPage)
+	}
+
+	renderFunc := func(writer http.ResponseWriter) error {
+		data := app.newTemplateData(r)
+		data["Author"] = author
+		data["BlogPosts"] = pagination.Paginate(posts, paging)
+		data["Pagination"] = paging
+
+		return app.jetRenderer.RenderPage(writer, http.StatusOK, data, "pages/author.jet")
+	}
+
+	if cacheKey != "" {
+		err = app.renderWithCache(w, r, cacheKey, renderFunc)
+	} else {
+		err = renderFunc(w)
+	}
+
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+func (app *application) tagPage(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+
+	// First check if the tag exists - don't cache 404s
+	tag, found := app.store(r).Tag(slug)
+	if !found {
+		app.notFound(w, r)
+		return
+	}
+
+	posts := app.store(r).PostsByTag(slug)
+
+	paging, ok := app.paginate(w, r, tag.URL(), len(posts))
+	if !ok {
+		return
+	}
+
+	// Now that we know it exists, build cache key if caching is enabled
+	var cacheKey string
+	var err error
+	if app.cache != nil && !cache.ShouldBypass(r) {
+		cacheKey = app.cacheKeyBuilder.BuildKeyForListingPage(r, "pages/tag.jet", app.store(r).Generation(), paging.CurrentPage)
+	}
+
+	renderFunc := func(writer http.ResponseWriter) error {
+		data := app.newTemplateData(r)
+		data["Tag"] = tag
+		data["BlogPosts"] = pagination.Paginate(posts, paging)
+		data["Pagination"] = paging
+
+		return app.jetRenderer.RenderPage(writer, http.StatusOK, data, "pages/tag.jet")
+	}
+
+	if cacheKey != "" {
+		err = app.renderWithCache(w, r, cacheKey, renderFunc)
+	} else {
+		err = renderFunc(w)
+	}
+
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+func (app *application) tagIndex(w http.ResponseWriter, r *http.Request) {
+	var cacheKey string
+	var err error
+
+	// Build cache key if caching is enabled
+	if app.cache != nil && !cache.ShouldBypass(r) {
+		cacheKey = app.cacheKeyBuilder.BuildKeyForListingPage(r, "pages/tags.jet", app.store(r).Generation(), 1)
+	}
+
+	renderFunc := func(writer http.ResponseWriter) error {
+		data := app.newTemplateData(r)
+		data["Tags"] = app.store(r).Tags()
+
+		return app.jetRenderer.RenderPage(writer, http.StatusOK, data, "pages/tags.jet")
+	}
+
+	if cacheKey != "" {
+		err = app.renderWithCache(w, r, cacheKey, renderFunc)
+	} else {
+		err = renderFunc(w)
+	}
+
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+// searchResult is a search hit as returned by the JSON search endpoint
+type searchResult struct {
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Type        string    `json:"type"`
+	Description string    `json:"description,omitempty"`
+	Date        time.Time `json:"date,omitzero"`
+	Tags        []string  `json:"tags,omitempty"`
+	Snippet     string    `json:"snippet"`
+	Score       float64   `json:"score"`
+}
+
+// search renders the search page. Results depend on the query, so they are not cached.
+func (app *application) search(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	data := app.newTemplateData(r)
+	data["Query"] = query
+	data["Results"] = app.store(r).Search(query, app.config.search.maxResults)
+
+	err := app.jetRenderer.RenderPage(w, http.StatusOK, data, "pages/search.jet")
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+// searchJSON returns the search results for the q query parameter as JSON
+func (app *application) searchJSON(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	results := []searchResult{}
+	for _, result := range app.store(r).Search(query, app.config.search.maxResults) {
+		var date time.Time
+		if result.IsPost() {
+			date = result.Content.GetDate()
+		}
+
+		results = append(results, searchResult{
+			Title:       result.Content.Frontmatter.Title,
+			URL:         result.URL,
+			Type:        string(result.Kind),
+			Description: result.Content.Frontmatter.Description,
+			Date:        date,
+			Tags:        result.Content.Frontmatter.Tags,
+			Snippet:     result.Snippet,
+			Score:       result.Score,
+		})
+	}
+
+	err := response.JSON(w, http.StatusOK, map[string]any{
+		"query":   query,
+		"results": results,
+	})
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+// graphNode is a post or page in the link graph returned by the graph endpoint
+type graphNode struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	Type      string   `json:"type"`
+	Tags      []string `json:"tags,omitempty"`
+	Backlinks int      `json:"backlinks"`
+}
+
+// graphLink is a link between two graph nodes, by ID
+type graphLink struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// graphJSON returns the links between published posts and pages as JSON nodes and links,
+// the shape graph visualisation libraries such as d3-force expect
+func (app *application) graphJSON(w http.ResponseWriter, r *http.Request) {
+	nodes := []graphNode{}
+	links := []graphLink{}
+
+	if graph := app.store(r).Graph(); graph != nil {
+		for _, node := range graph.Nodes {
+			nodes = append(nodes, graphNode{
+				ID:        node.URL,
+				Title:     node.Content.Frontmatter.Title,
+				Type:      string(node.Kind),
+				Tags:      node.Content.Frontmatter.Tags,
+				Backlinks: len(node.Content.Backlinks),
+			})
+		}
+		for _, edge := range graph.Edges {
+			links = append(links, graphLink{Source: edge.Source, Target: edge.Target})
+		}
+	}
+
+	err := response.JSON(w, http.StatusOK, map[string]any{
+		"nodes": nodes,
+		"links": links,
+	})
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
 func (app *application) health(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -390,30 +766,29 @@ func (app *application) rssFeed(w http.ResponseWriter, r *http.Request) {
 	var cacheKey string
 	var err error
 
-	// Build cache key if caching is enabled
+	store := app.store(r)
+	lang := app.language(r)
+
+	// Build cache key if caching is enabled, one per language
 	if app.cache != nil && !cache.ShouldBypass(r) {
-		cacheKey = "rss:main"
+		cacheKey = "rss:" + lang
 	}
 
 	renderFunc := func(writer http.ResponseWriter) error {
-		// Load blog posts
-		posts, _, err := app.contentLoader.LoadBlogPosts(app.config.dataDir)
-		if err != nil {
-			return fmt.Errorf("failed to load blog posts for RSS: %w", err)
-		}
+		posts := store.Posts()
 
 		// Build feed configuration
 		feedConfig := feed.Config{
 			Title:       app.config.site.title,
-			Link:        app.config.baseURL,
+			Link:        app.config.baseURL + store.URLPrefix(),
 			Description: app.config.site.description,
-			Language:    app.config.site.language,
+			Language:    lang,
 			Copyright:   app.config.site.copyright,
 			Generator:   fmt.Sprintf("VellumForge %s", version.Get()),
 		}
 
 		// Generate RSS feed
-		feedURL := app.config.baseURL + "/rss"
+		feedURL := app.config.baseURL + store.URLPrefix() + "/rss"
 		rssData, err := feed.GenerateRSS(posts, feedConfig, feedURL, app.config.site.feedItemsCount)
 		if err != nil {
 			return fmt.Errorf("failed to generate RSS feed: %w", err)
@@ -449,26 +824,42 @@ func (app *application) sitemap(w http.ResponseWriter, r *http.Request) {
 	}
 
 	renderFunc := func(writer http.ResponseWriter) error {
-		// Load blog posts
-		posts, _, err := app.contentLoader.LoadBlogPosts(app.config.dataDir)
-		if err != nil {
-			return fmt.Errorf("failed to load blog posts for sitemap: %w", err)
+		var entries []*sitemap.SitemapEntry
+		prefixes := make(map[string]string)
+
+		for _, store := range app.allStores() {
+			baseURL := app.config.baseURL + store.URLPrefix()
+			prefixes[store.Language()] = store.URLPrefix()
+
+			// Build sitemap entries
+			langEntries := sitemap.BuildSitemapFromContent(
+				baseURL,
+				store.Posts(),
+				time.Now(), // TODO: Get actual last modification time
+				store.Pages(),
+				time.Now(), // TODO: Get actual last modification time
+			)
+
+			// Add author archive pages
+			var authorSlugs []string
+			for _, author := range store.Authors() {
+				authorSlugs = append(authorSlugs, author.Slug)
+			}
+			langEntries = sitemap.AddAuthorPages(baseURL, authorSlugs, time.Now(), langEntries)
+
+			// Add tag archive pages
+			var tagSlugs []string
+			for _, tag := range store.Tags() {
+				tagSlugs = append(tagSlugs, tag.Slug)
+			}
+			langEntries = sitemap.AddTagPages(baseURL, tagSlugs, time.Now(), langEntries)
+
+			sitemap.SetLanguage(langEntries, store.Language())
+			entries = append(entries, langEntries...)
 		}
 
-		// Load pages
-		pages, _, err := app.contentLoader.LoadPages(app.config.dataDir)
-		if err != nil {
-			return fmt.Errorf("failed to load pages for sitemap: %w", err)
-		}
-
-		// Build sitemap entries
-		entries := sitemap.BuildSitemapFromContent(
-			app.config.baseURL,
-			posts,
-			time.Now(), // TODO: Get actual last modification time
-			pages,
-			time.Now(), // TODO: Get actual last modification time
-		)
+		// Link each page to its translations
+		sitemap.LinkAlternates(app.config.baseURL, app.config.site.languages.Default(), prefixes, entries)
 
 		// Generate sitemap XML
 		sitemapData, err := sitemap.GenerateSitemap(entries)
@@ -508,6 +899,21 @@ Sitemap: %s/sitemap.xml
 	w.Write([]byte(robotsTxt))
 }
 
+// highlightCSS serves the stylesheet for highlighted code, generated from the highlight style
+// the theme or configuration chose so the colours always match the markup
+func (app *application) highlightCSS(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	err := app.config.markdown.options.WriteHighlightCSS(&buf)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
 // validateAssetPath validates and cleans a requested asset path to prevent directory traversal
 func (app *application) validateAssetPath(requestedPath string) (string, error) {
 	// Remove any leading slashes
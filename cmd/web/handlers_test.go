@@ -1,10 +1,16 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"vellum.forge/internal/assert"
+	"vellum.forge/internal/cache"
+	"vellum.forge/internal/content"
+	"vellum.forge/internal/preview"
 )
 
 func TestHome(t *testing.T) {
@@ -18,3 +24,700 @@ func TestHome(t *testing.T) {
 		assert.True(t, containsPageTag(t, res.Body, "home"))
 	})
 }
+
+func TestAuthorPage(t *testing.T) {
+	files := map[string]string{
+		"authors/jane.md": "---\nname: Jane Smith\nbio: Writes about Go\n---\n\nLonger bio",
+		"blog/first.md":   "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\nauthor: jane\n---\n\nHello",
+		"blog/second.md":  "---\ntitle: Second Post\ndate: 2024-01-16T10:00:00Z\nauthors: [\"someone-else\"]\n---\n\nWorld",
+	}
+
+	t.Run("GET renders the author archive with their posts", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contentStore = newTestContentStore(t, files)
+
+		req := newTestRequest(t, http.MethodGet, "/author/jane")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, containsPageTag(t, res.Body, "author"))
+		assert.True(t, strings.Contains(res.Body, "Jane Smith"))
+		assert.True(t, strings.Contains(res.Body, "First Post"))
+		assert.False(t, strings.Contains(res.Body, "Second Post"))
+	})
+
+	t.Run("GET renders the 404 page for unknown authors", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contentStore = newTestContentStore(t, files)
+
+		req := newTestRequest(t, http.MethodGet, "/author/nobody")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Blog posts show a byline linking to the author", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contentStore = newTestContentStore(t, files)
+
+		req := newTestRequest(t, http.MethodGet, "/blog/first")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, containsHTMLNode(t, res.Body, `a[rel="author"][href="/author/jane"]`))
+		assert.True(t, containsHTMLNode(t, res.Body, `meta[name="author"][content="Jane Smith"]`))
+	})
+}
+
+func TestTagPage(t *testing.T) {
+	files := map[string]string{
+		"blog/first.md":  "---\ntitle: First Post\ndate: 2024-01-15T10:00:00Z\ntags: [\"Go\", \"Web Dev\"]\n---\n\nHello",
+		"blog/second.md": "---\ntitle: Second Post\ndate: 2024-01-16T10:00:00Z\ntags: [\"go\"]\n---\n\nWorld",
+		"blog/third.md":  "---\ntitle: Third Post\ndate: 2024-01-17T10:00:00Z\ntags: [\"rust\"]\n---\n\nAgain",
+	}
+
+	t.Run("GET renders the posts carrying the tag", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contentStore = newTestContentStore(t, files)
+
+		req := newTestRequest(t, http.MethodGet, "/tag/go")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, containsPageTag(t, res.Body, "tag"))
+		assert.True(t, strings.Contains(res.Body, "First Post"))
+		assert.True(t, strings.Contains(res.Body, "Second Post"))
+		assert.False(t, strings.Contains(res.Body, "Third Post"))
+	})
+
+	t.Run("GET renders the 404 page for unknown tags", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contentStore = newTestContentStore(t, files)
+
+		req := newTestRequest(t, http.MethodGet, "/tag/python")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("GET /tags lists every tag with its count", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contentStore = newTestContentStore(t, files)
+
+		req := newTestRequest(t, http.MethodGet, "/tags")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, containsPageTag(t, res.Body, "tags"))
+		assert.True(t, containsHTMLNode(t, res.Body, `.tag-weight-5 a[href="/tag/go"]`))
+		assert.True(t, containsHTMLNode(t, res.Body, `a[href="/tag/web-dev"]`))
+		assert.True(t, containsHTMLNode(t, res.Body, `a[href="/tag/rust"]`))
+	})
+
+	t.Run("Blog posts link their tags", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contentStore = newTestContentStore(t, files)
+
+		req := newTestRequest(t, http.MethodGet, "/blog/first")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, containsHTMLNode(t, res.Body, `a[rel="tag"][href="/tag/web-dev"]`))
+	})
+}
+
+func TestBlogIndexPagination(t *testing.T) {
+	files := map[string]string{
+		"blog/one.md":   "---\ntitle: Post One\ndate: 2024-01-01T10:00:00Z\ntags: [\"go\"]\n---\n\nOne",
+		"blog/two.md":   "---\ntitle: Post Two\ndate: 2024-01-02T10:00:00Z\ntags: [\"go\"]\n---\n\nTwo",
+		"blog/three.md": "---\ntitle: Post Three\ndate: 2024-01-03T10:00:00Z\ntags: [\"go\"]\n---\n\nThree",
+	}
+
+	newApp := func(t *testing.T) *application {
+		app := newTestApplication(t)
+		app.config.site.postsPerPage = 2
+		app.contentStore = newTestContentStore(t, files)
+		return app
+	}
+
+	t.Run("GET /blog renders the first page with a next link", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/blog")
+
+		res := send(t, req, newApp(t).routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "Post Three"))
+		assert.True(t, strings.Contains(res.Body, "Post Two"))
+		assert.False(t, strings.Contains(res.Body, "Post One"))
+		assert.True(t, containsHTMLNode(t, res.Body, `link[rel="next"][href$="/blog/page/2"]`))
+		assert.False(t, containsHTMLNode(t, res.Body, `link[rel="prev"]`))
+	})
+
+	t.Run("GET /blog/page/2 renders the remaining posts", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/blog/page/2")
+
+		res := send(t, req, newApp(t).routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "Post One"))
+		assert.False(t, strings.Contains(res.Body, "Post Three"))
+		assert.True(t, containsHTMLNode(t, res.Body, `link[rel="prev"][href$="/blog"]`))
+	})
+
+	t.Run("GET /blog/page/1 redirects to /blog", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/blog/page/1")
+
+		res := send(t, req, newApp(t).routes())
+		assert.Equal(t, res.StatusCode, http.StatusMovedPermanently)
+		assert.Equal(t, res.Header.Get("Location"), "/blog")
+	})
+
+	t.Run("GET renders the 404 page for pages out of range", func(t *testing.T) {
+		for _, path := range []string{"/blog/page/3", "/blog/page/0", "/blog/page/abc"} {
+			req := newTestRequest(t, http.MethodGet, path)
+
+			res := send(t, req, newApp(t).routes())
+			assert.Equal(t, res.StatusCode, http.StatusNotFound)
+		}
+	})
+
+	t.Run("Tag archives are paginated", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/tag/go/page/2")
+
+		res := send(t, req, newApp(t).routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "Post One"))
+		assert.False(t, strings.Contains(res.Body, "Post Two"))
+	})
+}
+
+func TestBlogArchive(t *testing.T) {
+	files := map[string]string{
+		"blog/jan.md":   "---\ntitle: January Post\ndate: 2024-01-15T10:00:00Z\n---\n\nOne",
+		"blog/feb.md":   "---\ntitle: February Post\ndate: 2024-02-15T10:00:00Z\n---\n\nTwo",
+		"blog/old.md":   "---\ntitle: Old Post\ndate: 2023-06-01T10:00:00Z\n---\n\nThree",
+		"blog/2024.md":  "---\ntitle: Slug Post\ndate: 2022-01-01T10:00:00Z\nslug: year-in-review\n---\n\nFour",
+		"blog/hello.md": "---\ntitle: Hello\ndate: 2022-01-02T10:00:00Z\n---\n\nFive",
+		"blog/1999.md":  "---\ntitle: Party Like It's 1999\ndate: 2022-01-03T10:00:00Z\n---\n\nSix",
+	}
+
+	t.Run("GET /blog/archive groups posts by year and month", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contentStore = newTestContentStore(t, files)
+
+		req := newTestRequest(t, http.MethodGet, "/blog/archive")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, containsPageTag(t, res.Body, "blog/archive"))
+		assert.True(t, containsHTMLNode(t, res.Body, `a[href="/blog/2024"]`))
+		assert.True(t, containsHTMLNode(t, res.Body, `a[href="/blog/2024/02"]`))
+		assert.True(t, containsHTMLNode(t, res.Body, `a[href="/blog/2023/06"]`))
+	})
+
+	t.Run("GET /blog/{year} lists the year's posts", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contentStore = newTestContentStore(t, files)
+
+		req := newTestRequest(t, http.MethodGet, "/blog/2024")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "January Post"))
+		assert.True(t, strings.Contains(res.Body, "February Post"))
+		assert.False(t, strings.Contains(res.Body, "Old Post"))
+	})
+
+	t.Run("GET /blog/{year}/{month} lists the month's posts", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contentStore = newTestContentStore(t, files)
+
+		req := newTestRequest(t, http.MethodGet, "/blog/2024/02")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "February 2024"))
+		assert.True(t, strings.Contains(res.Body, "February Post"))
+		assert.False(t, strings.Contains(res.Body, "January Post"))
+	})
+
+	t.Run("GET renders the 404 page for periods without posts", func(t *testing.T) {
+		for _, path := range []string{"/blog/2019", "/blog/2024/03", "/blog/2024/13"} {
+			app := newTestApplication(t)
+			app.contentStore = newTestContentStore(t, files)
+
+			req := newTestRequest(t, http.MethodGet, path)
+
+			res := send(t, req, app.routes())
+			assert.Equal(t, res.StatusCode, http.StatusNotFound)
+		}
+	})
+
+	t.Run("Post slugs still resolve alongside archive routes", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contentStore = newTestContentStore(t, files)
+
+		req := newTestRequest(t, http.MethodGet, "/blog/hello")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "Hello"))
+	})
+
+	t.Run("GET /blog/{year} serves a post with that slug", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contentStore = newTestContentStore(t, files)
+
+		req := newTestRequest(t, http.MethodGet, "/blog/1999")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.False(t, containsPageTag(t, res.Body, "blog/archive"))
+		assert.True(t, strings.Contains(res.Body, "Party Like It"))
+	})
+}
+
+func TestScheduledPosts(t *testing.T) {
+	publishAt := time.Now().Add(10 * time.Minute).UTC().Format(time.RFC3339)
+	files := map[string]string{
+		"blog/live.md":   "---\ntitle: Live Post\ndate: 2024-01-15T10:00:00Z\n---\n\nHello",
+		"blog/future.md": "---\ntitle: Future Post\ndate: " + publishAt + "\n---\n\nSoon",
+		"blog/gone.md":   "---\ntitle: Gone Post\ndate: 2024-01-10T10:00:00Z\nexpires: 2024-02-01T00:00:00Z\n---\n\nBye",
+	}
+
+	t.Run("Scheduled and expired posts are hidden", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contentStore = newTestContentStore(t, files)
+
+		for _, path := range []string{"/blog/future", "/blog/gone"} {
+			res := send(t, newTestRequest(t, http.MethodGet, path), app.routes())
+			assert.Equal(t, res.StatusCode, http.StatusNotFound)
+		}
+
+		res := send(t, newTestRequest(t, http.MethodGet, "/blog"), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "Live Post"))
+		assert.False(t, strings.Contains(res.Body, "Future Post"))
+		assert.False(t, strings.Contains(res.Body, "Gone Post"))
+
+		res = send(t, newTestRequest(t, http.MethodGet, "/rss"), app.routes())
+		assert.False(t, strings.Contains(res.Body, "Future Post"))
+	})
+
+	t.Run("Cached pages expire by the next scheduled publish", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.config.cacheTTL = 3600
+		app.contentStore = newTestContentStore(t, files)
+
+		assert.True(t, app.pageCacheTTL() <= 10*time.Minute)
+	})
+}
+
+func TestDraftPreview(t *testing.T) {
+	files := map[string]string{
+		"blog/draft.md":   "---\ntitle: Draft Post\ndate: 2024-01-15T10:00:00Z\ndraft: true\n---\n\nWork in progress",
+		"pages/hidden.md": "---\ntitle: Hidden Page\ndraft: true\n---\n\nNot yet",
+	}
+
+	newApp := func(t *testing.T) *application {
+		app := newTestApplication(t)
+		app.config.cookie.secretKey = "test-secret-key"
+		app.contentStore = newTestContentStore(t, files)
+		app.cache = cache.New(cache.DefaultConfig())
+		app.cacheKeyBuilder = cache.NewCacheKeyBuilder("default", t.TempDir(), "../../themes")
+		t.Cleanup(app.cache.Close)
+		return app
+	}
+
+	t.Run("GET renders the 404 page for drafts without a preview token", func(t *testing.T) {
+		app := newApp(t)
+
+		for _, path := range []string{"/blog/draft", "/hidden"} {
+			res := send(t, newTestRequest(t, http.MethodGet, path), app.routes())
+			assert.Equal(t, res.StatusCode, http.StatusNotFound)
+		}
+	})
+
+	t.Run("GET renders drafts with a valid preview token and does not cache them", func(t *testing.T) {
+		app := newApp(t)
+
+		for _, path := range []string{"/blog/draft", "/hidden"} {
+			link := preview.URL("", path, time.Now().Add(time.Hour), app.config.cookie.secretKey)
+
+			res := send(t, newTestRequest(t, http.MethodGet, link), app.routes())
+			assert.Equal(t, res.StatusCode, http.StatusOK)
+			assert.Equal(t, res.Header.Get("Cache-Control"), "private, no-store")
+			assert.True(t, containsHTMLNode(t, res.Body, ".preview-banner"))
+		}
+
+		assert.Equal(t, app.cache.Stats().Entries, 0)
+	})
+
+	t.Run("GET renders the 404 page for invalid or expired tokens", func(t *testing.T) {
+		app := newApp(t)
+
+		links := []string{
+			"/blog/draft?preview=bogus",
+			preview.URL("", "/blog/draft", time.Now().Add(-time.Minute), app.config.cookie.secretKey),
+			preview.URL("", "/blog/other", time.Now().Add(time.Hour), app.config.cookie.secretKey),
+			preview.URL("", "/blog/draft", time.Now().Add(time.Hour), "wrong-key"),
+		}
+		for _, link := range links {
+			res := send(t, newTestRequest(t, http.MethodGet, link), app.routes())
+			assert.Equal(t, res.StatusCode, http.StatusNotFound)
+		}
+	})
+
+	t.Run("GET renders the 404 page while the default secret is in use", func(t *testing.T) {
+		app := newApp(t)
+		app.config.cookie.secretKey = defaultCookieSecretKey
+
+		link := preview.URL("", "/blog/draft", time.Now().Add(time.Hour), defaultCookieSecretKey)
+		res := send(t, newTestRequest(t, http.MethodGet, link), app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusNotFound)
+	})
+}
+
+func TestBlogPostTOC(t *testing.T) {
+	app := newTestApplication(t)
+	app.contentStore = newTestContentStore(t, map[string]string{
+		"blog/guide.md": "---\ntitle: Guide\ndate: 2024-01-15T10:00:00Z\n---\n\n## Install\n\n### On Linux\n\n## Usage\n",
+	})
+
+	req := newTestRequest(t, http.MethodGet, "/blog/guide")
+
+	res := send(t, req, app.routes())
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.True(t, containsHTMLNode(t, res.Body, `nav.toc a[href="#install"]`))
+	assert.True(t, containsHTMLNode(t, res.Body, `nav.toc li li a[href="#on-linux"]`))
+	assert.True(t, containsHTMLNode(t, res.Body, `h2#usage`))
+}
+
+func TestBlogPostRelated(t *testing.T) {
+	app := newTestApplication(t)
+	app.contentStore = newTestContentStore(t, map[string]string{
+		"blog/first.md":  "---\ntitle: First\ndate: 2024-01-15T10:00:00Z\ntags: [\"go\"]\n---\n\nFirst",
+		"blog/second.md": "---\ntitle: Second\ndate: 2024-02-15T10:00:00Z\ntags: [\"go\"]\n---\n\nSecond",
+		"blog/third.md":  "---\ntitle: Third\ndate: 2024-03-15T10:00:00Z\ntags: [\"rust\"]\n---\n\nThird",
+	})
+
+	req := newTestRequest(t, http.MethodGet, "/blog/first")
+
+	res := send(t, req, app.routes())
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.True(t, containsHTMLNode(t, res.Body, `.related-posts a[href="/blog/second"]`))
+	assert.False(t, containsHTMLNode(t, res.Body, `.related-posts a[href="/blog/third"]`))
+}
+
+func TestBlogPostShortcodes(t *testing.T) {
+	app := newTestApplication(t)
+	app.contentStore = newTestContentStore(t, map[string]string{
+		"blog/media.md": "---\ntitle: Media\ndate: 2024-01-15T10:00:00Z\n---\n\n" +
+			"{{< youtube dQw4w9WgXcQ >}}\n\n" +
+			"{{< figure src=\"/images/cat.jpg\" caption=\"A cat\" >}}\n\n" +
+			"{{< callout type=\"warn\" >}}\nBack up **first**.\n{{< /callout >}}\n",
+	}, content.WithShortcodes(app.jetRenderer))
+
+	req := newTestRequest(t, http.MethodGet, "/blog/media")
+
+	res := send(t, req, app.routes())
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.True(t, containsHTMLNode(t, res.Body, `.shortcode-youtube iframe[src="https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ"]`))
+	assert.True(t, containsHTMLNode(t, res.Body, `figure.shortcode-figure img[src="/images/cat.jpg"][alt="A cat"]`))
+	assert.True(t, containsHTMLNode(t, res.Body, `figure.shortcode-figure figcaption`))
+	assert.True(t, containsHTMLNode(t, res.Body, `.callout.callout-warn p strong`))
+	assert.False(t, strings.Contains(res.Body, "{{&lt;"))
+}
+
+func TestBlogPostParams(t *testing.T) {
+	app := newTestApplication(t)
+	app.contentStore = newTestContentStore(t, map[string]string{
+		"blog/with.md":    "---\ntitle: With\ndate: 2024-01-15T10:00:00Z\nsubtitle: A custom field\n---\n\nBody",
+		"blog/without.md": "---\ntitle: Without\ndate: 2024-01-16T10:00:00Z\n---\n\nBody",
+	})
+
+	req := newTestRequest(t, http.MethodGet, "/blog/with")
+
+	res := send(t, req, app.routes())
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.True(t, containsHTMLNode(t, res.Body, `.subtitle`))
+	assert.True(t, strings.Contains(res.Body, "A custom field"))
+
+	req = newTestRequest(t, http.MethodGet, "/blog/without")
+
+	res = send(t, req, app.routes())
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.False(t, containsHTMLNode(t, res.Body, `.subtitle`))
+}
+
+func TestBacklinksAndGraph(t *testing.T) {
+	app := newTestApplication(t)
+	app.contentStore = newTestContentStore(t, map[string]string{
+		"blog/first.md":  "---\ntitle: First\ndate: 2024-01-15T10:00:00Z\ntags: [\"go\"]\n---\n\nSee [about](/about)",
+		"blog/second.md": "---\ntitle: Second\ndate: 2024-02-15T10:00:00Z\n---\n\nBack to [[first]]",
+		"pages/about.md": "---\ntitle: About\n---\n\nAbout",
+	})
+
+	t.Run("Lists backlinks on posts and pages", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/blog/first")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, containsHTMLNode(t, res.Body, `.backlinks a[href="/blog/second"]`))
+
+		req = newTestRequest(t, http.MethodGet, "/about")
+
+		res = send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, containsHTMLNode(t, res.Body, `.backlinks a[href="/blog/first"]`))
+	})
+
+	t.Run("Returns the link graph as JSON", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/graph.json")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		var body struct {
+			Nodes []graphNode `json:"nodes"`
+			Links []graphLink `json:"links"`
+		}
+		err := json.Unmarshal([]byte(res.Body), &body)
+		assert.Nil(t, err)
+		assert.Equal(t, len(body.Nodes), 3)
+		assert.Equal(t, body.Nodes[1], graphNode{ID: "/blog/first", Title: "First", Type: "blog", Tags: []string{"go"}, Backlinks: 1})
+		assert.Equal(t, len(body.Links), 2)
+		assert.Equal(t, body.Links[0], graphLink{Source: "/blog/second", Target: "/blog/first"})
+		assert.Equal(t, body.Links[1], graphLink{Source: "/blog/first", Target: "/about"})
+	})
+}
+
+func TestSectionPages(t *testing.T) {
+	app := newTestApplication(t)
+	app.contentStore = newTestContentStore(t, map[string]string{
+		"pages/install.md":          "---\ntitle: Install\n---\n\nTop-level install",
+		"pages/docs/_index.md":      "---\ntitle: Docs\n---\n\nDocs home",
+		"pages/docs/install.md":     "---\ntitle: Installing\nweight: 1\n---\n\nNested install",
+		"pages/docs/configure.md":   "---\ntitle: Configure\nweight: 2\n---\n\nConfigure",
+		"pages/docs/guides/deep.md": "---\ntitle: Deep\nweight: 3\n---\n\nDeep",
+	})
+
+	t.Run("Serves pages at nested URLs", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/install")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "Top-level install"))
+
+		req = newTestRequest(t, http.MethodGet, "/docs/guides/deep")
+
+		res = send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, containsHTMLNode(t, res.Body, `.breadcrumbs a[href="/docs"]`))
+	})
+
+	t.Run("Lists the children of section pages", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/docs")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, containsHTMLNode(t, res.Body, `.page-children a[href="/docs/install"]`))
+		assert.True(t, containsHTMLNode(t, res.Body, `.page-children a[href="/docs/guides/deep"]`))
+		assert.False(t, containsHTMLNode(t, res.Body, `.breadcrumbs`))
+	})
+
+	t.Run("Links siblings", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/docs/install")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "Nested install"))
+		assert.True(t, containsHTMLNode(t, res.Body, `.page-siblings a[rel="next"][href="/docs/configure"]`))
+		assert.False(t, containsHTMLNode(t, res.Body, `.page-siblings a[rel="prev"]`))
+	})
+
+	t.Run("Returns 404 for unknown nested paths", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/docs/missing")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusNotFound)
+	})
+}
+
+func TestLanguages(t *testing.T) {
+	app := newTestApplication(t)
+	app.config.baseURL = "https://example.com"
+	app.config.site.languages = content.ParseLanguages("en", "fr")
+
+	app.config.dataDir = newTestDataDir(t, map[string]string{
+		"blog/hello.md":     "---\ntitle: Hello\ndate: 2024-01-15T10:00:00Z\ntags: [go]\n---\n\nHello there",
+		"blog/hello.fr.md":  "---\ntitle: Bonjour\ndate: 2024-01-15T10:00:00Z\ntags: [go]\n---\n\nBonjour à tous, voir [[about]]",
+		"blog/salut.md":     "---\ntitle: Salut\nlang: fr\ndate: 2024-01-16T10:00:00Z\n---\n\nSalut",
+		"pages/about.md":    "---\ntitle: About\n---\n\nAbout us",
+		"pages/about.fr.md": "---\ntitle: À propos\n---\n\nÀ propos de nous",
+	})
+	app.stores = app.config.contentStores(content.NewLoader(), app.logger)
+	for _, store := range app.stores {
+		if err := store.Load(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	app.contentStore = app.stores["en"]
+
+	t.Run("Serves the default language at the root", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/blog/hello")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "Hello there"))
+		assert.True(t, containsHTMLNode(t, res.Body, `html[lang="en"]`))
+		assert.True(t, containsHTMLNode(t, res.Body, `.translations a[hreflang="fr"][href="/fr/blog/hello"]`))
+		assert.True(t, containsHTMLNode(t, res.Body, `link[rel="alternate"][hreflang="fr"][href="https://example.com/fr/blog/hello"]`))
+	})
+
+	t.Run("Serves other languages under their prefix", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/fr/blog/hello")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "Bonjour à tous"))
+		assert.True(t, containsHTMLNode(t, res.Body, `html[lang="fr"]`))
+		assert.True(t, containsHTMLNode(t, res.Body, `.post-content a[href="/fr/about"]`))
+		assert.True(t, containsHTMLNode(t, res.Body, `.tags a[href="/fr/tag/go"]`))
+		assert.True(t, containsHTMLNode(t, res.Body, `.translations a[hreflang="en"][href="/blog/hello"]`))
+
+		req = newTestRequest(t, http.MethodGet, "/fr/about")
+
+		res = send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "À propos de nous"))
+
+		req = newTestRequest(t, http.MethodGet, "/blog/salut")
+
+		res = send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Lists only the language's posts", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/fr/blog")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, containsHTMLNode(t, res.Body, `a[href="/fr/blog/salut"]`))
+		assert.False(t, strings.Contains(res.Body, "Hello"))
+	})
+
+	t.Run("Translates the theme's strings", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/fr/blog/hello")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "15 janvier 2024"))
+		assert.True(t, strings.Contains(res.Body, "1 min de lecture"))
+		assert.True(t, strings.Contains(res.Body, "Retour au blog"))
+		assert.True(t, strings.Contains(res.Body, "Aussi disponible en :"))
+
+		req = newTestRequest(t, http.MethodGet, "/fr/tag/go")
+
+		res = send(t, req, app.routes())
+		assert.True(t, strings.Contains(res.Body, "1 article &middot;"))
+
+		req = newTestRequest(t, http.MethodGet, "/fr/blog/archive")
+
+		res = send(t, req, app.routes())
+		assert.True(t, containsHTMLNode(t, res.Body, `a[href="/fr/blog/2024/01"]`))
+		assert.True(t, strings.Contains(res.Body, ">janvier</a>"))
+
+		req = newTestRequest(t, http.MethodGet, "/fr/blog/2024/01")
+
+		res = send(t, req, app.routes())
+		assert.True(t, strings.Contains(res.Body, "janvier 2024"))
+		assert.False(t, strings.Contains(res.Body, "January"))
+
+		req = newTestRequest(t, http.MethodGet, "/blog/hello")
+
+		res = send(t, req, app.routes())
+		assert.True(t, strings.Contains(res.Body, "January 15, 2024"))
+		assert.True(t, strings.Contains(res.Body, "Back to Blog"))
+	})
+
+	t.Run("Serves a feed per language", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/fr/rss")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "<language>fr</language>"))
+		assert.True(t, strings.Contains(res.Body, "<link>https://example.com/fr/blog/salut</link>"))
+		assert.False(t, strings.Contains(res.Body, "Hello"))
+
+		req = newTestRequest(t, http.MethodGet, "/rss")
+
+		res = send(t, req, app.routes())
+		assert.True(t, strings.Contains(res.Body, "<language>en</language>"))
+		assert.False(t, strings.Contains(res.Body, "Salut"))
+	})
+
+	t.Run("Links translations in the sitemap", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/sitemap.xml")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, "<loc>https://example.com/fr/blog/salut</loc>"))
+		assert.True(t, strings.Contains(res.Body, `<xhtml:link rel="alternate" hreflang="fr" href="https://example.com/fr/about"></xhtml:link>`))
+		assert.True(t, strings.Contains(res.Body, `<xhtml:link rel="alternate" hreflang="x-default" href="https://example.com/about"></xhtml:link>`))
+	})
+}
+
+func TestSearch(t *testing.T) {
+	app := newTestApplication(t)
+	app.contentStore = newTestContentStore(t, map[string]string{
+		"blog/caching.md": "---\ntitle: Caching pages\ndate: 2024-01-15T10:00:00Z\n---\n\nAn LRU cache keeps rendered pages in memory.",
+		"blog/other.md":   "---\ntitle: Other\ndate: 2024-02-15T10:00:00Z\n---\n\nSomething else.",
+		"pages/about.md":  "---\ntitle: About\n---\n\nAbout this site.",
+	})
+
+	t.Run("Renders ranked results with highlighted snippets", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/search?q=cache")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, containsHTMLNode(t, res.Body, `input[name="q"][value="cache"]`))
+		assert.True(t, containsHTMLNode(t, res.Body, `.search-result a[href="/blog/caching"]`))
+		assert.True(t, containsHTMLNode(t, res.Body, `.search-result mark`))
+		assert.False(t, containsHTMLNode(t, res.Body, `.search-result a[href="/blog/other"]`))
+	})
+
+	t.Run("Renders an empty form without a query", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/search")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, containsHTMLNode(t, res.Body, `form[action="/search"]`))
+		assert.False(t, containsHTMLNode(t, res.Body, `.search-result`))
+	})
+
+	t.Run("Returns results as JSON", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/search.json?q=about")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.Equal(t, res.Header.Get("Content-Type"), "application/json")
+
+		var body struct {
+			Query   string         `json:"query"`
+			Results []searchResult `json:"results"`
+		}
+		err := json.Unmarshal([]byte(res.Body), &body)
+		assert.Nil(t, err)
+		assert.Equal(t, body.Query, "about")
+		assert.Equal(t, len(body.Results), 1)
+		assert.Equal(t, body.Results[0].URL, "/about")
+		assert.Equal(t, body.Results[0].Type, "pages")
+	})
+
+	t.Run("Returns an empty JSON list without matches", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/search.json?q=nothing")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.True(t, strings.Contains(res.Body, `"results": []`))
+	})
+}
@@ -3,19 +3,35 @@ package main
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/go-chi/chi/v5"
+
+	"vellum.forge/internal/content"
+	"vellum.forge/internal/pagination"
 	"vellum.forge/internal/version"
 )
 
 func (app *application) newTemplateData(r *http.Request) map[string]any {
+	locale := app.jetRenderer.Locale(app.language(r))
+
 	data := map[string]any{
-		"Version": version.Get(),
+		"Version":    version.Get(),
+		"Lang":       app.language(r),
+		"LangPrefix": app.store(r).URLPrefix(),
+		// Translate the theme's strings and format dates and numbers in the page's language
+		"T":            locale.T,
+		"formatDate":   locale.FormatDate,
+		"humanizeTime": locale.HumanizeTime,
+		"formatNumber": locale.FormatNumber,
 		"Site": map[string]any{
 			"BaseURL": app.config.baseURL,
 			"Theme":   app.config.theme,
+			"Author":  app.config.site.author,
 		},
 		"Request": map[string]any{
 			"URL":    r.URL.String(),
+			"Path":   r.URL.Path,
 			"Method": r.Method,
 			"Host":   r.Host,
 		},
@@ -24,6 +40,61 @@ func (app *application) newTemplateData(r *http.Request) map[string]any {
 	return data
 }
 
+// language returns the language a request is in, from the URL prefix it was routed under
+func (app *application) language(r *http.Request) string {
+	if lang := contextLanguage(r); lang != "" {
+		return lang
+	}
+	return app.config.site.languages.Default()
+}
+
+// store returns the content store for the language a request is in
+func (app *application) store(r *http.Request) *content.Store {
+	if store, found := app.stores[contextLanguage(r)]; found {
+		return store
+	}
+	return app.contentStore
+}
+
+// allStores returns the content store of every language, the default first
+func (app *application) allStores() []*content.Store {
+	if len(app.config.site.languages) == 0 {
+		return []*content.Store{app.contentStore}
+	}
+
+	stores := make([]*content.Store, 0, len(app.stores))
+	for _, lang := range app.config.site.languages {
+		stores = append(stores, app.stores[lang])
+	}
+	return stores
+}
+
+// translation is a post or page in another language, as listed for templates
+type translation struct {
+	Lang  string
+	URL   string
+	Title string
+}
+
+// translations returns the post or page with slug in every language other than current's,
+// found by looking up the same slug in each language's store
+func (app *application) translations(current *content.Store, lookup func(store *content.Store, slug string) (*content.Content, bool), section, slug string) []translation {
+	var found []translation
+	for _, store := range app.allStores() {
+		if store == current {
+			continue
+		}
+		if item, ok := lookup(store, slug); ok {
+			found = append(found, translation{
+				Lang:  store.Language(),
+				URL:   store.URLPrefix() + section + item.Frontmatter.Slug,
+				Title: item.Frontmatter.Title,
+			})
+		}
+	}
+	return found
+}
+
 func (app *application) backgroundTask(r *http.Request, fn func() error) {
 	app.wg.Add(1)
 
@@ -43,3 +114,32 @@ func (app *application) backgroundTask(r *http.Request, fn func() error) {
 		}
 	}()
 }
+
+// paginate resolves the page requested by a {page} route parameter for a listing served at baseURL,
+// under the request's language prefix.
+// It returns false after responding itself when the page doesn't exist, or when /page/1 is
+// requested and the client is redirected to the canonical first page.
+func (app *application) paginate(w http.ResponseWriter, r *http.Request, baseURL string, totalItems int) (*pagination.Pagination, bool) {
+	page := 1
+	if param := chi.URLParam(r, "page"); param != "" {
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			app.notFound(w, r)
+			return nil, false
+		}
+		page = n
+	}
+
+	paging := pagination.New(app.store(r).URLPrefix()+baseURL, totalItems, app.config.site.postsPerPage, page, app.config.site.paginationContext)
+	if !paging.IsValid() {
+		app.notFound(w, r)
+		return nil, false
+	}
+
+	if chi.URLParam(r, "page") == "1" {
+		http.Redirect(w, r, paging.FirstURL(), http.StatusMovedPermanently)
+		return nil, false
+	}
+
+	return paging, true
+}
@@ -1,29 +1,41 @@
 package main
 
 import (
+	"cmp"
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime/debug"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"vellum.forge/internal/apikey"
 	"vellum.forge/internal/cache"
 	"vellum.forge/internal/content"
 	"vellum.forge/internal/env"
+	"vellum.forge/internal/redirect"
 	"vellum.forge/internal/response"
+	"vellum.forge/internal/search"
 	"vellum.forge/internal/version"
 
 	"github.com/joho/godotenv"
 	"github.com/lmittmann/tint"
+	"gopkg.in/yaml.v3"
 )
 
 func main() {
 	logger := slog.New(tint.NewHandler(os.Stdout, &tint.Options{Level: slog.LevelDebug}))
 
 	err := run(logger)
+	if errors.Is(err, errCheckFailed) {
+		os.Exit(1) // The problems have been reported already
+	}
 	if err != nil {
 		trace := string(debug.Stack())
 		logger.Error(err.Error(), "trace", trace)
@@ -39,31 +51,173 @@ type config struct {
 		secretKey string
 	}
 	site struct {
-		title          string
-		description    string
-		author         string
-		language       string
-		copyright      string
-		feedItemsCount int
+		title             string
+		description       string
+		author            string
+		language          string
+		languages         content.Languages
+		copyright         string
+		feedItemsCount    int
+		postsPerPage      int
+		paginationContext int
+	}
+	markdown struct {
+		headingAnchors bool
+		options        content.MarkdownOptions
+		schema         *content.Schema
+		sanitizer      *content.SanitizerConfig
+	}
+	related struct {
+		count             int
+		contentSimilarity bool
+	}
+	search struct {
+		maxResults int
+	}
+	apiKeys struct {
+		spec string
+		file string
 	}
 	cacheTTL        int
 	dataDir         string
+	redirectsFile   string
+	sanitizerFile   string
 	themeDir        string
 	cacheEnabled    bool
 	cacheMaxSize    int64
 	cacheMaxEntries int
 }
 
+// parserOptions returns the markdown parser options selected by the configuration,
+// rendering shortcodes with the theme's templates
+func (cfg config) parserOptions(shortcodes content.ShortcodeRenderer) []content.ParserOption {
+	return []content.ParserOption{
+		content.WithHeadingAnchors(cfg.markdown.headingAnchors),
+		content.WithMarkdownOptions(cfg.markdown.options),
+		content.WithSchema(cfg.markdown.schema),
+		content.WithSanitizer(cfg.markdown.sanitizer),
+		content.WithShortcodes(shortcodes),
+	}
+}
+
+// frontmatterSchema loads the frontmatter schema the theme declares in frontmatter.yaml, if any
+func (cfg config) frontmatterSchema() (*content.Schema, error) {
+	schema, err := content.LoadSchema(filepath.Join(cfg.themeDir, cfg.theme, "frontmatter.yaml"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return schema, err
+}
+
+// themeSettings holds what a theme tells the application in its theme.yaml
+type themeSettings struct {
+	HighlightStyle string `yaml:"highlight_style"` // Chroma style the theme's code colours match
+}
+
+// themeSettings loads the theme's theme.yaml over the default theme's, either of which may
+// be missing
+func (cfg config) themeSettings() (themeSettings, error) {
+	var settings themeSettings
+	for _, theme := range []string{"default", cfg.theme} {
+		path := filepath.Join(cfg.themeDir, theme, "theme.yaml")
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return settings, err
+		}
+
+		err = yaml.Unmarshal(data, &settings)
+		if err != nil {
+			return settings, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	return settings, nil
+}
+
+// redirectRules loads the site's redirects file, returning no rules if it doesn't exist
+func (cfg config) redirectRules() (redirect.Rules, error) {
+	rules, err := redirect.LoadFile(cfg.redirectsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return rules, err
+}
+
+// sanitizerConfig loads the site's sanitizer policy rules, returning none if the file doesn't
+// exist, so everything is sanitized with the standard policy
+func (cfg config) sanitizerConfig() (*content.SanitizerConfig, error) {
+	config, err := content.LoadSanitizerConfig(cfg.sanitizerFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return config, err
+}
+
+// contentStores creates a content store for each of the site's languages, keyed by language,
+// all loading content with loader. Without any languages there is one store, keyed by "".
+func (cfg config) contentStores(loader *content.Loader, logger *slog.Logger) map[string]*content.Store {
+	if len(cfg.site.languages) == 0 {
+		return map[string]*content.Store{"": content.NewStore(loader, cfg.dataDir, logger, cfg.storeOptions()...)}
+	}
+
+	stores := make(map[string]*content.Store, len(cfg.site.languages))
+	for _, lang := range cfg.site.languages {
+		opts := append(cfg.storeOptions(), content.WithLanguage(cfg.site.languages, lang))
+		stores[lang] = content.NewStore(loader, cfg.dataDir, logger, opts...)
+	}
+	return stores
+}
+
+// apiKeyring builds the API keyring from the keys given inline and in the keys file
+func (cfg config) apiKeyring() (*apikey.Keyring, error) {
+	keys, err := apikey.ParseKeys(cfg.apiKeys.spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.apiKeys.file != "" {
+		fileKeys, err := apikey.LoadFile(cfg.apiKeys.file)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+
+	return apikey.NewKeyring(keys)
+}
+
+// defaultCookieSecretKey is the secret used when COOKIE_SECRET_KEY isn't set. It is public, so
+// preview links signed with it would let anyone read drafts.
+const defaultCookieSecretKey = "fredbzsw2qsqb3mto3xfxnclebdt4hht"
+
+// previewsEnabled reports whether a secret of the site's own is set to sign preview links with
+func (cfg config) previewsEnabled() bool {
+	return cfg.cookie.secretKey != "" && cfg.cookie.secretKey != defaultCookieSecretKey
+}
+
+// storeOptions returns the content store options selected by the configuration
+func (cfg config) storeOptions() []content.StoreOption {
+	return []content.StoreOption{
+		content.WithRelatedPosts(cfg.related.count),
+		content.WithContentSimilarity(cfg.related.contentSimilarity),
+	}
+}
+
 type application struct {
 	config           config
 	logger           *slog.Logger
 	wg               sync.WaitGroup
-	contentLoader    *content.Loader
+	contentStore     *content.Store            // The default language's content
+	stores           map[string]*content.Store // Every language's content, by language
 	jetRenderer      *response.JetRenderer
 	cache            *cache.Cache
 	cacheKeyBuilder  *cache.CacheKeyBuilder
 	cacheInvalidator *cache.CacheInvalidator
 	fileWatcher      *cache.FileWatcher
+	apiKeys          *apikey.Keyring
+	redirects        atomic.Pointer[redirect.Rules]
 }
 
 func run(logger *slog.Logger) error {
@@ -74,9 +228,11 @@ func run(logger *slog.Logger) error {
 
 	cfg.baseURL = env.GetString("BASE_URL", "http://localhost:6886")
 	cfg.httpPort = env.GetInt("PORT", 6886)
-	cfg.cookie.secretKey = env.GetString("COOKIE_SECRET_KEY", "fredbzsw2qsqb3mto3xfxnclebdt4hht")
+	cfg.cookie.secretKey = env.GetString("COOKIE_SECRET_KEY", defaultCookieSecretKey)
 	cfg.cacheTTL = env.GetInt("CACHE_TTL", 3600)
 	cfg.dataDir = env.GetString("DATA_DIR", "data")
+	cfg.redirectsFile = env.GetString("REDIRECTS_FILE", filepath.Join(cfg.dataDir, "_redirects"))
+	cfg.sanitizerFile = env.GetString("SANITIZER_FILE", filepath.Join(cfg.dataDir, "sanitizer.yaml"))
 	cfg.themeDir = env.GetString("THEME_DIR", "themes")
 	cfg.theme = env.GetString("THEME", "default")
 	cfg.cacheEnabled = env.GetBool("CACHE_ENABLED", true)
@@ -88,8 +244,36 @@ func run(logger *slog.Logger) error {
 	cfg.site.description = env.GetString("SITE_DESCRIPTION", "A blog built with VellumForge")
 	cfg.site.author = env.GetString("SITE_AUTHOR", "VellumForge")
 	cfg.site.language = env.GetString("SITE_LANGUAGE", "en-us")
+	cfg.site.languages = content.ParseLanguages(cfg.site.language, env.GetString("SITE_LANGUAGES", ""))
 	cfg.site.copyright = env.GetString("SITE_COPYRIGHT", "")
 	cfg.site.feedItemsCount = env.GetInt("FEED_ITEMS_COUNT", 20)
+	cfg.site.postsPerPage = env.GetInt("POSTS_PER_PAGE", 10)
+	cfg.site.paginationContext = env.GetInt("PAGINATION_CONTEXT", 2)
+
+	// Markdown rendering
+	cfg.markdown.headingAnchors = env.GetBool("HEADING_ANCHORS", true)
+	defaults := content.DefaultMarkdownOptions()
+	cfg.markdown.options.Footnotes = env.GetBool("MARKDOWN_FOOTNOTES", defaults.Footnotes)
+	cfg.markdown.options.DefinitionLists = env.GetBool("MARKDOWN_DEFINITION_LISTS", defaults.DefinitionLists)
+	cfg.markdown.options.Typographer = env.GetBool("MARKDOWN_TYPOGRAPHER", defaults.Typographer)
+	cfg.markdown.options.Attributes = env.GetBool("MARKDOWN_ATTRIBUTES", defaults.Attributes)
+	cfg.markdown.options.HeadingIDs = env.GetBool("MARKDOWN_HEADING_IDS", defaults.HeadingIDs)
+	cfg.markdown.options.HardWraps = env.GetBool("MARKDOWN_HARD_WRAPS", defaults.HardWraps)
+	cfg.markdown.options.Highlight = env.GetBool("HIGHLIGHT", defaults.Highlight)
+	cfg.markdown.options.HighlightStyle = env.GetString("HIGHLIGHT_STYLE", "") // The theme's when empty
+	cfg.markdown.options.LineNumbers = env.GetBool("HIGHLIGHT_LINE_NUMBERS", defaults.LineNumbers)
+	cfg.markdown.options.Mermaid = env.GetBool("MARKDOWN_MERMAID", defaults.Mermaid)
+
+	// Related posts
+	cfg.related.count = env.GetInt("RELATED_POSTS", content.DefaultRelatedCount)
+	cfg.related.contentSimilarity = env.GetBool("RELATED_POSTS_CONTENT_SIMILARITY", false)
+
+	// Search
+	cfg.search.maxResults = env.GetInt("SEARCH_MAX_RESULTS", search.DefaultLimit)
+
+	// API keys
+	cfg.apiKeys.spec = env.GetString("API_KEYS", "")
+	cfg.apiKeys.file = env.GetString("API_KEYS_FILE", "")
 
 	showVersion := flag.Bool("version", false, "display version and exit")
 
@@ -100,6 +284,37 @@ func run(logger *slog.Logger) error {
 		return nil
 	}
 
+	schema, err := cfg.frontmatterSchema()
+	if err != nil {
+		return fmt.Errorf("failed to load frontmatter schema: %w", err)
+	}
+	cfg.markdown.schema = schema
+
+	sanitizer, err := cfg.sanitizerConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load sanitizer config: %w", err)
+	}
+	cfg.markdown.sanitizer = sanitizer
+
+	settings, err := cfg.themeSettings()
+	if err != nil {
+		return fmt.Errorf("failed to load theme settings: %w", err)
+	}
+	if cfg.markdown.options.HighlightStyle == "" {
+		cfg.markdown.options.HighlightStyle = cmp.Or(settings.HighlightStyle, content.DefaultHighlightStyle)
+	}
+	err = cfg.markdown.options.Validate()
+	if err != nil {
+		return err
+	}
+
+	switch flag.Arg(0) {
+	case "preview":
+		return runPreview(cfg, logger, flag.Args()[1:])
+	case "check":
+		return runCheck(cfg, flag.Args()[1:])
+	}
+
 	// Initialize Jet renderer
 	themeDir := filepath.Join(cfg.themeDir, cfg.theme)
 	jetRenderer, err := response.NewJetRenderer(themeDir)
@@ -107,13 +322,49 @@ func run(logger *slog.Logger) error {
 		return fmt.Errorf("failed to initialize Jet renderer: %w", err)
 	}
 
+	apiKeys, err := cfg.apiKeyring()
+	if err != nil {
+		return fmt.Errorf("failed to load API keys: %w", err)
+	}
+	if !cfg.previewsEnabled() {
+		logger.Warn("COOKIE_SECRET_KEY is not set, preview links are disabled until it is")
+	}
+	if len(apiKeys.Keys()) == 0 {
+		logger.Info("No API keys configured, the content API and cache endpoints will refuse every request")
+	}
+
+	loader := content.NewLoader(cfg.parserOptions(jetRenderer)...)
+	stores := cfg.contentStores(loader, logger)
+
 	app := &application{
-		config:        cfg,
-		logger:        logger,
-		contentLoader: content.NewLoader(),
-		jetRenderer:   jetRenderer,
+		config:       cfg,
+		logger:       logger,
+		contentStore: stores[cfg.site.languages.Default()],
+		stores:       stores,
+		jetRenderer:  jetRenderer,
+		apiKeys:      apiKeys,
 	}
 
+	// Index all posts and pages up front so requests never touch the disk
+	for _, store := range app.allStores() {
+		err = store.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load content: %w", err)
+		}
+		for _, conflict := range store.AliasConflicts() {
+			logger.Warn("Alias conflict, the alias won't redirect", "path", conflict.Path, "problem", conflict.Message)
+		}
+		for _, conflict := range store.TagConflicts() {
+			logger.Warn("Tag conflict, the tags share one archive page", "path", conflict.Path, "problem", conflict.Message)
+		}
+	}
+
+	redirects, err := cfg.redirectRules()
+	if err != nil {
+		return fmt.Errorf("failed to load redirects: %w", err)
+	}
+	app.redirects.Store(&redirects)
+
 	// Initialize cache if enabled
 	if cfg.cacheEnabled {
 		cacheConfig := cache.Config{
@@ -127,23 +378,62 @@ func run(logger *slog.Logger) error {
 		app.cacheKeyBuilder = cache.NewCacheKeyBuilder(cfg.theme, cfg.dataDir, cfg.themeDir)
 		app.cacheInvalidator = cache.NewCacheInvalidator(app.cache, logger)
 
-		// Initialize file watcher for auto-invalidation
-		app.fileWatcher = cache.NewFileWatcher(app.cache, logger)
-		err = app.fileWatcher.Watch(cfg.dataDir)
-		if err != nil {
-			logger.Warn("Failed to watch data directory for cache invalidation", "error", err)
-		}
-		err = app.fileWatcher.Watch(themeDir)
-		if err != nil {
-			logger.Warn("Failed to watch theme directory for cache invalidation", "error", err)
-		}
-		app.fileWatcher.Start()
-
 		logger.Info("Cache initialized",
 			"maxEntries", cfg.cacheMaxEntries,
 			"maxSizeMB", cfg.cacheMaxSize/(1024*1024),
 			"ttlSeconds", cfg.cacheTTL)
 	}
 
+	// Initialize file watcher to keep the content store fresh and auto-invalidate the cache
+	app.fileWatcher = cache.NewFileWatcher(app.cache, logger)
+	for _, store := range app.allStores() {
+		app.fileWatcher.OnChange(store.HandleFileChange)
+	}
+	app.fileWatcher.OnChange(app.reloadForShortcode)
+	app.fileWatcher.OnChange(app.invalidatePages)
+	app.fileWatcher.OnChange(app.reloadRedirects)
+	err = app.fileWatcher.Watch(cfg.dataDir)
+	if err != nil {
+		logger.Warn("Failed to watch data directory for changes", "error", err)
+	}
+	err = app.fileWatcher.Watch(themeDir)
+	if err != nil {
+		logger.Warn("Failed to watch theme directory for changes", "error", err)
+	}
+	app.fileWatcher.Start()
+
 	return app.serveHTTP()
 }
+
+// invalidatePages drops every cached page when content changes, since pages list the posts
+// and pages linking to them, and the home pages of languages other than the default. The file
+// watcher already drops cached posts and the default language's home page.
+func (app *application) invalidatePages(path string) {
+	if app.cache == nil || !strings.HasSuffix(strings.ToLower(path), ".md") {
+		return
+	}
+
+	for _, store := range app.allStores() {
+		if prefix := store.URLPrefix(); prefix != "" {
+			app.cache.Invalidate(cache.ExactPath(prefix))
+		}
+		for _, page := range store.AllPages() {
+			app.cache.Invalidate(cache.ExactPath(store.URLPrefix() + "/" + page.Frontmatter.Slug))
+		}
+	}
+}
+
+// reloadForShortcode reloads all content when a shortcode template changes,
+// since rendered shortcodes are stored as part of each post's HTML
+func (app *application) reloadForShortcode(path string) {
+	if filepath.Ext(path) != ".jet" || filepath.Base(filepath.Dir(path)) != "shortcodes" {
+		return
+	}
+
+	for _, store := range app.allStores() {
+		err := store.Load()
+		if err != nil {
+			app.logger.Warn("Failed to reload content after shortcode change", "path", path, "error", err)
+		}
+	}
+}
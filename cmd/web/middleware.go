@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
 
+	"vellum.forge/internal/apikey"
 	"vellum.forge/internal/response"
 
 	"github.com/tomasen/realip"
@@ -35,6 +38,100 @@ func (app *application) securityHeaders(next http.Handler) http.Handler {
 	})
 }
 
+type contextKey string
+
+const (
+	apiKeyContextKey   = contextKey("apiKey")
+	languageContextKey = contextKey("language")
+)
+
+// requireAPIKey only lets through requests carrying a valid API key that grants scope,
+// or any valid key if scope is empty. The key is stored in the request context.
+func (app *application) requireAPIKey(scope apikey.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Responses differ by key, such as drafts for keys with the read_drafts scope
+			w.Header().Add("Vary", "Authorization")
+
+			key, err := app.apiKeys.Authenticate(r)
+			switch {
+			case errors.Is(err, apikey.ErrMissingKey), errors.Is(err, apikey.ErrInvalidKey):
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				app.apiError(w, r, http.StatusUnauthorized, err.Error())
+				return
+			case err != nil:
+				app.logger.Warn("API key used from a disallowed origin", "key", key.Name, "origin", apikey.RequestOrigin(r))
+				app.apiError(w, r, http.StatusForbidden, err.Error())
+				return
+			}
+
+			if scope != "" && !key.HasScope(scope) {
+				app.apiError(w, r, http.StatusForbidden, fmt.Sprintf("API key does not have the %s scope", scope))
+				return
+			}
+
+			// Let browsers on an allowed origin read the response
+			if origin := r.Header.Get("Origin"); origin != "" && len(key.Origins) > 0 {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Add("Vary", "Origin")
+			}
+
+			ctx := context.WithValue(r.Context(), apiKeyContextKey, key)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// apiPreflight answers CORS preflight requests, which browsers send without the API key
+// before calling the API from another origin with an Authorization header. Only origins
+// some key is restricted to are allowed; the key itself is checked on the request that follows.
+func (app *application) apiPreflight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+		origin := r.Header.Get("Origin")
+		if origin == "" || !app.apiKeys.AllowsOrigin(origin) {
+			app.apiError(w, r, http.StatusForbidden, apikey.ErrOriginNotAllowed.Error())
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization")
+		w.Header().Set("Access-Control-Max-Age", "600")
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// contextAPIKey returns the API key a request was authenticated with, or nil
+func contextAPIKey(r *http.Request) *apikey.Key {
+	key, _ := r.Context().Value(apiKeyContextKey).(*apikey.Key)
+	return key
+}
+
+// withLanguage serves requests from the content in lang, for the routes under its URL prefix
+func (app *application) withLanguage(lang string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), languageContextKey, lang)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// contextLanguage returns the language a request was routed to, empty for the default
+func contextLanguage(r *http.Request) string {
+	lang, _ := r.Context().Value(languageContextKey).(string)
+	return lang
+}
+
 func (app *application) logAccess(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		mw := response.NewMetricsResponseWriter(w)
@@ -94,6 +191,10 @@ func (app *application) cacheControlMiddleware(next http.Handler) http.Handler {
 
 // isApiEndpoint checks if the path is an API endpoint
 func isApiEndpoint(path string) bool {
+	if strings.HasPrefix(path, "/api/") {
+		return true
+	}
+
 	apiPaths := []string{"/cache/stats", "/cache/clear", "/health"}
 	for _, apiPath := range apiPaths {
 		if path == apiPath {
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// reloadRedirects reloads the redirects file when it changes, keeping the previous rules if
+// the new ones don't parse
+func (app *application) reloadRedirects(path string) {
+	redirectsFile, err := filepath.Abs(app.config.redirectsFile)
+	if err != nil || path != redirectsFile {
+		return
+	}
+
+	rules, err := app.config.redirectRules()
+	if err != nil {
+		app.logger.Warn("Failed to reload redirects, keeping previous rules", "path", path, "error", err)
+		return
+	}
+	app.redirects.Store(&rules)
+	app.logger.Info("Redirects reloaded", "path", path, "rules", len(rules))
+}
+
+// redirect answers a request nothing else serves with the post or page that has its path as an
+// alias, or else the first matching rule of the redirects file, reporting whether either did.
+// The query string is kept unless the target sets its own.
+func (app *application) redirect(w http.ResponseWriter, r *http.Request) bool {
+	var target string
+	var found bool
+	for _, store := range app.allStores() {
+		if target, found = store.Alias(r.URL.Path); found {
+			break
+		}
+	}
+	status := http.StatusMovedPermanently
+
+	if !found {
+		rules := app.redirects.Load()
+		if rules == nil {
+			return false
+		}
+		target, status, found = rules.Match(r.URL.Path)
+		if !found {
+			return false
+		}
+	}
+
+	if status == http.StatusGone {
+		app.gone(w, r)
+		return true
+	}
+
+	if r.URL.RawQuery != "" && !strings.Contains(target, "?") {
+		target += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, target, status)
+	return true
+}
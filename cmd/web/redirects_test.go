@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"vellum.forge/internal/assert"
+	"vellum.forge/internal/redirect"
+)
+
+func TestRedirects(t *testing.T) {
+	app := newTestApplication(t)
+	app.contentStore = newTestContentStore(t, map[string]string{
+		"blog/renamed.md": "---\ntitle: Renamed\ndate: 2024-01-15T10:00:00Z\naliases: [old-name, /news/kept]\n---\n\nBody",
+		"pages/about.md":  "---\ntitle: About\n---\n\nAbout",
+	})
+
+	rules, err := redirect.Parse(strings.NewReader("/news/* /blog/:splat 302\n/retired 410\n/about /elsewhere\n"))
+	assert.Nil(t, err)
+	app.redirects.Store(&rules)
+
+	tests := []struct {
+		name     string
+		path     string
+		status   int
+		location string
+	}{
+		{"Redirects aliases to their post", "/blog/old-name", http.StatusMovedPermanently, "/blog/renamed"},
+		{"Keeps the query string", "/blog/old-name?page=2", http.StatusMovedPermanently, "/blog/renamed?page=2"},
+		{"Prefers aliases over rules", "/news/kept", http.StatusMovedPermanently, "/blog/renamed"},
+		{"Follows wildcard rules", "/news/hello", http.StatusFound, "/blog/hello"},
+		{"Serves live content before rules", "/about", http.StatusOK, ""},
+		{"Falls through to not found", "/blog/missing", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newTestRequest(t, http.MethodGet, tt.path)
+
+			res := send(t, req, app.routes())
+			assert.Equal(t, res.StatusCode, tt.status)
+			assert.Equal(t, res.Header.Get("Location"), tt.location)
+		})
+	}
+
+	t.Run("Renders the 410 error page for removed paths", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "/retired")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusGone)
+		assert.True(t, containsPageTag(t, res.Body, "errors/410"))
+	})
+}
+
+func TestReloadRedirects(t *testing.T) {
+	app := newTestApplication(t)
+	app.config.redirectsFile = filepath.Join(t.TempDir(), "_redirects")
+	path, err := filepath.Abs(app.config.redirectsFile)
+	assert.Nil(t, err)
+
+	err = os.WriteFile(path, []byte("/old /new\n"), 0o644)
+	assert.Nil(t, err)
+	app.reloadRedirects(path)
+	assert.Equal(t, len(*app.redirects.Load()), 1)
+
+	err = os.WriteFile(path, []byte("/old\n"), 0o644)
+	assert.Nil(t, err)
+	app.reloadRedirects(path)
+	assert.Equal(t, len(*app.redirects.Load()), 1)
+
+	err = os.Remove(path)
+	assert.Nil(t, err)
+	app.reloadRedirects(path)
+	assert.Equal(t, len(*app.redirects.Load()), 0)
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"vellum.forge/assets"
+	"vellum.forge/internal/apikey"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -30,28 +31,84 @@ func (app *application) routes() http.Handler {
 	fileServer := http.FileServer(http.FS(assets.EmbeddedFiles))
 	mux.Handle("/static/*", fileServer)
 
-	// Theme assets (css, js, images, etc from themes/{theme}/assets/)
+	// Theme assets (css, js, images, etc from themes/{theme}/assets/), with the stylesheet
+	// for highlighted code generated from the theme's highlight style
+	mux.Get("/themes/css/highlight.css", app.highlightCSS)
 	mux.Handle("/themes/*", http.HandlerFunc(app.themeAssets))
 
 	// User attachment images (from data/attachments)
 	mux.Handle("/images/*", http.HandlerFunc(app.attachmentImages))
 
 	// Routes
-	mux.Get("/", app.home)
-	mux.Get("/blog", app.blogIndex)
-	mux.Get("/blog/{slug}", app.blogPost)
-	mux.Get("/{slug}", app.page)
 	mux.Get("/health", app.health)
 
-	// RSS and sitemap
-	mux.Get("/rss", app.rssFeed)
-	mux.Get("/feed", app.rssFeed)        // Alternative RSS URL
+	// One sitemap covers every language
 	mux.Get("/sitemap.xml", app.sitemap)
 	mux.Get("/robots.txt", app.robotsTxt)
 
+	// Content in languages other than the default is served under /{lang}
+	for _, lang := range app.config.site.languages {
+		if lang != app.config.site.languages.Default() {
+			mux.With(app.withLanguage(lang)).Route("/"+lang, app.contentRoutes)
+		}
+	}
+
+	app.contentRoutes(mux)
+
+	// Read-only content API
+	mux.Route("/api/v1", func(api chi.Router) {
+		api.Use(app.apiPreflight)
+		api.NotFound(app.apiNotFound)
+		api.MethodNotAllowed(app.apiMethodNotAllowed)
+
+		api.With(app.requireAPIKey("")).Get("/key", app.apiKey)
+
+		api.Group(func(content chi.Router) {
+			content.Use(app.requireAPIKey(apikey.ScopeReadPosts))
+
+			content.Get("/posts", app.apiPosts)
+			content.Get("/posts/{slug}", app.apiPost)
+			content.Get("/pages", app.apiPages)
+			content.Get("/pages/*", app.apiPage)
+			content.Get("/tags", app.apiTags)
+		})
+	})
+
 	// Cache stats and clear
-	mux.Get("/cache/stats", app.cacheStats)
-	mux.Post("/cache/clear", app.cacheClear)
+	mux.Group(func(admin chi.Router) {
+		admin.Use(app.requireAPIKey(apikey.ScopeAdminCache))
+
+		admin.Get("/cache/stats", app.cacheStats)
+		admin.Post("/cache/clear", app.cacheClear)
+	})
 
 	return mux
 }
+
+// contentRoutes registers the routes serving posts, pages and their listings in one language
+func (app *application) contentRoutes(mux chi.Router) {
+	mux.Get("/", app.home)
+	mux.Get("/blog", app.blogIndex)
+	mux.Get("/blog/page/{page}", app.blogIndex)
+	mux.Get("/blog/archive", app.blogArchive)
+	mux.Get("/blog/{year:[0-9]{4}}", app.blogArchive)
+	mux.Get("/blog/{year:[0-9]{4}}/page/{page}", app.blogArchive)
+	mux.Get("/blog/{year:[0-9]{4}}/{month:[0-9]{2}}", app.blogArchive)
+	mux.Get("/blog/{year:[0-9]{4}}/{month:[0-9]{2}}/page/{page}", app.blogArchive)
+	mux.Get("/blog/{slug}", app.blogPost)
+	mux.Get("/author/{slug}", app.authorPage)
+	mux.Get("/author/{slug}/page/{page}", app.authorPage)
+	mux.Get("/tags", app.tagIndex)
+	mux.Get("/tag/{slug}", app.tagPage)
+	mux.Get("/tag/{slug}/page/{page}", app.tagPage)
+	mux.Get("/search", app.search)
+	mux.Get("/search.json", app.searchJSON)
+	mux.Get("/graph.json", app.graphJSON)
+
+	// RSS, one feed per language
+	mux.Get("/rss", app.rssFeed)
+	mux.Get("/feed", app.rssFeed) // Alternative RSS URL
+
+	// Pages, at their nested URLs, take every path the routes above don't
+	mux.Get("/*", app.page)
+}
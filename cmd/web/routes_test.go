@@ -2,9 +2,11 @@ package main
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 
 	"vellum.forge/internal/assert"
+	"vellum.forge/internal/content"
 )
 
 func TestRoutes(t *testing.T) {
@@ -19,6 +21,19 @@ func TestRoutes(t *testing.T) {
 		assert.True(t, len(res.Body) > 0)
 	})
 
+	t.Run("Serves the highlighting CSS in the configured style", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.config.markdown.options = content.DefaultMarkdownOptions()
+		app.config.markdown.options.HighlightStyle = "nord"
+
+		req := newTestRequest(t, http.MethodGet, "/themes/css/highlight.css")
+
+		res := send(t, req, app.routes())
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.Equal(t, res.Header.Get("Content-Type"), "text/css; charset=utf-8")
+		assert.True(t, strings.Contains(res.Body, ".chroma .kd {"))
+	})
+
 	t.Run("Renders the 404 error page for non-existent routes", func(t *testing.T) {
 		app := newTestApplication(t)
 
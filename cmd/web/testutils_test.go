@@ -7,9 +7,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"vellum.forge/internal/content"
+	"vellum.forge/internal/response"
+
 	"github.com/andybalholm/cascadia"
 	"golang.org/x/net/html"
 )
@@ -19,9 +24,50 @@ func newTestApplication(t *testing.T) *application {
 
 	app.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
 
+	jetRenderer, err := response.NewJetRenderer("../../themes/default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.jetRenderer = jetRenderer
+	app.contentStore = content.NewStore(content.NewLoader(), t.TempDir(), app.logger)
+
 	return app
 }
 
+// newTestDataDir creates a data directory holding the given files, keyed by their path
+// relative to the data directory (e.g. "blog/hello.md")
+func newTestDataDir(t *testing.T, files map[string]string) string {
+	dataDir := t.TempDir()
+
+	for name, body := range files {
+		path := filepath.Join(dataDir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte(body), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dataDir
+}
+
+// newTestContentStore creates a content store loaded from the given files, keyed by
+// their path relative to the data directory (e.g. "blog/hello.md")
+func newTestContentStore(t *testing.T, files map[string]string, opts ...content.ParserOption) *content.Store {
+	dataDir := newTestDataDir(t, files)
+
+	store := content.NewStore(content.NewLoader(opts...), dataDir, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
 func newTestRequest(t *testing.T, method, path string) *http.Request {
 	req, err := http.NewRequest(method, path, nil)
 	if err != nil {
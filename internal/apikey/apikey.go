@@ -0,0 +1,259 @@
+package apikey
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scope is a permission granted to a key
+type Scope string
+
+const (
+	ScopeReadPosts  Scope = "read_posts"  // Read published posts, pages and tags through the content API
+	ScopeReadDrafts Scope = "read_drafts" // Also read drafts, scheduled and expired content
+	ScopeAdminCache Scope = "admin_cache" // Read cache statistics and clear the cache
+)
+
+// scopes lists every known scope
+var scopes = []Scope{ScopeReadPosts, ScopeReadDrafts, ScopeAdminCache}
+
+// QueryParam is the query string parameter a key can be sent in instead of the Authorization header
+const QueryParam = "key"
+
+var (
+	ErrMissingKey       = errors.New("missing API key")
+	ErrInvalidKey       = errors.New("invalid API key")
+	ErrOriginNotAllowed = errors.New("origin not allowed for this API key")
+)
+
+// Key is an API key with the scopes it grants and the origins it may be used from
+type Key struct {
+	Name    string
+	Secret  string
+	Scopes  []Scope
+	Origins []string // Allowed request origins, any origin when empty
+
+	requests atomic.Int64
+	lastUsed atomic.Int64 // Unix nanoseconds
+}
+
+// Usage counts the requests made with a key
+type Usage struct {
+	Requests int64     `json:"requests"`
+	LastUsed time.Time `json:"last_used,omitzero"`
+}
+
+// HasScope reports whether the key grants scope
+func (k *Key) HasScope(scope Scope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// AllowsOrigin reports whether the key may be used from origin
+func (k *Key) AllowsOrigin(origin string) bool {
+	if len(k.Origins) == 0 {
+		return true
+	}
+	return slices.Contains(k.Origins, normalizeOrigin(origin))
+}
+
+// Usage returns the key's usage counters
+func (k *Key) Usage() Usage {
+	var usage Usage
+	usage.Requests = k.requests.Load()
+	if last := k.lastUsed.Load(); last != 0 {
+		usage.LastUsed = time.Unix(0, last)
+	}
+	return usage
+}
+
+func (k *Key) record(now time.Time) {
+	k.requests.Add(1)
+	k.lastUsed.Store(now.UnixNano())
+}
+
+// Keyring holds the configured keys. It is safe for concurrent use.
+type Keyring struct {
+	keys []*Key
+}
+
+// NewKeyring checks that every key has a unique name and secret and only known scopes
+func NewKeyring(keys []*Key) (*Keyring, error) {
+	names := make(map[string]bool)
+	secrets := make(map[string]bool)
+
+	for _, key := range keys {
+		if key.Name == "" {
+			return nil, errors.New("API key without a name")
+		}
+		if key.Secret == "" {
+			return nil, fmt.Errorf("API key %q has no secret", key.Name)
+		}
+		if names[key.Name] {
+			return nil, fmt.Errorf("duplicate API key name %q", key.Name)
+		}
+		if secrets[key.Secret] {
+			return nil, fmt.Errorf("API key %q reuses the secret of another key", key.Name)
+		}
+		for _, scope := range key.Scopes {
+			if !slices.Contains(scopes, scope) {
+				return nil, fmt.Errorf("API key %q has unknown scope %q", key.Name, scope)
+			}
+		}
+		for i, origin := range key.Origins {
+			key.Origins[i] = normalizeOrigin(origin)
+		}
+
+		names[key.Name] = true
+		secrets[key.Secret] = true
+	}
+
+	return &Keyring{keys: keys}, nil
+}
+
+// Keys returns the configured keys. The returned slice must not be modified.
+func (kr *Keyring) Keys() []*Key {
+	if kr == nil {
+		return nil
+	}
+	return kr.keys
+}
+
+// AllowsOrigin reports whether any key lists origin among its allowed origins, which is what
+// lets browsers on that origin call the API at all
+func (kr *Keyring) AllowsOrigin(origin string) bool {
+	origin = normalizeOrigin(origin)
+	for _, key := range kr.Keys() {
+		if slices.Contains(key.Origins, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticate finds the key sent with the request and checks the request's origin against it.
+// Every request made with a valid key is counted in its usage, even if the origin is refused.
+func (kr *Keyring) Authenticate(r *http.Request) (*Key, error) {
+	secret := FromRequest(r)
+	if secret == "" {
+		return nil, ErrMissingKey
+	}
+
+	var found *Key
+	for _, key := range kr.Keys() {
+		// Compare against every key so timing doesn't reveal how close a guess was
+		if subtle.ConstantTimeCompare([]byte(key.Secret), []byte(secret)) == 1 {
+			found = key
+		}
+	}
+	if found == nil {
+		return nil, ErrInvalidKey
+	}
+
+	found.record(time.Now())
+
+	if !found.AllowsOrigin(RequestOrigin(r)) {
+		return found, ErrOriginNotAllowed
+	}
+
+	return found, nil
+}
+
+// FromRequest returns the key sent as a Bearer token in the Authorization header
+// or in the key query parameter
+func FromRequest(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		scheme, token, found := strings.Cut(auth, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return r.URL.Query().Get(QueryParam)
+}
+
+// RequestOrigin returns the origin a browser request was made from, taken from the
+// Origin header or else the Referer. It is empty for requests that carry neither.
+func RequestOrigin(r *http.Request) string {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		return normalizeOrigin(origin)
+	}
+	if referer, err := url.Parse(r.Referer()); err == nil && referer.Host != "" {
+		return normalizeOrigin(referer.Scheme + "://" + referer.Host)
+	}
+	return ""
+}
+
+func normalizeOrigin(origin string) string {
+	return strings.ToLower(strings.TrimRight(strings.TrimSpace(origin), "/"))
+}
+
+// ParseKeys reads keys from a comma-separated list of name:secret:scopes[:origins] entries,
+// where scopes and origins are separated by plus signs, for example
+// "frontend:s3cret:read_posts:https://example.com+https://www.example.com,ops:0ther:admin_cache"
+func ParseKeys(spec string) ([]*Key, error) {
+	var keys []*Key
+	for i, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 4)
+		if len(parts) < 3 {
+			// Don't echo the entry, it may hold a secret
+			return nil, fmt.Errorf("invalid API key entry %d, expected name:secret:scopes[:origins]", i+1)
+		}
+
+		key := &Key{Name: parts[0], Secret: parts[1]}
+		for _, scope := range strings.Split(parts[2], "+") {
+			key.Scopes = append(key.Scopes, Scope(scope))
+		}
+		if len(parts) == 4 {
+			key.Origins = strings.Split(parts[3], "+")
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// keyFileEntry is a key as written in a keys file
+type keyFileEntry struct {
+	Name    string   `yaml:"name"`
+	Key     string   `yaml:"key"`
+	Scopes  []Scope  `yaml:"scopes"`
+	Origins []string `yaml:"origins"`
+}
+
+// LoadFile reads keys from a YAML file holding a list of keys, for example
+//
+//   - name: frontend
+//     key: s3cret
+//     scopes: [read_posts]
+//     origins: [https://example.com]
+func LoadFile(path string) ([]*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []keyFileEntry
+	err = yaml.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse API keys file %s: %w", path, err)
+	}
+
+	keys := make([]*Key, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, &Key{Name: entry.Name, Secret: entry.Key, Scopes: entry.Scopes, Origins: entry.Origins})
+	}
+	return keys, nil
+}
@@ -0,0 +1,138 @@
+package apikey
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"vellum.forge/internal/assert"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys("frontend:s3cret:read_posts+read_drafts:https://example.com+https://www.example.com, ops:0ther:admin_cache")
+	assert.Nil(t, err)
+	assert.Equal(t, len(keys), 2)
+
+	assert.Equal(t, keys[0].Name, "frontend")
+	assert.Equal(t, keys[0].Secret, "s3cret")
+	assert.Equal(t, keys[0].Scopes, []Scope{ScopeReadPosts, ScopeReadDrafts})
+	assert.Equal(t, keys[0].Origins, []string{"https://example.com", "https://www.example.com"})
+	assert.Equal(t, keys[1].Scopes, []Scope{ScopeAdminCache})
+	assert.Nil(t, keys[1].Origins)
+
+	_, err = ParseKeys("missing-scopes")
+	assert.NotNil(t, err)
+
+	keys, err = ParseKeys("")
+	assert.Nil(t, err)
+	assert.Equal(t, len(keys), 0)
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	err := os.WriteFile(path, []byte("- name: frontend\n  key: s3cret\n  scopes: [read_posts]\n  origins: [https://example.com]\n"), 0o600)
+	assert.Nil(t, err)
+
+	keys, err := LoadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, len(keys), 1)
+	assert.Equal(t, keys[0].Secret, "s3cret")
+	assert.Equal(t, keys[0].Origins, []string{"https://example.com"})
+}
+
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []*Key
+	}{
+		{"Missing name", []*Key{{Secret: "a"}}},
+		{"Missing secret", []*Key{{Name: "a"}}},
+		{"Duplicate name", []*Key{{Name: "a", Secret: "a"}, {Name: "a", Secret: "b"}}},
+		{"Duplicate secret", []*Key{{Name: "a", Secret: "a"}, {Name: "b", Secret: "a"}}},
+		{"Unknown scope", []*Key{{Name: "a", Secret: "a", Scopes: []Scope{"write_posts"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.keys)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestKeyring_Authenticate(t *testing.T) {
+	keyring, err := NewKeyring([]*Key{
+		{Name: "open", Secret: "open-secret", Scopes: []Scope{ScopeReadPosts}},
+		{Name: "restricted", Secret: "restricted-secret", Origins: []string{"https://Example.com/"}},
+	})
+	assert.Nil(t, err)
+
+	t.Run("Reads the key from the Authorization header or query string", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/posts", nil)
+		req.Header.Set("Authorization", "Bearer open-secret")
+		key, err := keyring.Authenticate(req)
+		assert.Nil(t, err)
+		assert.Equal(t, key.Name, "open")
+
+		req = httptest.NewRequest("GET", "/api/v1/posts?key=open-secret", nil)
+		key, err = keyring.Authenticate(req)
+		assert.Nil(t, err)
+		assert.Equal(t, key.Name, "open")
+		assert.True(t, key.HasScope(ScopeReadPosts))
+		assert.False(t, key.HasScope(ScopeAdminCache))
+	})
+
+	t.Run("Rejects missing and unknown keys", func(t *testing.T) {
+		_, err := keyring.Authenticate(httptest.NewRequest("GET", "/", nil))
+		assert.ErrorIs(t, err, ErrMissingKey)
+
+		_, err = keyring.Authenticate(httptest.NewRequest("GET", "/?key=guess", nil))
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+
+	t.Run("Checks the origin of restricted keys", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?key=restricted-secret", nil)
+		req.Header.Set("Origin", "https://example.com")
+		_, err := keyring.Authenticate(req)
+		assert.Nil(t, err)
+
+		req = httptest.NewRequest("GET", "/?key=restricted-secret", nil)
+		req.Header.Set("Referer", "https://example.com/blog/hello")
+		_, err = keyring.Authenticate(req)
+		assert.Nil(t, err)
+
+		req = httptest.NewRequest("GET", "/?key=restricted-secret", nil)
+		req.Header.Set("Origin", "https://other.com")
+		_, err = keyring.Authenticate(req)
+		assert.ErrorIs(t, err, ErrOriginNotAllowed)
+	})
+
+	t.Run("Counts usage per key", func(t *testing.T) {
+		key := keyring.Keys()[0]
+		before := key.Usage().Requests
+
+		_, _ = keyring.Authenticate(httptest.NewRequest("GET", "/?key=open-secret", nil))
+
+		assert.Equal(t, key.Usage().Requests, before+1)
+		assert.False(t, key.Usage().LastUsed.IsZero())
+	})
+
+	t.Run("A nil keyring rejects every key", func(t *testing.T) {
+		var empty *Keyring
+		_, err := empty.Authenticate(httptest.NewRequest("GET", "/?key=open-secret", nil))
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+}
+
+func TestKeyring_AllowsOrigin(t *testing.T) {
+	keyring, err := NewKeyring([]*Key{
+		{Name: "open", Secret: "open-secret"},
+		{Name: "restricted", Secret: "restricted-secret", Origins: []string{"https://Example.com/"}},
+	})
+	assert.Nil(t, err)
+
+	assert.True(t, keyring.AllowsOrigin("https://example.com"))
+	assert.False(t, keyring.AllowsOrigin("https://other.com"))
+	assert.False(t, keyring.AllowsOrigin(""))
+}
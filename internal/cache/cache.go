@@ -70,6 +70,8 @@ type KeyParams struct {
 	FeatureFlags   map[string]bool
 	AcceptEncoding string
 	ContentType    string
+	Page           int    // Page number of a paginated listing, 0 otherwise
+	Generation     uint64 // Content store generation a listing was rendered from, 0 otherwise
 }
 
 // Config contains cache configuration
@@ -128,7 +130,11 @@ func NewWithLogger(config Config, logger *slog.Logger) *Cache {
 	return c
 }
 
-// GenerateKey generates a cache key from the given parameters
+// keyPathSeparator separates the request path prefix of a key from its hash
+const keyPathSeparator = "|"
+
+// GenerateKey generates a cache key from the given parameters.
+// Keys are prefixed with the normalized request path so entries can be invalidated by path.
 func GenerateKey(params KeyParams) string {
 	h := sha256.New()
 
@@ -160,8 +166,18 @@ func GenerateKey(params KeyParams) string {
 	h.Write([]byte(params.AcceptEncoding))
 	h.Write([]byte("|"))
 	h.Write([]byte(params.ContentType))
+	h.Write([]byte("|"))
+	h.Write([]byte(strconv.Itoa(params.Page)))
+	h.Write([]byte("|"))
+	h.Write([]byte(strconv.FormatUint(params.Generation, 10)))
+
+	return fmt.Sprintf("%s%s%x", params.NormalizedPath, keyPathSeparator, h.Sum(nil))
+}
 
-	return fmt.Sprintf("%x", h.Sum(nil))
+// ExactPath returns an Invalidate pattern that only matches entries for exactly the given path,
+// rather than every path containing it
+func ExactPath(path string) string {
+	return path + keyPathSeparator
 }
 
 // Get retrieves an entry from the cache
@@ -207,3 +207,28 @@ func TestEntry_MatchesConditions(t *testing.T) {
 		t.Error("Expected different ETag to not match")
 	}
 }
+
+func TestCache_InvalidateByPath(t *testing.T) {
+	cache := New(DefaultConfig())
+	defer cache.Close()
+
+	keyFor := func(path string) string {
+		return GenerateKey(KeyParams{Method: "GET", NormalizedPath: path})
+	}
+
+	for _, path := range []string{"/", "/blog", "/tag/go", "/tags", "/about"} {
+		cache.Set(keyFor(path), &Entry{Body: []byte(path)})
+	}
+
+	if got := cache.Invalidate("/tag"); got != 2 {
+		t.Errorf("Expected /tag to invalidate 2 entries, got %d", got)
+	}
+
+	if got := cache.Invalidate(ExactPath("/")); got != 1 {
+		t.Errorf("Expected exact / to invalidate only the home page, got %d", got)
+	}
+
+	if _, found := cache.Get(keyFor("/about")); !found {
+		t.Error("Expected unrelated entries to remain cached")
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"crypto/md5"
 	"fmt"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -87,14 +86,9 @@ func NewCacheKeyBuilder(themeID, dataDir, themeDir string) *CacheKeyBuilder {
 	}
 }
 
-// BuildKey builds a cache key for the given request and context
+// BuildKey builds a cache key for a page rendered from the given files, keyed by their
+// modification times
 func (ckb *CacheKeyBuilder) BuildKey(r *http.Request, template string, filePaths []string) (string, error) {
-	// Normalize path
-	normalizedPath := strings.ToLower(strings.TrimRight(r.URL.Path, "/"))
-	if normalizedPath == "" {
-		normalizedPath = "/"
-	}
-
 	// Get file modification times
 	var maxMTime int64
 	var absFilePaths []string
@@ -111,144 +105,69 @@ func (ckb *CacheKeyBuilder) BuildKey(r *http.Request, template string, filePaths
 		}
 	}
 
-	// Create combined file path string
-	combinedFilePath := strings.Join(absFilePaths, ";")
-
-	// Detect feature flags (for now, just check for mermaid in query params or headers)
-	featureFlags := make(map[string]bool)
-	if r.URL.Query().Get("mermaid") == "1" || r.Header.Get("X-Enable-Mermaid") == "1" {
-		featureFlags["mermaid"] = true
-	}
-
-	// Get accept-encoding
-	acceptEncoding := r.Header.Get("Accept-Encoding")
-
-	// Build cache key parameters
-	params := KeyParams{
-		Method:         r.Method,
-		NormalizedPath: normalizedPath,
-		AbsFilePath:    combinedFilePath,
-		FileMTimeNs:    maxMTime,
-		Template:       template,
-		ThemeID:        ckb.themeID,
-		FeatureFlags:   featureFlags,
-		AcceptEncoding: acceptEncoding,
-		ContentType:    "text/html",
-	}
+	params := ckb.keyParams(r, template)
+	params.AbsFilePath = strings.Join(absFilePaths, ";")
+	params.FileMTimeNs = maxMTime
 
 	return GenerateKey(params), nil
 }
 
-// BuildKeyForBlogIndex builds a cache key for the blog index page
-func (ckb *CacheKeyBuilder) BuildKeyForBlogIndex(r *http.Request) (string, error) {
-	blogDir := filepath.Join(ckb.dataDir, "blog")
-
-	var filePaths []string
-	err := filepath.Walk(blogDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip files we can't access
-		}
-		if !info.IsDir() && strings.HasSuffix(strings.ToLower(path), ".md") {
-			filePaths = append(filePaths, path)
-		}
-		return nil
-	})
-
-	if err != nil {
-		return "", fmt.Errorf("failed to walk blog directory: %w", err)
-	}
+// BuildKeyForListingPage builds a cache key for one page of a listing rendered from the
+// given generation of the content store. Listings can show any file, so the generation,
+// which changes with every file, stands in for them without touching the filesystem.
+func (ckb *CacheKeyBuilder) BuildKeyForListingPage(r *http.Request, template string, generation uint64, page int) string {
+	params := ckb.keyParams(r, template)
+	params.Generation = generation
+	params.Page = page
 
-	return ckb.BuildKey(r, "pages/blog/index.jet", filePaths)
+	return GenerateKey(params)
 }
 
-// BuildKeyForBlogPost builds a cache key for a specific blog post
-func (ckb *CacheKeyBuilder) BuildKeyForBlogPost(r *http.Request, slug string) (string, error) {
-	blogDir := filepath.Join(ckb.dataDir, "blog")
-
-	// Find the specific blog post file
-	var filePath string
-	err := filepath.Walk(blogDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-		if !info.IsDir() && strings.HasSuffix(strings.ToLower(path), ".md") {
-			base := filepath.Base(path)
-			ext := filepath.Ext(base)
-			fileSlug := strings.TrimSuffix(base, ext)
-			if fileSlug == slug {
-				filePath = path
-				return filepath.SkipDir
-			}
-		}
-		return nil
-	})
-
-	if err != nil {
-		return "", fmt.Errorf("failed to find blog post: %w", err)
-	}
-
-	if filePath == "" {
-		return "", fmt.Errorf("blog post not found: %s", slug)
-	}
+// BuildKeyForBlogIndex builds a cache key for a page of the blog index
+func (ckb *CacheKeyBuilder) BuildKeyForBlogIndex(r *http.Request, generation uint64, page int) string {
+	return ckb.BuildKeyForListingPage(r, "pages/blog/index.jet", generation, page)
+}
 
+// BuildKeyForBlogPost builds a cache key for a blog post from its source file, as translations
+// share a slug but not a file
+func (ckb *CacheKeyBuilder) BuildKeyForBlogPost(r *http.Request, filePath string) (string, error) {
 	return ckb.BuildKey(r, "pages/blog/post.jet", []string{filePath})
 }
 
-// BuildKeyForPage builds a cache key for a regular page
-func (ckb *CacheKeyBuilder) BuildKeyForPage(r *http.Request, slug string) (string, error) {
-	pagesDir := filepath.Join(ckb.dataDir, "pages")
-
-	// Find the specific page file
-	var filePath string
-	err := filepath.Walk(pagesDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-		if !info.IsDir() && strings.HasSuffix(strings.ToLower(path), ".md") {
-			base := filepath.Base(path)
-			ext := filepath.Ext(base)
-			fileSlug := strings.TrimSuffix(base, ext)
-			if fileSlug == slug {
-				filePath = path
-				return filepath.SkipDir
-			}
-		}
-		return nil
-	})
-
-	if err != nil {
-		return "", fmt.Errorf("failed to find page: %w", err)
-	}
-
-	if filePath == "" {
-		return "", fmt.Errorf("page not found: %s", slug)
-	}
-
+// BuildKeyForPage builds a cache key for a regular page from its source file, as pages in
+// sections can't be found by their slug alone
+func (ckb *CacheKeyBuilder) BuildKeyForPage(r *http.Request, filePath string) (string, error) {
 	return ckb.BuildKey(r, "pages/page.jet", []string{filePath})
 }
 
-// BuildKeyForHome builds a cache key for the home page
-func (ckb *CacheKeyBuilder) BuildKeyForHome(r *http.Request) (string, error) {
-	// Home page might depend on recent blog posts, so include blog directory
-	blogDir := filepath.Join(ckb.dataDir, "blog")
+// BuildKeyForHome builds a cache key for the home page, which lists recent blog posts
+func (ckb *CacheKeyBuilder) BuildKeyForHome(r *http.Request, generation uint64) string {
+	return ckb.BuildKeyForListingPage(r, "pages/home.jet", generation, 0)
+}
 
-	var filePaths []string
-	err := filepath.Walk(blogDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-		if !info.IsDir() && strings.HasSuffix(strings.ToLower(path), ".md") {
-			filePaths = append(filePaths, path)
-		}
-		return nil
-	})
+// keyParams returns the key parameters every page shares, from the request and template
+func (ckb *CacheKeyBuilder) keyParams(r *http.Request, template string) KeyParams {
+	// Normalize path
+	normalizedPath := strings.ToLower(strings.TrimRight(r.URL.Path, "/"))
+	if normalizedPath == "" {
+		normalizedPath = "/"
+	}
 
-	if err != nil {
-		// If we can't read blog posts, just cache based on template
-		return ckb.BuildKey(r, "pages/home.jet", []string{})
+	// Detect feature flags (for now, just check for mermaid in query params or headers)
+	featureFlags := make(map[string]bool)
+	if r.URL.Query().Get("mermaid") == "1" || r.Header.Get("X-Enable-Mermaid") == "1" {
+		featureFlags["mermaid"] = true
 	}
 
-	return ckb.BuildKey(r, "pages/home.jet", filePaths)
+	return KeyParams{
+		Method:         r.Method,
+		NormalizedPath: normalizedPath,
+		Template:       template,
+		ThemeID:        ckb.themeID,
+		FeatureFlags:   featureFlags,
+		AcceptEncoding: r.Header.Get("Accept-Encoding"),
+		ContentType:    "text/html",
+	}
 }
 
 // GenerateETag generates an ETag for response content
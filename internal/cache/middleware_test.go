@@ -64,3 +64,21 @@ func TestResponseCapture_HeaderHandling(t *testing.T) {
 		t.Errorf("Expected flushed body 'test response body', got '%s'", w.Body.String())
 	}
 }
+
+func TestCacheKeyBuilder_BuildKeyForListingPage(t *testing.T) {
+	ckb := NewCacheKeyBuilder("default", t.TempDir(), t.TempDir())
+	req := httptest.NewRequest(http.MethodGet, "/blog", nil)
+
+	first := ckb.BuildKeyForListingPage(req, "pages/blog/index.jet", 1, 1)
+	second := ckb.BuildKeyForListingPage(req, "pages/blog/index.jet", 1, 2)
+	if first == second {
+		t.Error("Expected each page of a listing to get its own cache key")
+	}
+
+	if ckb.BuildKeyForListingPage(req, "pages/blog/index.jet", 1, 1) != first {
+		t.Error("Expected the same key while the content is unchanged")
+	}
+	if ckb.BuildKeyForListingPage(req, "pages/blog/index.jet", 2, 1) == first {
+		t.Error("Expected a new key once the content store's generation changes")
+	}
+}
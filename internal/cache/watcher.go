@@ -11,16 +11,18 @@ import (
 
 // FileWatcher watches for file changes and triggers cache invalidation
 type FileWatcher struct {
-	cache   *Cache
-	logger  *slog.Logger
-	paths   map[string]bool // paths being watched
-	mu      sync.RWMutex
-	stopCh  chan struct{}
-	wg      sync.WaitGroup
-	pollInt time.Duration // polling interval
+	cache     *Cache
+	logger    *slog.Logger
+	paths     map[string]bool // paths being watched
+	listeners []func(path string)
+	mu        sync.RWMutex
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+	pollInt   time.Duration // polling interval
 }
 
-// NewFileWatcher creates a new file watcher
+// NewFileWatcher creates a new file watcher.
+// The cache may be nil, in which case only the registered listeners are notified.
 func NewFileWatcher(cache *Cache, logger *slog.Logger) *FileWatcher {
 	return &FileWatcher{
 		cache:   cache,
@@ -49,6 +51,16 @@ func (fw *FileWatcher) Watch(path string) error {
 	return nil
 }
 
+// OnChange registers a function that is called with the path of every file that
+// is created, modified or removed. Listeners run before any cache invalidation so
+// that data derived from the file is fresh by the time a request misses the cache.
+func (fw *FileWatcher) OnChange(fn func(path string)) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	fw.listeners = append(fw.listeners, fn)
+}
+
 // Start begins the file watching process
 func (fw *FileWatcher) Start() {
 	fw.wg.Add(1)
@@ -65,8 +77,9 @@ func (fw *FileWatcher) Stop() {
 func (fw *FileWatcher) watchLoop() {
 	defer fw.wg.Done()
 
-	// Track file modification times
+	// Track file modification times, priming them so existing files aren't reported as new
 	fileTimes := make(map[string]time.Time)
+	fw.checkForChanges(fileTimes, true)
 
 	ticker := time.NewTicker(fw.pollInt)
 	defer ticker.Stop()
@@ -74,15 +87,16 @@ func (fw *FileWatcher) watchLoop() {
 	for {
 		select {
 		case <-ticker.C:
-			fw.checkForChanges(fileTimes)
+			fw.checkForChanges(fileTimes, false)
 		case <-fw.stopCh:
 			return
 		}
 	}
 }
 
-// checkForChanges checks all watched paths for file changes
-func (fw *FileWatcher) checkForChanges(fileTimes map[string]time.Time) {
+// checkForChanges checks all watched paths for created, modified and removed files.
+// When priming, the current state is recorded without reporting any changes.
+func (fw *FileWatcher) checkForChanges(fileTimes map[string]time.Time, priming bool) {
 	fw.mu.RLock()
 	paths := make([]string, 0, len(fw.paths))
 	for path := range fw.paths {
@@ -90,25 +104,33 @@ func (fw *FileWatcher) checkForChanges(fileTimes map[string]time.Time) {
 	}
 	fw.mu.RUnlock()
 
+	seen := make(map[string]bool, len(fileTimes))
+
 	for _, watchPath := range paths {
 		err := filepath.Walk(watchPath, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return nil // Skip files we can't access
 			}
 
-			// Only watch markdown files and template files
+			// Only watch markdown files, template files and the redirects file
 			if info.IsDir() || (!strings.HasSuffix(strings.ToLower(path), ".md") &&
 				!strings.HasSuffix(strings.ToLower(path), ".jet") &&
-				!strings.HasSuffix(strings.ToLower(path), ".tmpl")) {
+				!strings.HasSuffix(strings.ToLower(path), ".tmpl") &&
+				info.Name() != "_redirects") {
 				return nil
 			}
 
 			modTime := info.ModTime()
 			lastTime, exists := fileTimes[path]
+			seen[path] = true
 
 			if !exists {
 				// First time seeing this file
 				fileTimes[path] = modTime
+				if !priming {
+					fw.logger.Info("File created, invalidating cache", "path", path)
+					fw.handleFileChange(path)
+				}
 				return nil
 			}
 
@@ -126,25 +148,53 @@ func (fw *FileWatcher) checkForChanges(fileTimes map[string]time.Time) {
 			fw.logger.Error("Error walking watched path", "path", watchPath, "error", err)
 		}
 	}
+
+	// Anything we knew about but didn't see this time has been removed
+	for path := range fileTimes {
+		if !seen[path] {
+			delete(fileTimes, path)
+			fw.logger.Info("File removed, invalidating cache", "path", path)
+			fw.handleFileChange(path)
+		}
+	}
 }
 
 // handleFileChange processes a file change event
 func (fw *FileWatcher) handleFileChange(filePath string) {
 	absPath, _ := filepath.Abs(filePath)
 
+	// Let listeners refresh derived data before cached responses are dropped
+	fw.mu.RLock()
+	listeners := fw.listeners
+	fw.mu.RUnlock()
+	for _, listener := range listeners {
+		listener(absPath)
+	}
+
+	if fw.cache == nil {
+		return
+	}
+
 	// Invalidate entries related to this file
 	invalidated := fw.cache.InvalidateByFilePath(absPath)
 
 	// For certain file types, also invalidate related caches
 	if strings.Contains(absPath, "blog") {
-		// If a blog post changed, also invalidate blog index
+		// If a blog post changed, also invalidate blog index and every other post,
+		// since any post's related posts may have changed
 		blogIndexInvalidated := fw.cache.Invalidate("/blog")
 		invalidated += blogIndexInvalidated
 
 		// Also invalidate home page if it shows recent blog posts
-		homeInvalidated := fw.cache.Invalidate("/")
+		homeInvalidated := fw.cache.Invalidate(ExactPath("/"))
 		invalidated += homeInvalidated
 
+		// Tag and author archives list posts, and a post's tags or authors may have changed
+		tagsInvalidated := fw.cache.Invalidate("/tag")
+		invalidated += tagsInvalidated
+		authorsInvalidated := fw.cache.Invalidate("/author")
+		invalidated += authorsInvalidated
+
 		// Invalidate RSS feed and sitemap as they include blog posts
 		rssInvalidated := fw.cache.Invalidate("rss:")
 		invalidated += rssInvalidated
@@ -152,10 +202,18 @@ func (fw *FileWatcher) handleFileChange(filePath string) {
 		invalidated += sitemapInvalidated
 	}
 
-	// If a page changed, invalidate sitemap
+	// If an author profile changed, invalidate their archive page
+	if strings.Contains(absPath, "authors") {
+		authorsInvalidated := fw.cache.Invalidate("/author")
+		invalidated += authorsInvalidated
+	}
+
+	// If a page changed, invalidate sitemap, and posts since they list the pages linking to them
 	if strings.Contains(absPath, "pages") {
 		sitemapInvalidated := fw.cache.Invalidate("sitemap:")
 		invalidated += sitemapInvalidated
+		blogInvalidated := fw.cache.Invalidate("/blog")
+		invalidated += blogInvalidated
 	}
 
 	// If theme files changed, invalidate everything
@@ -172,6 +230,9 @@ func (fw *FileWatcher) handleFileChange(filePath string) {
 
 // InvalidateForPath manually invalidates cache entries for a specific path
 func (fw *FileWatcher) InvalidateForPath(path string) int {
+	if fw.cache == nil {
+		return 0
+	}
 	absPath, _ := filepath.Abs(path)
 	return fw.cache.InvalidateByFilePath(absPath)
 }
@@ -215,7 +276,12 @@ func (ci *CacheInvalidator) InvalidateBlogIndex() int {
 
 // InvalidateHome invalidates the home page cache
 func (ci *CacheInvalidator) InvalidateHome() int {
-	return ci.InvalidateByPath("/")
+	return ci.InvalidateByPath(ExactPath("/"))
+}
+
+// InvalidateTags invalidates the tag index and every tag archive page
+func (ci *CacheInvalidator) InvalidateTags() int {
+	return ci.InvalidateByPath("/tag")
 }
 
 // InvalidateFeed invalidates the RSS feed cache
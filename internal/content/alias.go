@@ -0,0 +1,86 @@
+package content
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// aliasPath returns the URL path of an alias, which is relative to the content's section
+// (under urlPrefix) unless it starts with a slash
+func aliasPath(urlPrefix string, kind Kind, alias string) (string, bool) {
+	alias = strings.TrimSpace(alias)
+	if alias == "" || strings.Contains(alias, "://") {
+		return "", false
+	}
+
+	if !strings.HasPrefix(alias, "/") {
+		if kind == KindPost {
+			alias = urlPrefix + "/blog/" + alias
+		} else {
+			alias = urlPrefix + "/" + alias
+		}
+	}
+	return path.Clean(alias), true
+}
+
+// buildAliases maps the aliases of published posts and pages to their URLs. Aliases that are the URL of published content or were already claimed by
+// newer content are reported rather than indexed, though live content always wins anyway as
+// aliases are only checked for paths nothing else serves.
+func (s *Store) buildAliases(postList, pageList []*Content) (map[string]string, []Problem) {
+	live := make(map[string]string, len(postList)+len(pageList))
+	for _, post := range postList {
+		live[s.urlPrefix+"/blog/"+post.Frontmatter.Slug] = post.Path
+	}
+	for _, page := range pageList {
+		live[s.urlPrefix+"/"+page.Frontmatter.Slug] = page.Path
+	}
+
+	aliases := make(map[string]string)
+	claimedBy := make(map[string]string)
+	var conflicts []Problem
+
+	add := func(kind Kind, content *Content, url string) {
+		for _, alias := range content.Frontmatter.Aliases {
+			aliasURL, ok := aliasPath(s.urlPrefix, kind, alias)
+			switch {
+			case !ok:
+				conflicts = append(conflicts, Problem{Path: s.relPath(content.Path), Severity: SeverityError, Message: fmt.Sprintf("alias %q must be a path", alias)})
+			case aliasURL == url:
+				conflicts = append(conflicts, Problem{Path: s.relPath(content.Path), Severity: SeverityError, Message: fmt.Sprintf("alias %s is the URL of the content itself", aliasURL)})
+			case live[aliasURL] != "":
+				conflicts = append(conflicts, Problem{Path: s.relPath(content.Path), Severity: SeverityError, Message: fmt.Sprintf("alias %s is the URL of %s", aliasURL, s.relPath(live[aliasURL]))})
+			case claimedBy[aliasURL] != "" && aliases[aliasURL] != url:
+				conflicts = append(conflicts, Problem{Path: s.relPath(content.Path), Severity: SeverityError, Message: fmt.Sprintf("alias %s is already used by %s", aliasURL, s.relPath(claimedBy[aliasURL]))})
+			default:
+				aliases[aliasURL] = url
+				claimedBy[aliasURL] = content.Path
+			}
+		}
+	}
+
+	for _, post := range postList {
+		add(KindPost, post, s.urlPrefix+"/blog/"+post.Frontmatter.Slug)
+	}
+	for _, page := range pageList {
+		add(KindPage, page, s.urlPrefix+"/"+page.Frontmatter.Slug)
+	}
+
+	return aliases, conflicts
+}
+
+// Alias returns the URL of the published post or page that has urlPath as an alias
+func (s *Store) Alias(urlPath string) (string, bool) {
+	s.rlock()
+	defer s.mu.RUnlock()
+	url, found := s.aliases[path.Clean(urlPath)]
+	return url, found
+}
+
+// AliasConflicts returns the aliases that can't redirect, because published content has that
+// URL, another post or page claims it too, or it isn't a path
+func (s *Store) AliasConflicts() []Problem {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.aliasConflicts
+}
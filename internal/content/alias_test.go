@@ -0,0 +1,51 @@
+package content
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestStore_Aliases(t *testing.T) {
+	store, dataDir := newTestStore(t)
+
+	writeContentFile(t, filepath.Join(dataDir, "blog", "renamed.md"), "---\ntitle: Renamed\ndate: 2024-02-15T10:00:00Z\naliases: [old-name, /2024/old-name/, /about, second, renamed]\n---\n\nBody")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "second.md"), "---\ntitle: Second\ndate: 2024-01-15T10:00:00Z\naliases: [/2024/old-name, \"https://example.com/x\"]\n---\n\nBody")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "draft.md"), "---\ntitle: Draft\ndate: 2024-03-15T10:00:00Z\ndraft: true\naliases: [draft-alias]\n---\n\nBody")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "about.md"), "---\ntitle: About\naliases: [about-us]\n---\n\nAbout")
+
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path  string
+		url   string
+		found bool
+	}{
+		{"/blog/old-name", "/blog/renamed", true},
+		{"/blog/old-name/", "/blog/renamed", true},
+		{"/2024/old-name", "/blog/renamed", true},
+		{"/about-us", "/about", true},
+		{"/blog/draft-alias", "", false},
+		{"/about", "", false},
+	}
+	for _, tt := range tests {
+		url, found := store.Alias(tt.path)
+		if url != tt.url || found != tt.found {
+			t.Errorf("Alias(%q) = %q, %v, expected %q, %v", tt.path, url, found, tt.url, tt.found)
+		}
+	}
+
+	got := problemStrings(store.AliasConflicts(), dataDir)
+	want := []string{
+		"blog/renamed.md: alias /about is the URL of " + filepath.Join(dataDir, "pages", "about.md"),
+		"blog/renamed.md: alias /blog/second is the URL of " + filepath.Join(dataDir, "blog", "second.md"),
+		"blog/renamed.md: alias /blog/renamed is the URL of the content itself",
+		"blog/second.md: alias /2024/old-name is already used by " + filepath.Join(dataDir, "blog", "renamed.md"),
+		`blog/second.md: alias "https://example.com/x" must be a path`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected alias conflicts\n%v\ngot\n%v", want, got)
+	}
+}
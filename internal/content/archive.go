@@ -0,0 +1,56 @@
+package content
+
+import (
+	"fmt"
+	"time"
+)
+
+// ArchiveYear groups the published posts of one year for the archive overview
+type ArchiveYear struct {
+	Year   int
+	Count  int             // Number of published posts in the year
+	Months []*ArchiveMonth // Months with posts, newest first
+}
+
+// URL returns the path of the year's archive page
+func (y *ArchiveYear) URL() string {
+	return fmt.Sprintf("/blog/%04d", y.Year)
+}
+
+// ArchiveMonth counts the published posts of one month
+type ArchiveMonth struct {
+	Year  int
+	Month time.Month
+	Count int
+}
+
+// URL returns the path of the month's archive page
+func (m *ArchiveMonth) URL() string {
+	return fmt.Sprintf("/blog/%04d/%02d", m.Year, int(m.Month))
+}
+
+// Date returns the first day of the month, for templates to name it with formatDate
+// in the page's language
+func (m *ArchiveMonth) Date() time.Time {
+	return time.Date(m.Year, m.Month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// buildArchive groups posts by year and month. Posts must be sorted newest first.
+func buildArchive(posts []*Content) []*ArchiveYear {
+	var years []*ArchiveYear
+	for _, post := range posts {
+		date := post.GetDate()
+
+		if len(years) == 0 || years[len(years)-1].Year != date.Year() {
+			years = append(years, &ArchiveYear{Year: date.Year()})
+		}
+		year := years[len(years)-1]
+		year.Count++
+
+		if len(year.Months) == 0 || year.Months[len(year.Months)-1].Month != date.Month() {
+			year.Months = append(year.Months, &ArchiveMonth{Year: date.Year(), Month: date.Month()})
+		}
+		year.Months[len(year.Months)-1].Count++
+	}
+	return years
+}
@@ -0,0 +1,69 @@
+package content
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Author represents an author profile loaded from data/authors/{slug}.md
+type Author struct {
+	Slug     string            `yaml:"slug"`
+	Name     string            `yaml:"name"`
+	Email    string            `yaml:"email"`
+	Bio      string            `yaml:"bio"`
+	Avatar   string            `yaml:"avatar"`
+	Website  string            `yaml:"website"`
+	Location string            `yaml:"location"`
+	Twitter  string            `yaml:"twitter"`
+	GitHub   string            `yaml:"github"`
+	Social   map[string]string `yaml:"social"` // Any other profile links keyed by network name
+
+	HTML    string    `yaml:"-"` // Extended bio rendered from the markdown body
+	Path    string    `yaml:"-"`
+	ModTime time.Time `yaml:"-"`
+}
+
+// URL returns the path of the author's archive page
+func (a *Author) URL() string {
+	return "/author/" + a.Slug
+}
+
+// DisplayName returns the author's name, falling back to the slug
+func (a *Author) DisplayName() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return a.Slug
+}
+
+// LoadAuthor loads and parses a single author profile file
+func (l *Loader) LoadAuthor(filePath string) (*Author, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+
+	fi, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file %s: %w", filePath, err)
+	}
+
+	author, err := l.parser.ParseAuthor(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse author from %s: %w", filePath, err)
+	}
+
+	// Generate slug from filename if not provided in frontmatter
+	if author.Slug == "" {
+		base := filepath.Base(filePath)
+		author.Slug = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+
+	author.Path = filePath
+	author.ModTime = fi.ModTime()
+
+	return author, nil
+}
@@ -0,0 +1,146 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"vellum.forge/internal/validator"
+)
+
+// Severities of problems
+const (
+	SeverityError   = "error"   // Something is broken, such as a file that fails to load or a broken link
+	SeverityWarning = "warning" // The content works but may not read as intended, such as HTML the sanitizer removed
+)
+
+// Problem is something wrong with a content file, found by Store.Check
+type Problem struct {
+	Path     string `json:"path"`           // Source file, within the data directory
+	Line     int    `json:"line,omitempty"` // 1-based line, zero if unknown
+	Severity string `json:"severity"`       // SeverityError or SeverityWarning
+	Message  string `json:"message"`
+}
+
+func (p Problem) String() string {
+	message := p.Message
+	if p.Severity == SeverityWarning {
+		message = "warning: " + message
+	}
+	if p.Line == 0 {
+		return fmt.Sprintf("%s: %s", p.Path, message)
+	}
+	return fmt.Sprintf("%s:%d: %s", p.Path, p.Line, message)
+}
+
+// Check loads every file in the data directory, like Load but keeping whatever loads when some
+// files don't, and reports the problems found. Errors are files that fail to load, slugs used by
+// more than one file, posts and pages without a title, posts without a date, tags missing from
+// knownTags (unless empty) and aliases that can't redirect. Warnings are tags sharing a slug,
+// authors without a profile and the warnings of parsing, such as unresolved wiki-links.
+// Internal links and covers are checked with linkExists, if set, as only the caller knows every
+// route the site serves.
+func (s *Store) Check(knownTags []string, linkExists func(path string) bool) ([]Problem, error) {
+	read, err := s.readFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	s.replaceFiles(read)
+	s.mu.RLock()
+	files, kinds, authors := s.files, s.kinds, s.authors
+	s.mu.RUnlock()
+
+	var problems []Problem
+	for path, err := range read.failed {
+		// The loader names the file in its errors, which Problem already does
+		if cause := errors.Unwrap(err); cause != nil {
+			err = cause
+		}
+		problems = append(problems, Problem{Path: s.relPath(path), Severity: SeverityError, Message: err.Error()})
+	}
+
+	known := make([]string, 0, len(knownTags))
+	for _, name := range knownTags {
+		known = append(known, TagSlug(name))
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	slugs := map[Kind]map[string]string{KindPost: {}, KindPage: {}}
+	for _, path := range paths {
+		content, kind := files[path], kinds[path]
+		fm := content.Frontmatter
+
+		var v validator.Validator
+		v.Check(validator.NotBlank(fm.Title), "missing title")
+		if kind == KindPost {
+			v.Check(!fm.Date.IsZero(), "missing date")
+		}
+
+		if first, exists := slugs[kind][fm.Slug]; exists {
+			v.AddError(fmt.Sprintf("duplicate slug %q, already used by %s", fm.Slug, s.relPath(first)))
+		} else {
+			slugs[kind][fm.Slug] = path
+		}
+
+		if len(known) > 0 {
+			for _, tag := range content.Tags() {
+				v.Check(validator.In(tag.Slug, known...), fmt.Sprintf("unknown tag %q", tag.Name))
+			}
+		}
+
+		var warnings []Warning
+		for _, slug := range unknownAuthors(content, authors) {
+			warnings = append(warnings, Warning{Message: fmt.Sprintf("unknown author %q", slug)})
+		}
+
+		if linkExists != nil {
+			if cover, ok := internalLinkPath(fm.Cover); ok {
+				v.Check(linkExists(cover), fmt.Sprintf("missing cover image %s", fm.Cover))
+			}
+			for _, link := range content.Links {
+				v.Check(linkExists(link), fmt.Sprintf("broken link to %s", link))
+			}
+		}
+
+		for _, message := range v.Errors {
+			problems = append(problems, Problem{Path: s.relPath(path), Severity: SeverityError, Message: message})
+		}
+		for _, warning := range append(warnings, content.Warnings...) {
+			problems = append(problems, Problem{Path: s.relPath(path), Line: warning.Line, Severity: SeverityWarning, Message: warning.Message})
+		}
+	}
+
+	problems = append(problems, s.AliasConflicts()...)
+	problems = append(problems, s.TagConflicts()...)
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Path != problems[j].Path {
+			return problems[i].Path < problems[j].Path
+		}
+		return problems[i].Line < problems[j].Line
+	})
+
+	return problems, nil
+}
+
+// relPath returns an absolute path within the data directory as the data directory joined
+// with the rest, so paths are reported the way the data directory was configured
+func (s *Store) relPath(absPath string) string {
+	absDataDir, err := filepath.Abs(s.dataDir)
+	if err != nil {
+		return absPath
+	}
+
+	rel, err := filepath.Rel(absDataDir, absPath)
+	if err != nil {
+		return absPath
+	}
+	return filepath.Join(s.dataDir, rel)
+}
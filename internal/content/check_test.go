@@ -0,0 +1,63 @@
+package content
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func problemStrings(problems []Problem, dataDir string) []string {
+	var lines []string
+	for _, problem := range problems {
+		lines = append(lines, strings.TrimPrefix(problem.String(), dataDir+string(filepath.Separator)))
+	}
+	return lines
+}
+
+func TestStore_Check(t *testing.T) {
+	store, dataDir := newTestStore(t)
+
+	writeContentFile(t, filepath.Join(dataDir, "blog", "a-first.md"), "---\ntitle: First\ndate: 2024-01-15T10:00:00Z\ntags: [\"Go\", \"cooking\"]\nauthor: ghost\ncover: /images/cover.jpg\n---\n\nSee [about](/about), [gone](/blog/gone) and [[nowhere]]")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "b-copy.md"), "---\ntitle: Copy\ndate: 2024-01-16T10:00:00Z\nslug: a-first\n---\n\nCopy")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "c-untitled.md"), "---\ndraft: true\n---\n\nNo title or date")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "d-broken.md"), "---\ntitle: [unclosed\n---\n\nBroken")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "about.md"), "---\ntitle: About\n---\n\nAbout")
+
+	linkExists := func(link string) bool {
+		return link == "/about" || link == "/blog/a-first"
+	}
+	problems, err := store.Check([]string{"go", "web"}, linkExists)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := problemStrings(problems, dataDir)
+	want := []string{
+		`blog/a-first.md: unknown tag "cooking"`,
+		"blog/a-first.md: missing cover image /images/cover.jpg",
+		"blog/a-first.md: broken link to /blog/gone",
+		`blog/a-first.md: warning: unknown author "ghost"`,
+		`blog/a-first.md:9: warning: unresolved wiki-link "nowhere"`,
+		`blog/b-copy.md: duplicate slug "a-first", already used by ` + filepath.Join(dataDir, "blog", "a-first.md"),
+		"blog/c-untitled.md: missing title",
+		"blog/c-untitled.md: missing date",
+	}
+	if len(got) != len(want)+1 || !slices.Equal(got[:len(want)], want) {
+		t.Fatalf("Expected problems\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if last := got[len(want)]; !strings.HasPrefix(last, "blog/d-broken.md: failed to decode yaml frontmatter") {
+		t.Errorf("Expected the broken file to be reported without repeating its path, got %q", last)
+	}
+
+	if _, found := store.Post("a-first"); !found {
+		t.Error("Expected the files that load to be in the store")
+	}
+
+	t.Run("Load refuses broken files", func(t *testing.T) {
+		err := store.Load()
+		if err == nil || !strings.Contains(err.Error(), "d-broken.md") {
+			t.Errorf("Expected the broken file to fail the load, got %v", err)
+		}
+	})
+}
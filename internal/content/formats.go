@@ -0,0 +1,221 @@
+package content
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FrontmatterFormat is the format a content file's frontmatter is written in
+type FrontmatterFormat string
+
+const (
+	FormatNone FrontmatterFormat = ""
+	FormatYAML FrontmatterFormat = "yaml" // Between --- lines
+	FormatTOML FrontmatterFormat = "toml" // Between +++ lines, as written by Hugo
+	FormatJSON FrontmatterFormat = "json" // A JSON object opening the file
+)
+
+// frontmatterDelimiters maps the delimiter lines of YAML and TOML frontmatter to their format
+var frontmatterDelimiters = map[string]FrontmatterFormat{
+	"---": FormatYAML,
+	"+++": FormatTOML,
+}
+
+// document is a content file split into its frontmatter and markdown body
+type document struct {
+	format      FrontmatterFormat
+	frontmatter []byte // Without the delimiters
+	body        []byte // Everything after the frontmatter
+	bodyLine    int    // 1-based line of the source file the body starts on
+}
+
+// splitFrontmatter separates the frontmatter from the body of a content file. A leading
+// byte order mark is dropped and CRLF line endings are read as LF. Files without a complete
+// frontmatter block are all body.
+func splitFrontmatter(content []byte) *document {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+
+	doc := &document{body: content, bodyLine: 1}
+
+	firstLine, rest, _ := bytes.Cut(content, []byte("\n"))
+	if format, ok := frontmatterDelimiters[string(bytes.TrimRight(firstLine, " \t"))]; ok {
+		delimiter := bytes.TrimRight(firstLine, " \t")
+
+		start := len(firstLine) + 1
+		for offset := 0; offset < len(rest); {
+			line, _, _ := bytes.Cut(rest[offset:], []byte("\n"))
+			if bytes.Equal(bytes.TrimRight(line, " \t"), delimiter) {
+				doc.format = format
+				doc.frontmatter = content[start : start+offset]
+				doc.body = content[min(start+offset+len(line)+1, len(content)):]
+				doc.bodyLine = bytes.Count(content[:len(content)-len(doc.body)], []byte("\n")) + 1
+				return doc
+			}
+			offset += len(line) + 1
+		}
+
+		return doc
+	}
+
+	// Shortcodes open with {{, JSON frontmatter with a single brace. A file is only taken to
+	// open with JSON frontmatter when it holds an object alone on its closing line, so markdown
+	// that happens to start with a brace stays body.
+	if bytes.HasPrefix(content, []byte("{")) && !bytes.HasPrefix(content, []byte("{{")) {
+		dec := json.NewDecoder(bytes.NewReader(content))
+		var fields map[string]json.RawMessage
+		if err := dec.Decode(&fields); err != nil || fields == nil {
+			return doc
+		}
+
+		end := int(dec.InputOffset())
+		trailing, _, _ := bytes.Cut(content[end:], []byte("\n"))
+		if len(bytes.TrimSpace(trailing)) > 0 {
+			return doc
+		}
+
+		doc.format = FormatJSON
+		doc.frontmatter = content[:end]
+		doc.body = content[min(end+len(trailing)+1, len(content)):]
+		doc.bodyLine = bytes.Count(content[:len(content)-len(doc.body)], []byte("\n")) + 1
+	}
+
+	return doc
+}
+
+// markdown returns the body preceded by a blank line for every line of frontmatter,
+// so line numbers reported while rendering match the source file
+func (d *document) markdown() []byte {
+	return append(bytes.Repeat([]byte("\n"), d.bodyLine-1), d.body...)
+}
+
+// Body returns the markdown after the frontmatter, trimmed when there was frontmatter
+func (d *document) Body() string {
+	if d.format == FormatNone {
+		return string(d.body)
+	}
+	return string(bytes.TrimSpace(d.body))
+}
+
+// decode decodes the frontmatter into dst, through the yaml tags of its fields, and into a map.
+// Whatever the format, values have the types YAML decodes to: string, bool, int, float64,
+// time.Time, []any and map[string]any. JSON strings holding an RFC 3339 time or a
+// 2006-01-02 date are decoded as time.Time, like unquoted YAML timestamps.
+func (d *document) decode(dst any) (map[string]any, error) {
+	node, err := d.node()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s frontmatter: %w", d.format, err)
+	}
+
+	params := make(map[string]any)
+	if node.Kind == 0 { // No or empty frontmatter
+		return params, nil
+	}
+
+	if dst != nil {
+		if err := node.Decode(dst); err != nil {
+			return nil, fmt.Errorf("failed to decode %s frontmatter: %w", d.format, err)
+		}
+	}
+	if err := node.Decode(&params); err != nil {
+		return nil, fmt.Errorf("failed to decode %s frontmatter: %w", d.format, err)
+	}
+	return params, nil
+}
+
+// node parses the frontmatter into a YAML node, which keeps the yaml field tags the single
+// description of every format
+func (d *document) node() (*yaml.Node, error) {
+	var node yaml.Node
+	raw := make(map[string]any)
+
+	switch d.format {
+	case FormatNone:
+		return &node, nil
+	case FormatYAML:
+		return &node, yaml.Unmarshal(d.frontmatter, &node)
+	case FormatTOML:
+		if _, err := toml.Decode(string(d.frontmatter), &raw); err != nil {
+			return nil, err
+		}
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(d.frontmatter))
+		dec.UseNumber()
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+	}
+
+	for key, value := range raw {
+		raw[key] = normalizeValue(value)
+	}
+	if err := node.Encode(raw); err != nil {
+		return nil, err
+	}
+	if d.format == FormatJSON {
+		plainDates(&node)
+	}
+	return &node, nil
+}
+
+// plainDates turns JSON strings holding an RFC 3339 time or a 2006-01-02 date into plain
+// YAML scalars, which decode as time.Time into time fields and maps, and as text into strings
+func plainDates(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		for _, layout := range []string{time.RFC3339, time.DateOnly} {
+			if _, err := time.Parse(layout, node.Value); err == nil {
+				node.Tag = ""
+				node.Style = 0
+				return
+			}
+		}
+	}
+	for _, child := range node.Content {
+		plainDates(child)
+	}
+}
+
+// normalizeValue converts TOML and JSON values to the types YAML decodes to
+func normalizeValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeValue(item)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeValue(item)
+		}
+		return v
+	case []map[string]any: // TOML arrays of tables
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = normalizeValue(item)
+		}
+		return items
+	case int64:
+		return int(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil && i >= math.MinInt && i <= math.MaxInt {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	case time.Time:
+		// TOML local dates and times have no zone, read them as UTC like YAML does
+		switch v.Location().String() {
+		case "datetime-local", "date-local", "time-local":
+			return time.Date(v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond(), time.UTC)
+		}
+		return v
+	default:
+		return value
+	}
+}
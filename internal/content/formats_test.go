@@ -0,0 +1,143 @@
+package content
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkdownParser_FrontmatterFormats(t *testing.T) {
+	yamlSource := "---\ntitle: Hello\ndate: 2024-01-15\ntags: [go, web]\ntoc_depth: 2\nsubtitle: More\nrank: 3\nratio: 1.5\n---\n\n# Body\n"
+	tomlSource := "+++\ntitle = \"Hello\"\ndate = 2024-01-15\ntags = [\"go\", \"web\"]\ntoc_depth = 2\nsubtitle = \"More\"\nrank = 3\nratio = 1.5\n+++\n\n# Body\n"
+	jsonSource := "{\n  \"title\": \"Hello\",\n  \"date\": \"2024-01-15\",\n  \"tags\": [\"go\", \"web\"],\n  \"toc_depth\": 2,\n  \"subtitle\": \"More\",\n  \"rank\": 3,\n  \"ratio\": 1.5\n}\n\n# Body\n"
+
+	want, err := NewMarkdownParser().Parse([]byte(yamlSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !want.Frontmatter.Date.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)) || want.Frontmatter.TOCDepth != 2 {
+		t.Fatalf("Unexpected YAML frontmatter %+v", want.Frontmatter)
+	}
+
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"TOML", tomlSource},
+		{"JSON", jsonSource},
+		{"CRLF line endings", strings.ReplaceAll(yamlSource, "\n", "\r\n")},
+		{"Byte order mark", "\xef\xbb\xbf" + tomlSource},
+		{"Trailing spaces after delimiters", strings.ReplaceAll(yamlSource, "---\n", "--- \n")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := NewMarkdownParser().Parse([]byte(tt.source))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(parsed.Frontmatter, want.Frontmatter) {
+				t.Errorf("Expected frontmatter %+v, got %+v", want.Frontmatter, parsed.Frontmatter)
+			}
+			if !reflect.DeepEqual(parsed.Params, want.Params) {
+				t.Errorf("Expected params %#v, got %#v", want.Params, parsed.Params)
+			}
+			if parsed.Body != "# Body" {
+				t.Errorf("Expected the body without frontmatter, got %q", parsed.Body)
+			}
+			if strings.Contains(parsed.HTML, "Hello") || !strings.Contains(parsed.HTML, ">Body</h1>") {
+				t.Errorf("Expected only the body rendered, got %s", parsed.HTML)
+			}
+		})
+	}
+}
+
+func TestMarkdownParser_FrontmatterEdgeCases(t *testing.T) {
+	t.Run("Keeps line numbers after frontmatter", func(t *testing.T) {
+		parsed, err := NewMarkdownParser(WithLinkResolver(testLinks{})).Parse([]byte("+++\ntitle = \"Test\"\n+++\n\nFirst line\n[[missing]]\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(parsed.Warnings) != 1 || parsed.Warnings[0].Line != 6 {
+			t.Errorf("Expected a warning on line 6, got %v", parsed.Warnings)
+		}
+	})
+
+	t.Run("Leaves shortcodes opening a file alone", func(t *testing.T) {
+		parsed, err := NewMarkdownParser().Parse([]byte("{{< youtube abc >}}\n\nText"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed.Frontmatter.Title != "" || !strings.HasPrefix(parsed.Body, "{{<") {
+			t.Errorf("Expected no frontmatter, got %+v", parsed.Frontmatter)
+		}
+	})
+
+	t.Run("Keeps JSON dates as text in string fields", func(t *testing.T) {
+		parsed, err := NewMarkdownParser().Parse([]byte(`{"title": "2024-01-15", "date": "2024-01-15T10:00:00Z", "launch": "2024-05-01"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed.Frontmatter.Title != "2024-01-15" || parsed.Frontmatter.Date.Hour() != 10 {
+			t.Errorf("Unexpected frontmatter %+v", parsed.Frontmatter)
+		}
+		if _, ok := parsed.Params["launch"].(time.Time); !ok {
+			t.Errorf("Expected launch to be a date, got %#v", parsed.Params["launch"])
+		}
+	})
+
+	t.Run("Treats unclosed frontmatter as body", func(t *testing.T) {
+		parsed, err := NewMarkdownParser().Parse([]byte("+++\ntitle = \"Test\"\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed.Frontmatter.Title != "" {
+			t.Errorf("Expected no frontmatter, got %+v", parsed.Frontmatter)
+		}
+	})
+
+	t.Run("Reports malformed frontmatter", func(t *testing.T) {
+		if _, err := NewMarkdownParser().Parse([]byte("+++\ntitle = \n+++\n")); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+
+	t.Run("Treats a leading brace that doesn't open a JSON object as body", func(t *testing.T) {
+		for _, source := range []string{
+			"{\"title\": \"Test\",}\n",
+			"{\"title\": \"Test\"} trailing\n",
+			"{.callout} A paragraph with attributes\n",
+			"{\n\nNot JSON at all\n",
+		} {
+			parsed, err := NewMarkdownParser().Parse([]byte(source))
+			if err != nil {
+				t.Fatalf("Expected %q to parse, got %v", source, err)
+			}
+			if parsed.Frontmatter.Title != "" || parsed.Body != source {
+				t.Errorf("Expected %q to be all body, got frontmatter %+v and body %q", source, parsed.Frontmatter, parsed.Body)
+			}
+		}
+	})
+}
+
+func TestMarkdownParser_ParseFrontmatterOnly(t *testing.T) {
+	for _, source := range []string{
+		"---\r\ntitle: Test\r\ndraft: true\r\n---\r\nBody",
+		"+++\ntitle = \"Test\"\ndraft = true\n+++\nBody",
+		"{\"title\": \"Test\", \"draft\": true}\nBody",
+	} {
+		fm, err := NewMarkdownParser().ParseFrontmatterOnly([]byte(source))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fm.Title != "Test" || !fm.Draft {
+			t.Errorf("Expected the frontmatter of %q, got %+v", source, fm)
+		}
+	}
+}
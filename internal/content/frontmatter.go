@@ -5,22 +5,110 @@ import (
 	"time"
 )
 
+// Publication statuses returned by Content.Status
+const (
+	StatusPublished = "published"
+	StatusDraft     = "draft"
+	StatusScheduled = "scheduled"
+	StatusExpired   = "expired"
+)
+
 // Frontmatter represents the YAML frontmatter structure for content files
 type Frontmatter struct {
 	Title       string    `yaml:"title"`
-	Date        time.Time `yaml:"date"`
+	Date        time.Time `yaml:"date"`    // Publish time, content dated in the future is scheduled
+	Expires     time.Time `yaml:"expires"` // Optional time after which the content is unpublished
 	Tags        []string  `yaml:"tags"`
 	Description string    `yaml:"description"`
+	Excerpt     string    `yaml:"excerpt"` // Overrides the excerpt generated from the body
 	Cover       string    `yaml:"cover"`
 	Draft       bool      `yaml:"draft"`
 	Slug        string    `yaml:"slug"`
+	Aliases     []string  `yaml:"aliases"` // Old URLs that redirect here, relative to the section unless they start with /
+	Author      string    `yaml:"author"`
+	Authors     []string  `yaml:"authors"`
+	TOC         *bool     `yaml:"toc"`       // Set to false to skip the table of contents
+	TOCDepth    int       `yaml:"toc_depth"` // Deepest heading level in the table of contents
+	Weight      int       `yaml:"weight"`    // Orders pages among their siblings, lowest first, then by title
+	Lang        string    `yaml:"lang"`      // Language the content is written in, see Languages
+}
+
+// TOCEnabled reports whether a table of contents should be built, which it is unless disabled
+func (f *Frontmatter) TOCEnabled() bool {
+	return f.TOC == nil || *f.TOC
+}
+
+// TOCMaxDepth returns the deepest heading level to include in the table of contents
+func (f *Frontmatter) TOCMaxDepth() int {
+	if f.TOCDepth > 0 {
+		return f.TOCDepth
+	}
+	return DefaultTOCDepth
+}
+
+// AuthorSlugs returns the author slugs from both the author and authors keys, without duplicates
+func (f *Frontmatter) AuthorSlugs() []string {
+	var slugs []string
+	seen := make(map[string]bool)
+
+	for _, slug := range append([]string{f.Author}, f.Authors...) {
+		slug = strings.TrimSpace(slug)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		slugs = append(slugs, slug)
+	}
+
+	return slugs
 }
 
 // Content represents a parsed content file with frontmatter and body
 type Content struct {
 	Frontmatter Frontmatter
+	Params      map[string]any // Frontmatter fields without a Frontmatter field, such as a theme's subtitle
 	Body        string
 	HTML        string
+	Path        string    // Source file the content was loaded from
+	ModTime     time.Time // Modification time of the source file
+
+	WordCount   int    // Number of words in the rendered body
+	ReadingTime int    // Estimated reading time in minutes
+	Excerpt     string // Plain text summary, see MarkdownParser.summarize
+
+	// TOC is the nested outline of the content's headings
+	TOC []*TOCEntry
+
+	// Links holds the paths of the internal links in the content, such as /blog/hello, in order
+	Links []string
+
+	// Backlinks holds the published posts and pages linking to this content, filled in by the Store
+	Backlinks []*GraphNode
+
+	// Warnings lists problems found while parsing, such as unknown shortcodes
+	Warnings []Warning
+
+	// hasWikiLinks is set when the content has wiki-links, which the Store re-resolves
+	// whenever posts or pages are added, removed or change slug
+	hasWikiLinks bool
+
+	// Authors holds the resolved author profiles, filled in by the Store
+	Authors []*Author
+
+	// Related holds the published posts most related to this one, filled in by the Store
+	Related []*Content
+
+	// IsSection is set for a section's landing page, loaded from an _index.md
+	IsSection bool
+
+	// Parent, Children and Siblings place a page in the section hierarchy, filled in by the
+	// Store. Children and Siblings are published pages ordered by weight, then title, and
+	// PrevSibling and NextSibling are this page's neighbours among them.
+	Parent      *Content
+	Children    []*Content
+	Siblings    []*Content
+	PrevSibling *Content
+	NextSibling *Content
 }
 
 // GetSlug returns the slug from frontmatter or generates one from title
@@ -40,6 +128,36 @@ func (c *Content) GetDate() time.Time {
 	}
 	return time.Now()
 }
+
+// IsScheduled reports whether the content is dated after now and so not published yet
+func (c *Content) IsScheduled(now time.Time) bool {
+	return !c.Frontmatter.Date.IsZero() && c.Frontmatter.Date.After(now)
+}
+
+// IsExpired reports whether the content's expiry time has passed
+func (c *Content) IsExpired(now time.Time) bool {
+	return !c.Frontmatter.Expires.IsZero() && !now.Before(c.Frontmatter.Expires)
+}
+
+// IsPublished reports whether the content is publicly visible at the given time
+func (c *Content) IsPublished(now time.Time) bool {
+	return !c.Frontmatter.Draft && !c.IsScheduled(now) && !c.IsExpired(now)
+}
+
+// Status returns whether the content is a draft, scheduled, expired or published at the given time
+func (c *Content) Status(now time.Time) string {
+	switch {
+	case c.Frontmatter.Draft:
+		return StatusDraft
+	case c.IsScheduled(now):
+		return StatusScheduled
+	case c.IsExpired(now):
+		return StatusExpired
+	default:
+		return StatusPublished
+	}
+}
+
 func generateSlug(title string) string {
 	slug := strings.ToLower(title)
 	slug = strings.ReplaceAll(slug, " ", "-")
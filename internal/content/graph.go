@@ -0,0 +1,130 @@
+package content
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// GraphNode is a published post or page in the link graph
+type GraphNode struct {
+	Content *Content
+	Kind    Kind
+	URL     string
+}
+
+// IsPost reports whether the node is a blog post rather than a page
+func (n *GraphNode) IsPost() bool {
+	return n.Kind == KindPost
+}
+
+// GraphEdge is a link from one published post or page to another, by URL
+type GraphEdge struct {
+	Source string
+	Target string
+}
+
+// Graph holds how published posts and pages link to each other
+type Graph struct {
+	Nodes []*GraphNode // posts newest first, then pages newest first
+	Edges []GraphEdge
+}
+
+// internalLinksKey is the parser context key under which a document's internal links are collected
+var internalLinksKey = parser.NewContextKey()
+
+// linkCollector records the internal links of a document, from markdown links
+// as well as resolved wiki-links
+type linkCollector struct{}
+
+func (c *linkCollector) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var links []string
+	seen := make(map[string]bool)
+
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		var destination string
+		switch n := node.(type) {
+		case *ast.Link:
+			destination = string(n.Destination)
+		case *wikiLinkNode:
+			if n.resolved && !n.image {
+				destination = n.url
+			}
+		}
+
+		if link, ok := internalLinkPath(destination); ok && !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	pc.Set(internalLinksKey, links)
+}
+
+// internalLinkPath returns the cleaned path of a link to somewhere on this site, leaving out
+// external links, in-page anchors and relative links
+func internalLinkPath(destination string) (string, bool) {
+	u, err := url.Parse(destination)
+	if err != nil || u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		return "", false
+	}
+	return path.Clean(u.Path), true
+}
+
+// buildGraph links published posts and pages through their internal links and fills in the
+// backlinks of every post and page in posts and pages, drafts included so previews show them.
+// Only published content counts as a source of backlinks.
+func buildGraph(urlPrefix string, posts, pages map[string]*Content, postList, pageList []*Content) *Graph {
+	byURL := make(map[string]*GraphNode, len(posts)+len(pages))
+	for slug, post := range posts {
+		url := urlPrefix + "/blog/" + slug
+		byURL[url] = &GraphNode{Content: post, Kind: KindPost, URL: url}
+	}
+	for slug, page := range pages {
+		url := urlPrefix + "/" + slug
+		byURL[url] = &GraphNode{Content: page, Kind: KindPage, URL: url}
+	}
+
+	graph := &Graph{Nodes: make([]*GraphNode, 0, len(postList)+len(pageList))}
+	published := make(map[*Content]bool)
+	for _, post := range postList {
+		graph.Nodes = append(graph.Nodes, byURL[urlPrefix+"/blog/"+post.Frontmatter.Slug])
+		published[post] = true
+	}
+	for _, page := range pageList {
+		graph.Nodes = append(graph.Nodes, byURL[urlPrefix+"/"+page.Frontmatter.Slug])
+		published[page] = true
+	}
+
+	for _, source := range graph.Nodes {
+		for _, link := range source.Content.Links {
+			target, ok := byURL[link]
+			if !ok || target == source {
+				continue
+			}
+
+			target.Content.Backlinks = append(target.Content.Backlinks, source)
+			if published[target.Content] {
+				graph.Edges = append(graph.Edges, GraphEdge{Source: source.URL, Target: target.URL})
+			}
+		}
+	}
+
+	return graph
+}
+
+// Graph returns the links between published posts and pages
+func (s *Store) Graph() *Graph {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.graph
+}
@@ -0,0 +1,84 @@
+package content
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func backlinkURLs(content *Content) []string {
+	var urls []string
+	for _, node := range content.Backlinks {
+		urls = append(urls, node.URL)
+	}
+	return urls
+}
+
+func TestMarkdownParser_Links(t *testing.T) {
+	parser := NewMarkdownParser(WithHeadingAnchors(true), WithLinkResolver(testLinks{"hello": true}))
+
+	parsed, err := parser.Parse([]byte("## Intro\n\n[About](/about/) [again](/about#team) [ext](https://example.com/blog/x) [rel](other) [top](#intro) [[hello]] ![[cat.png]] [[missing]]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"/about", "/blog/hello"}
+	if !slices.Equal(parsed.Links, want) {
+		t.Errorf("Expected internal links %v, got %v", want, parsed.Links)
+	}
+}
+
+func TestStore_Backlinks(t *testing.T) {
+	store, dataDir := newTestStore(t)
+
+	writeContentFile(t, filepath.Join(dataDir, "blog", "first.md"), "---\ntitle: First\ndate: 2024-01-15T10:00:00Z\n---\n\nSee [about](/about) and [[second]]")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "second.md"), "---\ntitle: Second\ndate: 2024-02-15T10:00:00Z\n---\n\nBack to [first](/blog/first)")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "draft.md"), "---\ntitle: Draft\ndate: 2024-03-15T10:00:00Z\ndraft: true\n---\n\nSee [about](/about)")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "about.md"), "---\ntitle: About\n---\n\nAbout, see [[first]]")
+
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	about, _ := store.Page("about")
+	if got := backlinkURLs(about); !slices.Equal(got, []string{"/blog/first"}) {
+		t.Errorf("Expected only the published post as a backlink of about, got %v", got)
+	}
+	first, _ := store.Post("first")
+	if got := backlinkURLs(first); !slices.Equal(got, []string{"/blog/second", "/about"}) {
+		t.Errorf("Expected posts then pages as backlinks of first, got %v", got)
+	}
+	draft, _ := store.PreviewPost("draft")
+	if len(draft.Backlinks) != 0 {
+		t.Errorf("Expected no backlinks for the draft, got %v", backlinkURLs(draft))
+	}
+
+	graph := store.Graph()
+	if len(graph.Nodes) != 3 {
+		t.Errorf("Expected the published posts and pages as nodes, got %d", len(graph.Nodes))
+	}
+	wantEdges := []GraphEdge{
+		{"/blog/second", "/blog/first"},
+		{"/blog/first", "/about"},
+		{"/blog/first", "/blog/second"},
+		{"/about", "/blog/first"},
+	}
+	if !slices.Equal(graph.Edges, wantEdges) {
+		t.Errorf("Expected edges %v, got %v", wantEdges, graph.Edges)
+	}
+
+	t.Run("Follows file changes", func(t *testing.T) {
+		path := filepath.Join(dataDir, "blog", "second.md")
+		writeContentFile(t, path, "---\ntitle: Second\ndate: 2024-02-15T10:00:00Z\n---\n\nNo links any more, apart from [about](/about)")
+		store.HandleFileChange(path)
+
+		first, _ := store.Post("first")
+		if got := backlinkURLs(first); !slices.Equal(got, []string{"/about"}) {
+			t.Errorf("Expected the removed link to drop out, got %v", got)
+		}
+		about, _ := store.Page("about")
+		if got := backlinkURLs(about); !slices.Equal(got, []string{"/blog/second", "/blog/first"}) {
+			t.Errorf("Expected the new link to show up, got %v", got)
+		}
+	})
+}
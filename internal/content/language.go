@@ -0,0 +1,146 @@
+package content
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Languages lists the languages content is written in, the default first. Content in the
+// default language is served from the site's root and content in any other under /{lang}.
+type Languages []string
+
+// ParseLanguages returns the default language followed by the others in a comma-separated
+// list, normalised and without duplicates
+func ParseLanguages(defaultLang, others string) Languages {
+	langs := Languages{NormalizeLanguage(defaultLang)}
+	for _, lang := range strings.Split(others, ",") {
+		lang = NormalizeLanguage(lang)
+		if lang != "" && !slices.Contains(langs, lang) {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
+
+// NormalizeLanguage lowercases a language code and writes its subtags with hyphens, so
+// en_US and en-US are both en-us
+func NormalizeLanguage(lang string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(lang)), "_", "-")
+}
+
+// Default returns the default language, empty if none are configured
+func (l Languages) Default() string {
+	if len(l) == 0 {
+		return ""
+	}
+	return l[0]
+}
+
+// Contains reports whether lang is one of the languages
+func (l Languages) Contains(lang string) bool {
+	return slices.Contains(l, NormalizeLanguage(lang))
+}
+
+// match returns the language lang names: the same one, or else the first whose base
+// language is the same, so en matches en-us and en-gb matches en. It returns false if
+// none match.
+func (l Languages) match(lang string) (string, bool) {
+	lang = NormalizeLanguage(lang)
+	if lang == "" {
+		return "", false
+	}
+	if slices.Contains(l, lang) {
+		return lang, true
+	}
+
+	base, _, _ := strings.Cut(lang, "-")
+	for _, candidate := range l {
+		if candidateBase, _, _ := strings.Cut(candidate, "-"); candidateBase == base {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// URLPrefix returns the path content in lang is served under, such as /fr, which is empty
+// for the default language
+func (l Languages) URLPrefix(lang string) string {
+	lang = NormalizeLanguage(lang)
+	if lang == "" || lang == l.Default() {
+		return ""
+	}
+	return "/" + lang
+}
+
+// split separates a language suffix from a file name without its extension, so post.fr is
+// post in fr. Only suffixes matching one of the languages count, so v1.2 stays as it is.
+func (l Languages) split(stem string) (name, lang string) {
+	i := strings.LastIndex(stem, ".")
+	if i <= 0 {
+		return stem, ""
+	}
+	lang, ok := l.match(stem[i+1:])
+	if !ok {
+		return stem, ""
+	}
+	return stem[:i], lang
+}
+
+// setLanguage works out the language of content loaded from a file with the given name
+// (without extension) from its lang field, or else its file name, or else the default, and
+// drops the language suffix from a slug taken from the file name. A lang field matching none
+// of the languages is warned about and left for the file name or default to decide. Without
+// any languages configured, content keeps whatever its lang field says.
+func (l Languages) setLanguage(content *Content, stem string) {
+	if len(l) == 0 {
+		return
+	}
+
+	name, lang := l.split(stem)
+	if content.Frontmatter.Slug == stem {
+		content.Frontmatter.Slug = name
+	}
+
+	if content.Frontmatter.Lang != "" {
+		if matched, ok := l.match(content.Frontmatter.Lang); ok {
+			lang = matched
+		} else {
+			content.Warnings = append(content.Warnings, Warning{
+				Message: fmt.Sprintf("unknown language %q, expected one of %s", content.Frontmatter.Lang, strings.Join(l, ", ")),
+			})
+		}
+	}
+	if lang == "" {
+		lang = l.Default()
+	}
+
+	content.Frontmatter.Lang = lang
+}
+
+// WithLanguage limits a store to the content in lang, one of languages, and serves its URLs
+// under the language's prefix. Content files name their language with a lang field or a
+// suffix such as post.fr.md, and are in the default language otherwise.
+func WithLanguage(languages Languages, lang string) StoreOption {
+	return func(s *Store) {
+		s.languages = languages
+		s.lang = NormalizeLanguage(lang)
+		s.urlPrefix = languages.URLPrefix(lang)
+	}
+}
+
+// Language returns the language of the content in the store, empty if it isn't limited to one
+func (s *Store) Language() string {
+	return s.lang
+}
+
+// URLPrefix returns the path the store's content is served under, empty for the default
+// language
+func (s *Store) URLPrefix() string {
+	return s.urlPrefix
+}
+
+// inLanguage reports whether loaded content belongs in the store
+func (s *Store) inLanguage(content *Content) bool {
+	return s.lang == "" || content.Frontmatter.Lang == s.lang
+}
@@ -0,0 +1,153 @@
+package content
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseLanguages(t *testing.T) {
+	langs := ParseLanguages("EN", "fr, pt_BR,en,,fr")
+	if want := (Languages{"en", "fr", "pt-br"}); !slices.Equal(langs, want) {
+		t.Fatalf("Expected %v, got %v", want, langs)
+	}
+	if got := langs.URLPrefix("en"); got != "" {
+		t.Errorf("Expected no prefix for the default language, got %q", got)
+	}
+	if got := langs.URLPrefix("pt-BR"); got != "/pt-br" {
+		t.Errorf("Expected /pt-br, got %q", got)
+	}
+
+	tests := []struct {
+		stem, name, lang string
+	}{
+		{"post", "post", ""},
+		{"post.fr", "post", "fr"},
+		{"post.pt-br", "post", "pt-br"},
+		{"release-v1.2", "release-v1.2", ""},
+		{"notes.de", "notes.de", ""},
+		{".fr", ".fr", ""},
+		{"post.fr-ca", "post", "fr"},
+		{"post.pt", "post", "pt-br"},
+	}
+	for _, tt := range tests {
+		name, lang := langs.split(tt.stem)
+		if name != tt.name || lang != tt.lang {
+			t.Errorf("split(%q) = %q, %q, want %q, %q", tt.stem, name, lang, tt.name, tt.lang)
+		}
+	}
+}
+
+func postTitles(posts []*Content) []string {
+	var titles []string
+	for _, post := range posts {
+		titles = append(titles, post.Frontmatter.Title)
+	}
+	return titles
+}
+
+func TestStore_Languages(t *testing.T) {
+	langs := ParseLanguages("en", "fr")
+	en, dataDir := newTestStore(t, WithLanguage(langs, "en"))
+	fr := NewStore(NewLoader(), dataDir, en.logger, WithLanguage(langs, "fr"))
+
+	writeContentFile(t, filepath.Join(dataDir, "blog", "hello.md"), "---\ntitle: Hello\ndate: 2024-01-15T10:00:00Z\n---\n\nSee [[about]]")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "hello.fr.md"), "---\ntitle: Bonjour\ndate: 2024-01-15T10:00:00Z\n---\n\nVoir [[about]]")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "salut.md"), "---\ntitle: Salut\nlang: FR\ndate: 2024-01-16T10:00:00Z\naliases: [coucou]\n---\n\nSalut")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "about.md"), "---\ntitle: About\n---\n\nAbout")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "about.fr.md"), "---\ntitle: À propos\n---\n\nÀ propos")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "docs", "_index.fr.md"), "---\ntitle: Docs\n---\n\nDocs")
+
+	for _, store := range []*Store{en, fr} {
+		if err := store.Load(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := postTitles(en.Posts()); !slices.Equal(got, []string{"Hello"}) {
+		t.Errorf("Expected only English posts, got %v", got)
+	}
+	if got := postTitles(fr.Posts()); !slices.Equal(got, []string{"Salut", "Bonjour"}) {
+		t.Errorf("Expected only French posts, got %v", got)
+	}
+
+	post, found := fr.Post("hello")
+	if !found || post.Frontmatter.Lang != "fr" {
+		t.Fatalf("Expected hello.fr.md at slug hello in fr, got %+v", post)
+	}
+	if got, want := post.Links, []string{"/fr/about"}; !slices.Equal(got, want) {
+		t.Errorf("Expected wiki-links within the language, got %v", got)
+	}
+	if got, want := en.Posts()[0].Links, []string{"/about"}; !slices.Equal(got, want) {
+		t.Errorf("Expected default-language links at the root, got %v", got)
+	}
+
+	if docs, found := fr.Page("docs"); !found || !docs.IsSection {
+		t.Errorf("Expected _index.fr.md to be the French docs section, got %+v", docs)
+	}
+	if _, found := en.Page("docs"); found {
+		t.Error("Expected no English docs section")
+	}
+
+	if got, found := fr.Alias("/fr/blog/coucou"); !found || got != "/fr/blog/salut" {
+		t.Errorf("Expected relative aliases under the language prefix, got %q", got)
+	}
+	if results := fr.Search("propos", 10); len(results) != 1 || results[0].URL != "/fr/about" {
+		t.Errorf("Expected search results under the language prefix, got %+v", results)
+	}
+}
+
+func TestStore_LanguagesUnknown(t *testing.T) {
+	store, dataDir := newTestStore(t, WithLanguage(ParseLanguages("en", "fr"), "en"))
+	writeContentFile(t, filepath.Join(dataDir, "blog", "hallo.md"), "---\ntitle: Hallo\nlang: de\ndate: 2024-01-15T10:00:00Z\n---\n\nHallo")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "hello.md"), "---\ntitle: Hello\ndate: 2024-01-16T10:00:00Z\n---\n\nHello")
+
+	if err := store.Load(); err != nil {
+		t.Fatalf("Expected an unknown language not to fail the load, got %v", err)
+	}
+
+	post, found := store.Post("hallo")
+	if !found || post.Frontmatter.Lang != "en" {
+		t.Fatalf("Expected the post in the default language, got %+v", post)
+	}
+	if len(post.Warnings) != 1 || post.Warnings[0].Message != `unknown language "de", expected one of en, fr` {
+		t.Errorf("Expected an unknown language warning, got %v", post.Warnings)
+	}
+	if got := postTitles(store.Posts()); !slices.Equal(got, []string{"Hello", "Hallo"}) {
+		t.Errorf("Expected both posts, got %v", got)
+	}
+}
+
+func TestStore_LanguagesBaseMatch(t *testing.T) {
+	store, dataDir := newTestStore(t, WithLanguage(ParseLanguages("en-US", ""), "en-us"))
+	writeContentFile(t, filepath.Join(dataDir, "blog", "hello.md"), "---\ntitle: Hello\nlang: en\ndate: 2024-01-15T10:00:00Z\n---\n\nHello")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "colour.en-gb.md"), "---\ntitle: Colour\ndate: 2024-01-16T10:00:00Z\n---\n\nColour")
+
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, slug := range []string{"hello", "colour"} {
+		post, found := store.Post(slug)
+		if !found || post.Frontmatter.Lang != "en-us" || len(post.Warnings) != 0 {
+			t.Errorf("Expected %s in en-us without warnings, got %+v", slug, post)
+		}
+	}
+}
+
+func TestStore_LanguagesFileChange(t *testing.T) {
+	langs := ParseLanguages("en", "fr")
+	store, dataDir := newTestStore(t, WithLanguage(langs, "en"))
+	path := filepath.Join(dataDir, "blog", "hello.md")
+	writeContentFile(t, path, "---\ntitle: Hello\n---\n\nHello")
+
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	writeContentFile(t, path, "---\ntitle: Bonjour\nlang: fr\n---\n\nBonjour")
+	store.HandleFileChange(path)
+	if _, found := store.Post("hello"); found {
+		t.Error("Expected a post switched to French to leave the English store")
+	}
+}
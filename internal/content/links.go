@@ -0,0 +1,141 @@
+package content
+
+import (
+	"io/fs"
+	"maps"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// linkTargets resolves wiki-links against the slugs of the loaded posts and pages,
+// drafts included, and the files in data/attachments
+type linkTargets struct {
+	posts           map[string]bool
+	pages           map[string]bool
+	attachments     map[string]bool   // attachment paths relative to data/attachments, with slashes
+	attachmentNames map[string]string // the first attachment path with each file name
+	urlPrefix       string            // path the content is served under, such as /fr
+	pageNames       map[string]string // the slug of the page nearest the top ending in each last segment
+}
+
+// newLinkTargets collects the slugs of the loaded content files and indexes the attachments
+func newLinkTargets(files map[string]*Content, kinds map[string]Kind, dataDir, urlPrefix string) *linkTargets {
+	lt := &linkTargets{
+		urlPrefix: urlPrefix,
+		posts:     make(map[string]bool),
+		pages:     make(map[string]bool),
+	}
+	lt.attachments, lt.attachmentNames = indexAttachments(filepath.Join(dataDir, "attachments"))
+
+	for path, content := range files {
+		switch kinds[path] {
+		case KindPost:
+			lt.posts[content.Frontmatter.Slug] = true
+		case KindPage:
+			lt.pages[content.Frontmatter.Slug] = true
+		}
+	}
+
+	// Shorter slugs win, then the first in order, so links by name don't depend on map order
+	lt.pageNames = make(map[string]string, len(lt.pages))
+	for slug := range lt.pages {
+		name := path.Base(slug)
+		existing, found := lt.pageNames[name]
+		if !found || len(slug) < len(existing) || (len(slug) == len(existing) && slug < existing) {
+			lt.pageNames[name] = slug
+		}
+	}
+
+	return lt
+}
+
+// ResolveLink accepts a slug, a file name with or without .md, a title like "Hello World"
+// or any of those prefixed by blog/ or pages/. Without a prefix posts win over pages. Pages in
+// sections can be linked by their full slug, such as docs/install, or by their last segment,
+// where the page nearest the top wins.
+func (lt *linkTargets) ResolveLink(target string) (string, bool) {
+	target = strings.TrimSuffix(strings.TrimSpace(target), ".md")
+
+	section, rest, _ := strings.Cut(target, "/")
+	if section != string(KindPost) && section != string(KindPage) {
+		section, rest = "", target
+	}
+	name := path.Base(rest)
+
+	if section != string(KindPost) && strings.Contains(rest, "/") {
+		slug := strings.TrimSuffix(rest, "/"+SectionIndex)
+		if lt.pages[slug] {
+			return lt.urlPrefix + "/" + slug, true
+		}
+	}
+
+	for _, slug := range []string{name, generateSlug(name)} {
+		if section != string(KindPage) && lt.posts[slug] {
+			return lt.urlPrefix + "/blog/" + slug, true
+		}
+		if section != string(KindPost) {
+			if page, found := lt.pageNames[slug]; found {
+				return lt.urlPrefix + "/" + page, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// ResolveAttachment accepts a path relative to data/attachments or, as Obsidian links
+// attachments by name wherever they are, just the file name
+func (lt *linkTargets) ResolveAttachment(name string) (string, bool) {
+	// Cleaning from the root keeps the name inside the attachments directory
+	rel := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+	if rel == "" {
+		return "", false
+	}
+
+	if lt.attachments[rel] {
+		return attachmentURL(rel), true
+	}
+	if found, ok := lt.attachmentNames[path.Base(rel)]; ok {
+		return attachmentURL(found), true
+	}
+	return "", false
+}
+
+// indexAttachments lists the files in dir by path relative to it and by file name,
+// where the first in walk order wins
+func indexAttachments(dir string) (map[string]bool, map[string]string) {
+	paths := make(map[string]bool)
+	names := make(map[string]string)
+	_ = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		paths[rel] = true
+		if _, found := names[d.Name()]; !found {
+			names[d.Name()] = rel
+		}
+		return nil
+	})
+	return paths, names
+}
+
+// equal reports whether both resolve the same posts, pages and attachments
+func (lt *linkTargets) equal(other *linkTargets) bool {
+	if lt == nil || other == nil {
+		return lt == other
+	}
+	return lt.urlPrefix == other.urlPrefix && maps.Equal(lt.posts, other.posts) && maps.Equal(lt.pages, other.pages) &&
+		maps.Equal(lt.attachments, other.attachments)
+}
+
+// attachmentURL returns the URL an attachment path relative to data/attachments is served at
+func attachmentURL(rel string) string {
+	return (&url.URL{Path: "/images/" + rel}).EscapedPath()
+}
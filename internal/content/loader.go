@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 )
 
 // Loader handles loading and parsing content files
@@ -15,14 +16,21 @@ type Loader struct {
 }
 
 // NewLoader creates a new content loader
-func NewLoader() *Loader {
+func NewLoader(opts ...ParserOption) *Loader {
 	return &Loader{
-		parser: NewMarkdownParser(),
+		parser: NewMarkdownParser(opts...),
 	}
 }
 
-// LoadContent loads and parses a single content file
+// LoadContent loads and parses a single content file. Not knowing the data directory, it
+// applies no directory sanitizer rules; a Store loading the file does.
 func (l *Loader) LoadContent(filePath string) (*Content, os.FileInfo, error) {
+	return l.loadContent(filePath, "", l.parser.links)
+}
+
+// loadContent loads and parses a single content file, found at dataPath within the data
+// directory (empty if unknown), resolving wiki-links with links
+func (l *Loader) loadContent(filePath, dataPath string, links LinkResolver) (*Content, os.FileInfo, error) {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
@@ -33,7 +41,7 @@ func (l *Loader) LoadContent(filePath string) (*Content, os.FileInfo, error) {
 		return nil, nil, fmt.Errorf("failed to stat file %s: %w", filePath, err)
 	}
 
-	parsed, err := l.parser.Parse(content)
+	parsed, err := l.parser.parse(content, links, dataPath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse content from %s: %w", filePath, err)
 	}
@@ -45,6 +53,9 @@ func (l *Loader) LoadContent(filePath string) (*Content, os.FileInfo, error) {
 		parsed.Frontmatter.Slug = strings.TrimSuffix(base, ext)
 	}
 
+	parsed.Path = filePath
+	parsed.ModTime = fi.ModTime()
+
 	return parsed, fi, nil
 }
 
@@ -64,13 +75,12 @@ func (l *Loader) LoadContentFromDir(dirPath string) ([]*Content, []os.FileInfo,
 		}
 
 		content, meta, err := l.LoadContent(path)
-		fmt.Printf("meta: %+v\n", meta)
 		if err != nil {
 			return fmt.Errorf("failed to load content from %s: %w", path, err)
 		}
 
-		// Skip draft content
-		if content.Frontmatter.Draft {
+		// Skip drafts, scheduled and expired content
+		if !content.IsPublished(time.Now()) {
 			return nil
 		}
 
@@ -100,10 +110,19 @@ func (l *Loader) LoadBlogPosts(contentDir string) ([]*Content, []os.FileInfo, er
 // LoadPages loads all pages from the content directory
 func (l *Loader) LoadPages(contentDir string) ([]*Content, []os.FileInfo, error) {
 	pagesDir := filepath.Join(contentDir, "pages")
-	return l.LoadContentFromDir(pagesDir)
+	pages, metas, err := l.LoadContentFromDir(pagesDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, page := range pages {
+		nestPageSlug(page, pagesDir, nil)
+	}
+	return pages, metas, nil
 }
 
-// LoadPage loads a single page by slug
+// LoadPage loads a single page by slug, which for pages in subdirectories of the pages
+// directory includes the directory, such as docs/install
 func (l *Loader) LoadPage(contentDir, slug string) (*Content, os.FileInfo, error) {
 	pagesDir := filepath.Join(contentDir, "pages")
 
@@ -119,13 +138,9 @@ func (l *Loader) LoadPage(contentDir, slug string) (*Content, os.FileInfo, error
 		}
 
 		// Check if the file matches the slug
-		base := filepath.Base(path)
-		ext := filepath.Ext(base)
-		fileSlug := strings.TrimSuffix(base, ext)
-
-		if fileSlug == slug {
+		if pageSlugForFile(pagesDir, path) == slug {
 			foundPath = path
-			return filepath.SkipDir // Stop walking
+			return filepath.SkipAll // Stop walking
 		}
 
 		return nil
@@ -139,7 +154,12 @@ func (l *Loader) LoadPage(contentDir, slug string) (*Content, os.FileInfo, error
 		return nil, nil, fmt.Errorf("page not found: %s", slug)
 	}
 
-	return l.LoadContent(foundPath)
+	page, fi, err := l.LoadContent(foundPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	nestPageSlug(page, pagesDir, nil)
+	return page, fi, nil
 }
 
 // LoadBlogPost loads a single blog post by slug
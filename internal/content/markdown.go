@@ -3,147 +3,342 @@ package content
 import (
 	"bytes"
 	"fmt"
-	"regexp"
-	"strings"
+	"io"
 
 	chroma "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/yuin/goldmark"
 	highlighting "github.com/yuin/goldmark-highlighting/v2"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
 	goldmarkHTML "github.com/yuin/goldmark/renderer/html"
-	"go.abhg.dev/goldmark/frontmatter"
+	"github.com/yuin/goldmark/util"
 	"go.abhg.dev/goldmark/mermaid"
-	"gopkg.in/yaml.v3"
 )
 
+// Warning is a problem found while parsing content that doesn't stop it from loading
+type Warning struct {
+	Line    int // 1-based line in the source file, zero if unknown
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Line == 0 {
+		return w.Message
+	}
+	return fmt.Sprintf("line %d: %s", w.Line, w.Message)
+}
+
+// warningsKey is the parser context key under which warnings are collected
+var warningsKey = parser.NewContextKey()
+
+// addWarning records a warning in the parser context
+func addWarning(pc parser.Context, line int, format string, args ...any) {
+	warnings, _ := pc.Get(warningsKey).([]Warning)
+	pc.Set(warningsKey, append(warnings, Warning{Line: line, Message: fmt.Sprintf(format, args...)}))
+}
+
 // MarkdownParser handles parsing markdown with frontmatter and extensions
 type MarkdownParser struct {
-	md        goldmark.Markdown
-	sanitizer *bluemonday.Policy
+	md         goldmark.Markdown
+	sanitizers map[string]*bluemonday.Policy // by policy name
+	policies   *SanitizerConfig              // chooses the policy of each content file, if set
+	links      LinkResolver                  // resolves wiki-links when Parse is not given a resolver
+	schema     *Schema                       // checks the frontmatter of posts and pages, if set
+}
+
+// MarkdownOptions selects the Markdown syntax the parser understands and how code is highlighted
+type MarkdownOptions struct {
+	Footnotes       bool   // [^1] references with the notes listed at the end
+	DefinitionLists bool   // Terms followed by lines starting with ": "
+	Typographer     bool   // Curly quotes, dashes and ellipses
+	Attributes      bool   // {#id .class} after headings
+	HeadingIDs      bool   // IDs derived from the heading text, needed for the TOC and anchors
+	HardWraps       bool   // Every line break in a paragraph is a <br>
+	Highlight       bool   // Syntax highlighting of fenced code blocks
+	HighlightStyle  string // Chroma style the highlighting CSS is generated from
+	LineNumbers     bool   // Line numbers on highlighted code blocks
+	Mermaid         bool   // Mermaid diagrams from ```mermaid blocks
+}
+
+// DefaultHighlightStyle is the highlighting style used when neither the configuration nor the
+// theme names one
+const DefaultHighlightStyle = "autumn"
+
+// DefaultMarkdownOptions returns the options parsers use unless given others: GitHub Flavored
+// Markdown with heading IDs, highlighted code with line numbers and Mermaid diagrams
+func DefaultMarkdownOptions() MarkdownOptions {
+	return MarkdownOptions{
+		HeadingIDs:     true,
+		Highlight:      true,
+		HighlightStyle: DefaultHighlightStyle,
+		LineNumbers:    true,
+		Mermaid:        true,
+	}
+}
+
+// Validate reports an unknown highlighting style
+func (o MarkdownOptions) Validate() error {
+	if _, found := styles.Registry[o.HighlightStyle]; o.Highlight && !found {
+		return fmt.Errorf("unknown highlight style %q", o.HighlightStyle)
+	}
+	return nil
+}
+
+// formatOptions returns the options highlighted code is rendered with, as CSS classes so
+// the theme's stylesheet decides the colours
+func (o MarkdownOptions) formatOptions() []chroma.Option {
+	return []chroma.Option{
+		chroma.WithClasses(true),
+		chroma.WithLineNumbers(o.LineNumbers),
+	}
+}
+
+// WriteHighlightCSS writes the stylesheet for highlighted code in the options' style
+func (o MarkdownOptions) WriteHighlightCSS(w io.Writer) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	return chroma.New(o.formatOptions()...).WriteCSS(w, styles.Get(o.HighlightStyle))
+}
+
+// extensions returns the goldmark extensions the options enable, GFM always among them
+func (o MarkdownOptions) extensions() []goldmark.Extender {
+	extensions := []goldmark.Extender{
+		extension.GFM, // Tables, strikethrough, task lists, autolink, emoji
+	}
+	if o.Footnotes {
+		extensions = append(extensions, extension.Footnote)
+	}
+	if o.DefinitionLists {
+		extensions = append(extensions, extension.DefinitionList)
+	}
+	if o.Typographer {
+		extensions = append(extensions, extension.Typographer)
+	}
+	if o.Highlight {
+		extensions = append(extensions, highlighting.NewHighlighting(
+			highlighting.WithStyle(o.HighlightStyle),
+			highlighting.WithFormatOptions(o.formatOptions()...),
+		))
+	}
+	if o.Mermaid {
+		extensions = append(extensions, &mermaid.Extender{NoScript: true}) // The theme layouts load mermaid.js
+	}
+	return extensions
+}
+
+// parserOptions returns the goldmark parser options the options enable
+func (o MarkdownOptions) parserOptions() []parser.Option {
+	var opts []parser.Option
+	if o.HeadingIDs {
+		opts = append(opts, parser.WithAutoHeadingID()) // Unique IDs derived from the heading text
+	}
+	if o.Attributes {
+		opts = append(opts, parser.WithAttribute())
+	}
+	return opts
+}
+
+// rendererOptions returns the goldmark renderer options the options enable
+func (o MarkdownOptions) rendererOptions() []renderer.Option {
+	opts := []renderer.Option{
+		goldmarkHTML.WithUnsafe(), // Allow raw HTML
+	}
+	if o.HardWraps {
+		opts = append(opts, goldmarkHTML.WithHardWraps())
+	}
+	return opts
+}
+
+// parserConfig holds the settings applied by ParserOptions
+type parserConfig struct {
+	headingAnchors bool
+	markdown       MarkdownOptions
+	shortcodes     ShortcodeRenderer
+	links          LinkResolver
+	schema         *Schema
+	sanitizer      *SanitizerConfig
+}
+
+// ParserOption configures a MarkdownParser
+type ParserOption func(*parserConfig)
+
+// WithHeadingAnchors appends a permalink anchor to every heading, for themes to reveal on hover
+func WithHeadingAnchors(enabled bool) ParserOption {
+	return func(c *parserConfig) {
+		c.headingAnchors = enabled
+	}
+}
+
+// WithMarkdownOptions replaces the default Markdown syntax and highlighting options
+func WithMarkdownOptions(options MarkdownOptions) ParserOption {
+	return func(c *parserConfig) {
+		c.markdown = options
+	}
+}
+
+// WithSanitizer chooses the sanitizer policy of each content file and extends the policies
+// with config. Without it, everything is sanitized with the standard policy.
+func WithSanitizer(config *SanitizerConfig) ParserOption {
+	return func(c *parserConfig) {
+		c.sanitizer = config
+	}
 }
 
 // NewMarkdownParser creates a new markdown parser with all configured extensions
-func NewMarkdownParser() *MarkdownParser {
+func NewMarkdownParser(opts ...ParserOption) *MarkdownParser {
+	cfg := parserConfig{markdown: DefaultMarkdownOptions()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	md := goldmark.New(
+		goldmark.WithExtensions(cfg.markdown.extensions()...),
 		goldmark.WithExtensions(
-			&frontmatter.Extender{},
-			extension.GFM, // Tables, strikethrough, task lists, autolink, emoji
-			highlighting.NewHighlighting(
-				highlighting.WithStyle("autumn"),
-				highlighting.WithFormatOptions(
-					chroma.WithClasses(true),
-					chroma.WithLineNumbers(true),
-				),
-			),
-			&mermaid.Extender{},
+			&shortcodeExtension{shortcodes: cfg.shortcodes},
+			&wikiLinkExtension{},
 		),
-		goldmark.WithRendererOptions(
-			goldmarkHTML.WithUnsafe(), // Allow raw HTML
+		goldmark.WithParserOptions(cfg.markdown.parserOptions()...),
+		goldmark.WithParserOptions(
+			parser.WithASTTransformers(
+				util.Prioritized(&headingTransformer{anchors: cfg.headingAnchors}, 100),
+				util.Prioritized(&linkCollector{}, 200),
+			),
 		),
+		goldmark.WithRendererOptions(cfg.markdown.rendererOptions()...),
 	)
 
-	// Create a permissive but safe HTML sanitizer
-	sanitizer := bluemonday.NewPolicy()
-
-	// Allow common HTML elements for rich content
-	sanitizer.AllowElements("p", "br", "strong", "em", "u", "s", "del", "ins", "mark")
-	sanitizer.AllowElements("h1", "h2", "h3", "h4", "h5", "h6")
-	sanitizer.AllowElements("ul", "ol", "li", "dl", "dt", "dd")
-	sanitizer.AllowElements("blockquote", "pre", "code")
-	sanitizer.AllowElements("a", "img", "figure", "figcaption")
-	sanitizer.AllowElements("table", "thead", "tbody", "tfoot", "tr", "th", "td")
-	sanitizer.AllowElements("div", "span", "section", "article", "header", "footer", "main")
-	sanitizer.AllowElements("style")
-
-	// Allow attributes
-	sanitizer.AllowAttrs("href", "title").OnElements("a")
-	sanitizer.AllowAttrs("src", "alt", "title", "width", "height").OnElements("img")
-	sanitizer.AllowAttrs("class", "id").Globally()
-	sanitizer.AllowAttrs("style").OnElements("pre", "code", "span") // For syntax highlighting
-
-	// Allow data attributes for Mermaid diagrams
-	sanitizer.AllowAttrs("class").Matching(regexp.MustCompile(`^mermaid$`)).OnElements("pre")
-
-	// Allow specific CSS properties for syntax highlighting
-	sanitizer.AllowStyles("color", "background-color", "font-weight", "font-style", "text-decoration").Globally()
-
 	return &MarkdownParser{
-		md:        md,
-		sanitizer: sanitizer,
+		md:         md,
+		sanitizers: newSanitizers(cfg.sanitizer),
+		policies:   cfg.sanitizer,
+		links:      cfg.links,
+		schema:     cfg.schema,
 	}
 }
 
 // Parse parses markdown content with frontmatter
 func (p *MarkdownParser) Parse(content []byte) (*Content, error) {
-	// Create parser context
-	ctx := parser.NewContext()
+	return p.ParseWithLinks(content, p.links)
+}
 
-	// Convert markdown to HTML
-	var htmlBuf bytes.Buffer
-	if err := p.md.Convert(content, &htmlBuf, parser.WithContext(ctx)); err != nil {
-		return nil, fmt.Errorf("failed to convert markdown: %w", err)
-	}
+// ParseWithLinks parses markdown content with frontmatter, resolving wiki-links with links
+func (p *MarkdownParser) ParseWithLinks(content []byte, links LinkResolver) (*Content, error) {
+	return p.parse(content, links, "")
+}
+
+// parse parses markdown content read from dataPath within the data directory, which with the
+// authors chooses the sanitizer policy, resolving wiki-links with links
+func (p *MarkdownParser) parse(content []byte, links LinkResolver, dataPath string) (*Content, error) {
+	doc := splitFrontmatter(content)
 
-	// Extract frontmatter
-	fm := frontmatter.Get(ctx)
 	var frontmatterData Frontmatter
-	if fm != nil {
-		if err := fm.Decode(&frontmatterData); err != nil {
-			return nil, fmt.Errorf("failed to decode frontmatter: %w", err)
-		}
+	raw, err := doc.decode(&frontmatterData)
+	if err != nil {
+		return nil, err
 	}
 
-	// Extract body content (everything after frontmatter)
-	body := p.extractBody(content)
+	params, err := p.params(raw)
+	if err != nil {
+		return nil, err
+	}
 
-	// Sanitize the HTML output
-	sanitizedHTML := p.sanitizer.Sanitize(htmlBuf.String())
+	policy := p.policies.policy(dataPath, frontmatterData.AuthorSlugs())
+	html, ctx, err := p.convert(doc, links, policy)
+	if err != nil {
+		return nil, err
+	}
 
-	return &Content{
+	parsed := &Content{
 		Frontmatter: frontmatterData,
-		Body:        body,
-		HTML:        sanitizedHTML,
-	}, nil
-}
+		Params:      params,
+		Body:        doc.Body(),
+		HTML:        html,
+	}
+	parsed.Warnings, _ = ctx.Get(warningsKey).([]Warning)
+	parsed.Warnings = append(parsed.Warnings, emptyTagWarnings(frontmatterData)...)
+	parsed.hasWikiLinks = ctx.Get(wikiLinksKey) != nil
+	parsed.Links, _ = ctx.Get(internalLinksKey).([]string)
+	summarize(parsed, ctx)
 
-// extractBody extracts the markdown body content after frontmatter
-func (p *MarkdownParser) extractBody(content []byte) string {
-	contentStr := string(content)
+	if frontmatterData.TOCEnabled() {
+		headings, _ := ctx.Get(headingsKey).([]heading)
+		parsed.TOC = buildTOC(headings, frontmatterData.TOCMaxDepth())
+	}
+
+	return parsed, nil
+}
 
-	// Find the end of frontmatter
-	if strings.HasPrefix(contentStr, "---\n") {
-		// Find the closing ---
-		parts := strings.SplitN(contentStr, "\n---\n", 2)
-		if len(parts) > 1 {
-			return strings.TrimSpace(parts[1])
+// params collects the frontmatter fields Frontmatter doesn't know about and checks
+// the frontmatter against the schema, if any
+func (p *MarkdownParser) params(raw map[string]any) (map[string]any, error) {
+	params := make(map[string]any)
+	for key, value := range raw {
+		if !builtinFields[key] {
+			params[key] = value
 		}
 	}
 
-	return contentStr
+	if err := p.schema.apply(raw, params); err != nil {
+		return nil, err
+	}
+	return params, nil
 }
 
-// ParseFrontmatterOnly parses only the frontmatter from content
-func (p *MarkdownParser) ParseFrontmatterOnly(content []byte) (*Frontmatter, error) {
-	contentStr := string(content)
+// ParseAuthor parses an author profile file, using the markdown body as the extended bio
+func (p *MarkdownParser) ParseAuthor(content []byte) (*Author, error) {
+	doc := splitFrontmatter(content)
 
-	// Check if content has frontmatter
-	if !strings.HasPrefix(contentStr, "---\n") {
-		return &Frontmatter{}, nil
+	var author Author
+	if _, err := doc.decode(&author); err != nil {
+		return nil, err
 	}
 
-	// Extract frontmatter section
-	parts := strings.SplitN(contentStr, "\n---\n", 2)
-	if len(parts) < 2 {
-		return &Frontmatter{}, nil
+	html, _, err := p.convert(doc, p.links, p.policies.policy("", nil))
+	if err != nil {
+		return nil, err
 	}
 
-	frontmatterStr := parts[0][4:] // Remove the opening ---
+	author.HTML = html
+	return &author, nil
+}
+
+// convert renders the markdown body of a document to HTML sanitized with the named policy,
+// warning of everything the policy removed.
+// The parser context is returned for callers that need data collected while parsing.
+func (p *MarkdownParser) convert(doc *document, links LinkResolver, policy string) (string, parser.Context, error) {
+	// Create parser context
+	ctx := parser.NewContext()
+	if links != nil {
+		ctx.Set(linksKey, links)
+	}
+
+	// Convert markdown to HTML
+	var htmlBuf bytes.Buffer
+	if err := p.md.Convert(doc.markdown(), &htmlBuf, parser.WithContext(ctx)); err != nil {
+		return "", nil, fmt.Errorf("failed to convert markdown: %w", err)
+	}
+
+	// Sanitize the HTML output
+	sanitizer := p.sanitizers[policy]
+	for _, removed := range stripped(sanitizer, htmlBuf.String()) {
+		addWarning(ctx, 0, "the %s sanitizer policy removed %s", policy, removed)
+	}
+	keepExcerpt(ctx, sanitizer, htmlBuf.String())
+	return sanitizer.Sanitize(htmlBuf.String()), ctx, nil
+}
+
+// ParseFrontmatterOnly parses only the frontmatter from content, in any of the
+// supported formats
+func (p *MarkdownParser) ParseFrontmatterOnly(content []byte) (*Frontmatter, error) {
+	doc := splitFrontmatter(content)
 
 	var frontmatterData Frontmatter
-	if err := yaml.Unmarshal([]byte(frontmatterStr), &frontmatterData); err != nil {
-		return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
+	if _, err := doc.decode(&frontmatterData); err != nil {
+		return nil, err
 	}
 
 	return &frontmatterData, nil
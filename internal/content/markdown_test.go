@@ -0,0 +1,129 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownOptions(t *testing.T) {
+	parse := func(t *testing.T, markdown string, change func(*MarkdownOptions)) string {
+		t.Helper()
+		options := DefaultMarkdownOptions()
+		change(&options)
+		parsed, err := NewMarkdownParser(WithMarkdownOptions(options)).Parse([]byte("---\ntitle: Test\n---\n\n" + markdown))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed.HTML
+	}
+
+	tests := []struct {
+		name       string
+		markdown   string
+		change     func(*MarkdownOptions)
+		want, lose string
+	}{
+		{
+			name:     "Footnotes",
+			markdown: "Claim[^1]\n\n[^1]: Source",
+			change:   func(o *MarkdownOptions) { o.Footnotes = true },
+			want:     `<sup id="fnref:1"><a href="#fn:1" class="footnote-ref"`,
+		},
+		{
+			name:     "No footnotes by default",
+			markdown: "Claim[^1]\n\n[^1]: Source",
+			change:   func(o *MarkdownOptions) {},
+			lose:     "<sup",
+		},
+		{
+			name:     "Definition lists",
+			markdown: "Term\n: Definition",
+			change:   func(o *MarkdownOptions) { o.DefinitionLists = true },
+			want:     "<dl>\n<dt>Term</dt>\n<dd>Definition</dd>\n</dl>",
+		},
+		{
+			name:     "Typographer",
+			markdown: `"Quoted" -- text...`,
+			change:   func(o *MarkdownOptions) { o.Typographer = true },
+			want:     "“Quoted” – text…",
+		},
+		{
+			name:     "Attributes",
+			markdown: "## Setup {#install .wide}",
+			change:   func(o *MarkdownOptions) { o.Attributes = true },
+			want:     `<h2 id="install" class="wide">Setup`,
+		},
+		{
+			name:     "No heading IDs",
+			markdown: "## Setup",
+			change:   func(o *MarkdownOptions) { o.HeadingIDs = false },
+			want:     "<h2>Setup</h2>",
+		},
+		{
+			name:     "Hard wraps",
+			markdown: "One\nTwo",
+			change:   func(o *MarkdownOptions) { o.HardWraps = true },
+			want:     "One<br>\nTwo",
+		},
+		{
+			name:     "Highlighting with line numbers",
+			markdown: "```go\nfunc main() {}\n```",
+			change:   func(o *MarkdownOptions) {},
+			want:     `<span class="ln">1</span>`,
+		},
+		{
+			name:     "Highlighting without line numbers",
+			markdown: "```go\nfunc main() {}\n```",
+			change:   func(o *MarkdownOptions) { o.LineNumbers = false },
+			want:     `<span class="kd">func</span>`,
+			lose:     `class="ln"`,
+		},
+		{
+			name:     "No highlighting",
+			markdown: "```go\nfunc main() {}\n```",
+			change:   func(o *MarkdownOptions) { o.Highlight = false },
+			want:     `<pre><code class="language-go">func main() {}`,
+		},
+		{
+			name:     "No Mermaid",
+			markdown: "```mermaid\ngraph TD\n```",
+			change:   func(o *MarkdownOptions) { o.Mermaid = false; o.Highlight = false },
+			want:     `<code class="language-mermaid">`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := parse(t, tt.markdown, tt.change)
+			if tt.want != "" && !strings.Contains(html, tt.want) {
+				t.Errorf("Expected %q in:\n%s", tt.want, html)
+			}
+			if tt.lose != "" && strings.Contains(html, tt.lose) {
+				t.Errorf("Expected no %q in:\n%s", tt.lose, html)
+			}
+		})
+	}
+}
+
+func TestMarkdownOptions_HighlightCSS(t *testing.T) {
+	options := DefaultMarkdownOptions()
+	options.HighlightStyle = "nord"
+
+	var css strings.Builder
+	if err := options.WriteHighlightCSS(&css); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(css.String(), ".chroma .kd {") {
+		t.Errorf("Expected rules for the highlighting classes, got %s", css.String())
+	}
+
+	options.HighlightStyle = "no-such-style"
+	if err := options.Validate(); err == nil || !strings.Contains(err.Error(), `unknown highlight style "no-such-style"`) {
+		t.Errorf("Expected an unknown style error, got %v", err)
+	}
+
+	options.Highlight = false
+	if err := options.Validate(); err != nil {
+		t.Errorf("Expected the style to be ignored without highlighting, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package content
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DefaultRelatedCount is the number of related posts kept for each post by default
+const DefaultRelatedCount = 3
+
+// relatedHalfLife is how much older a candidate can be than the newest post before
+// the recency part of its weight halves
+const relatedHalfLife = 365 * 24 * time.Hour
+
+// minTermLength is the shortest word used for content similarity
+const minTermLength = 3
+
+// StoreOption configures a Store
+type StoreOption func(*Store)
+
+// WithRelatedPosts sets how many related posts are kept for each post, zero disables them
+func WithRelatedPosts(count int) StoreOption {
+	return func(s *Store) {
+		s.relatedCount = max(count, 0)
+	}
+}
+
+// WithContentSimilarity also relates posts by the TF-IDF similarity of their text,
+// not just by shared tags
+func WithContentSimilarity(enabled bool) StoreOption {
+	return func(s *Store) {
+		s.contentSimilarity = enabled
+	}
+}
+
+// relatedScorer ranks published posts against each other
+type relatedScorer struct {
+	candidates []*Content           // published posts, newest first
+	order      map[*Content]int     // position of each candidate in candidates
+	recency    map[*Content]float64 // weight of each candidate, from 1 for the newest post towards 0.5
+	tags       map[*Content]map[string]bool
+	byTag      map[string][]*Content // candidates by tag slug, newest first
+	vectors    map[*Content]map[string]float64
+}
+
+// newRelatedScorer precomputes the recency weights, the tag sets and the candidates carrying
+// each tag, and the TF-IDF vectors if similarity is enabled
+func newRelatedScorer(posts []*Content, similarity bool) *relatedScorer {
+	rs := &relatedScorer{
+		candidates: posts,
+		order:      make(map[*Content]int, len(posts)),
+		recency:    make(map[*Content]float64, len(posts)),
+		tags:       make(map[*Content]map[string]bool, len(posts)),
+		byTag:      make(map[string][]*Content),
+	}
+	if len(posts) == 0 {
+		return rs
+	}
+
+	newest := posts[0].GetDate()
+	for i, post := range posts {
+		// Recency never more than halves a score, so an extra shared tag always outranks it
+		age := newest.Sub(post.GetDate())
+		rs.recency[post] = 0.5 + 0.5*math.Pow(0.5, float64(age)/float64(relatedHalfLife))
+		rs.order[post] = i
+
+		rs.tags[post] = tagSet(post)
+		for slug := range rs.tags[post] {
+			rs.byTag[slug] = append(rs.byTag[slug], post)
+		}
+	}
+
+	if similarity {
+		rs.vectors = tfidfVectors(posts)
+	}
+
+	return rs
+}
+
+// tagSet returns the slugs of the content's tags
+func tagSet(content *Content) map[string]bool {
+	tags := make(map[string]bool)
+	for _, tag := range content.Tags() {
+		tags[tag.Slug] = true
+	}
+	return tags
+}
+
+// related returns up to count published posts sharing the most tags with content,
+// favouring recent posts and, with similarity enabled, posts with similar text
+func (rs *relatedScorer) related(content *Content, count int) []*Content {
+	if count == 0 {
+		return nil
+	}
+
+	tags, ok := rs.tags[content]
+	if !ok {
+		tags = tagSet(content) // Drafts and scheduled posts aren't candidates
+	}
+	vector := rs.vectors[content]
+
+	shared := make(map[*Content]int)
+	for slug := range tags {
+		for _, candidate := range rs.byTag[slug] {
+			shared[candidate]++
+		}
+	}
+
+	// Only posts sharing a tag can score without similarity
+	candidates := rs.candidates
+	if vector == nil {
+		candidates = make([]*Content, 0, len(shared))
+		for candidate := range shared {
+			candidates = append(candidates, candidate)
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			return rs.order[candidates[i]] < rs.order[candidates[j]]
+		})
+	}
+
+	type scored struct {
+		post  *Content
+		score float64
+	}
+	var results []scored
+
+	for _, candidate := range candidates {
+		if candidate.Frontmatter.Slug == content.Frontmatter.Slug {
+			continue
+		}
+
+		score := float64(shared[candidate])
+		if vector != nil {
+			score += cosine(vector, rs.vectors[candidate])
+		}
+
+		if score > 0 {
+			results = append(results, scored{candidate, score * rs.recency[candidate]})
+		}
+	}
+
+	// Candidates are newest first, so a stable sort keeps ties in date order
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+
+	related := make([]*Content, 0, min(count, len(results)))
+	for _, r := range results[:min(count, len(results))] {
+		related = append(related, r.post)
+	}
+	return related
+}
+
+// tfidfVectors returns the unit-length TF-IDF term vector of each post's text
+func tfidfVectors(posts []*Content) map[*Content]map[string]float64 {
+	counts := make(map[*Content]map[string]int, len(posts))
+	docFreq := make(map[string]int)
+
+	for _, post := range posts {
+		tf := make(map[string]int)
+		for _, term := range terms(plainText(post.HTML)) {
+			tf[term]++
+		}
+		for term := range tf {
+			docFreq[term]++
+		}
+		counts[post] = tf
+	}
+
+	vectors := make(map[*Content]map[string]float64, len(posts))
+	for post, tf := range counts {
+		vector := make(map[string]float64, len(tf))
+		norm := 0.0
+		for term, n := range tf {
+			weight := float64(n) * math.Log(float64(len(posts))/float64(docFreq[term]))
+			if weight > 0 {
+				vector[term] = weight
+				norm += weight * weight
+			}
+		}
+
+		norm = math.Sqrt(norm)
+		for term := range vector {
+			vector[term] /= norm
+		}
+		vectors[post] = vector
+	}
+
+	return vectors
+}
+
+// terms splits text into lowercase words, leaving out words too short to be meaningful
+func terms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	kept := words[:0]
+	for _, word := range words {
+		if len([]rune(word)) >= minTermLength {
+			kept = append(kept, word)
+		}
+	}
+	return kept
+}
+
+// cosine returns the similarity of two unit-length vectors
+func cosine(a, b map[string]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+
+	dot := 0.0
+	for term, weight := range a {
+		dot += weight * b[term]
+	}
+	return dot
+}
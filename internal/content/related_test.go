@@ -0,0 +1,98 @@
+package content
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func relatedSlugs(t *testing.T, store *Store, slug string) []string {
+	t.Helper()
+
+	post, found := store.PreviewPost(slug)
+	if !found {
+		t.Fatalf("Expected to find post %s", slug)
+	}
+
+	var slugs []string
+	for _, related := range post.Related {
+		slugs = append(slugs, related.Frontmatter.Slug)
+	}
+	return slugs
+}
+
+func equalSlugs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStore_RelatedPosts(t *testing.T) {
+	t.Run("Ranks by shared tags, then recency", func(t *testing.T) {
+		store, dataDir := newTestStore(t, WithRelatedPosts(2))
+
+		writeContentFile(t, filepath.Join(dataDir, "blog", "base.md"), "---\ntitle: Base\ndate: 2024-01-01T10:00:00Z\ntags: [go, web]\n---\n\nBase")
+		writeContentFile(t, filepath.Join(dataDir, "blog", "both.md"), "---\ntitle: Both\ndate: 2023-01-01T10:00:00Z\ntags: [go, web]\n---\n\nBoth")
+		writeContentFile(t, filepath.Join(dataDir, "blog", "old-go.md"), "---\ntitle: Old Go\ndate: 2023-06-01T10:00:00Z\ntags: [go]\n---\n\nOld")
+		writeContentFile(t, filepath.Join(dataDir, "blog", "new-go.md"), "---\ntitle: New Go\ndate: 2024-02-01T10:00:00Z\ntags: [go]\n---\n\nNew")
+		writeContentFile(t, filepath.Join(dataDir, "blog", "unrelated.md"), "---\ntitle: Unrelated\ndate: 2024-03-01T10:00:00Z\ntags: [cooking]\n---\n\nFood")
+		writeContentFile(t, filepath.Join(dataDir, "blog", "draft.md"), "---\ntitle: Draft\ndate: 2024-03-01T10:00:00Z\ntags: [go, web]\ndraft: true\n---\n\nWIP")
+
+		if err := store.Load(); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+
+		if got, want := relatedSlugs(t, store, "base"), []string{"both", "new-go"}; !equalSlugs(got, want) {
+			t.Errorf("Expected related posts %v, got %v", want, got)
+		}
+		if got := relatedSlugs(t, store, "unrelated"); len(got) != 0 {
+			t.Errorf("Expected no related posts without shared tags, got %v", got)
+		}
+		if got, want := relatedSlugs(t, store, "draft"), []string{"base", "both"}; !equalSlugs(got, want) {
+			t.Errorf("Expected drafts to get published related posts %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Recomputes when content changes", func(t *testing.T) {
+		store, dataDir := newTestStore(t)
+
+		writeContentFile(t, filepath.Join(dataDir, "blog", "first.md"), "---\ntitle: First\ndate: 2024-01-01T10:00:00Z\ntags: [go]\n---\n\nFirst")
+		writeContentFile(t, filepath.Join(dataDir, "blog", "second.md"), "---\ntitle: Second\ndate: 2024-02-01T10:00:00Z\ntags: [rust]\n---\n\nSecond")
+
+		if err := store.Load(); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if got := relatedSlugs(t, store, "first"); len(got) != 0 {
+			t.Fatalf("Expected no related posts, got %v", got)
+		}
+
+		path := filepath.Join(dataDir, "blog", "second.md")
+		writeContentFile(t, path, "---\ntitle: Second\ndate: 2024-02-01T10:00:00Z\ntags: [go]\n---\n\nSecond")
+		store.HandleFileChange(path)
+
+		if got, want := relatedSlugs(t, store, "first"), []string{"second"}; !equalSlugs(got, want) {
+			t.Errorf("Expected related posts %v after the change, got %v", want, got)
+		}
+	})
+
+	t.Run("Content similarity relates posts without shared tags", func(t *testing.T) {
+		store, dataDir := newTestStore(t, WithContentSimilarity(true))
+
+		writeContentFile(t, filepath.Join(dataDir, "blog", "goroutines.md"), "---\ntitle: Goroutines\ndate: 2024-01-01T10:00:00Z\n---\n\nChannels and goroutines make concurrency simple.")
+		writeContentFile(t, filepath.Join(dataDir, "blog", "channels.md"), "---\ntitle: Channels\ndate: 2024-02-01T10:00:00Z\n---\n\nBuffered channels decouple goroutines.")
+		writeContentFile(t, filepath.Join(dataDir, "blog", "baking.md"), "---\ntitle: Baking\ndate: 2024-03-01T10:00:00Z\n---\n\nSourdough needs a lively starter.")
+
+		if err := store.Load(); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+
+		if got, want := relatedSlugs(t, store, "goroutines"), []string{"channels"}; !equalSlugs(got, want) {
+			t.Errorf("Expected related posts %v, got %v", want, got)
+		}
+	})
+}
@@ -0,0 +1,317 @@
+package content
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+)
+
+// Names of the built-in sanitizer policies
+const (
+	PolicyStrict   = "strict"   // The Markdown renderer's own output, with nofollow on external links
+	PolicyStandard = "standard" // Layout elements, details, audio, video and YouTube embeds too
+	PolicyTrusted  = "trusted"  // Inline styles, data attributes and embeds from any HTTPS site too
+)
+
+// policyNames lists the sanitizer policies from the least to the most permissive
+var policyNames = []string{PolicyStrict, PolicyStandard, PolicyTrusted}
+
+// youtubeEmbedPattern matches the YouTube embed URLs iframes may load under the standard policy
+var youtubeEmbedPattern = regexp.MustCompile(`^https://www\.youtube(-nocookie)?\.com/embed/[A-Za-z0-9_-]+(\?[^"]*)?$`)
+
+// Allowlist adds elements, attributes and embed sources to a sanitizer policy
+type Allowlist struct {
+	Elements   []string            `yaml:"elements"`   // Elements allowed, without attributes
+	Attributes map[string][]string `yaml:"attributes"` // Attributes allowed by element, "*" for every element
+	Embeds     []string            `yaml:"embeds"`     // URL prefixes iframes may load, such as https://player.vimeo.com/video/
+}
+
+// SanitizerConfig chooses the sanitizer policy each content file's HTML is cleaned with and
+// extends the policies' allowlists
+type SanitizerConfig struct {
+	Default     string               `yaml:"default"`     // Policy no rule chooses, standard when empty
+	Directories map[string]string    `yaml:"directories"` // Policy by directory, such as blog/guests
+	Authors     map[string]string    `yaml:"authors"`     // Policy by author slug
+	Policies    map[string]Allowlist `yaml:"policies"`    // Allowlist by policy, also given to the more permissive policies
+}
+
+// LoadSanitizerConfig reads sanitizer policy rules and allowlists from a YAML file
+func LoadSanitizerConfig(path string) (*SanitizerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config SanitizerConfig
+	err = yaml.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sanitizer config %s: %w", path, err)
+	}
+
+	err = config.validate()
+	if err != nil {
+		return nil, fmt.Errorf("sanitizer config %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// validate reports unknown policies and allowlists that would let scripts through
+func (c *SanitizerConfig) validate() error {
+	known := func(name string) bool { return slices.Contains(policyNames, name) }
+
+	if c.Default != "" && !known(c.Default) {
+		return fmt.Errorf("unknown default policy %q", c.Default)
+	}
+	for dir, name := range c.Directories {
+		if !known(name) {
+			return fmt.Errorf("unknown policy %q for directory %s", name, dir)
+		}
+	}
+	for author, name := range c.Authors {
+		if !known(name) {
+			return fmt.Errorf("unknown policy %q for author %q", name, author)
+		}
+	}
+
+	for name, allow := range c.Policies {
+		if !known(name) {
+			return fmt.Errorf("unknown policy %q, expected one of %s", name, strings.Join(policyNames, ", "))
+		}
+		for _, element := range allow.Elements {
+			if element == "script" || element == "style" {
+				return fmt.Errorf("policy %q cannot allow <%s> elements", name, element)
+			}
+		}
+		for element, attrs := range allow.Attributes {
+			for _, attr := range attrs {
+				if strings.HasPrefix(strings.ToLower(attr), "on") {
+					return fmt.Errorf("policy %q cannot allow the event handler %s on %s", name, attr, element)
+				}
+			}
+		}
+		for _, embed := range allow.Embeds {
+			if !strings.HasPrefix(embed, "https://") || len(embed) <= len("https://") {
+				return fmt.Errorf("policy %q embed %q must be an https:// URL prefix with a host", name, embed)
+			}
+		}
+	}
+	return nil
+}
+
+// policy returns the name of the policy for content read from dataPath within the data
+// directory and written by authors: the strictest of the authors' policies, or else that of
+// the deepest directory rule dataPath lies in, or else the default
+func (c *SanitizerConfig) policy(dataPath string, authors []string) string {
+	if c == nil {
+		return PolicyStandard
+	}
+
+	chosen := -1
+	for _, author := range authors {
+		if name, ok := c.Authors[author]; ok {
+			if rank := slices.Index(policyNames, name); chosen == -1 || rank < chosen {
+				chosen = rank
+			}
+		}
+	}
+	if chosen != -1 {
+		return policyNames[chosen]
+	}
+
+	if dataPath != "" {
+		dir := path.Dir(filepath.ToSlash(dataPath))
+		var deepest, name string
+		for rule, policy := range c.Directories {
+			rule = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(rule)), "/")
+			if rule == "" {
+				continue
+			}
+			if (dir == rule || strings.HasPrefix(dir, rule+"/")) && len(rule) > len(deepest) {
+				deepest, name = rule, policy
+			}
+		}
+		if name != "" {
+			return name
+		}
+	}
+
+	if c.Default != "" {
+		return c.Default
+	}
+	return PolicyStandard
+}
+
+// newSanitizers builds every policy, each with its own allowlist and those of the stricter
+// policies from the configuration
+func newSanitizers(config *SanitizerConfig) map[string]*bluemonday.Policy {
+	var allowlists []Allowlist
+	sanitizers := make(map[string]*bluemonday.Policy, len(policyNames))
+	for _, name := range policyNames {
+		if config != nil {
+			allowlists = append(allowlists, config.Policies[name])
+		}
+		sanitizers[name] = newSanitizer(name, allowlists)
+	}
+	return sanitizers
+}
+
+// newSanitizer builds the named built-in policy with the allowlists added
+func newSanitizer(name string, allowlists []Allowlist) *bluemonday.Policy {
+	rank := slices.Index(policyNames, name)
+	atLeast := func(other string) bool { return rank >= slices.Index(policyNames, other) }
+	sanitizer := bluemonday.NewPolicy()
+
+	// Everything the Markdown renderer produces
+	sanitizer.AllowElements("p", "br", "hr", "strong", "em", "u", "s", "del", "ins", "mark", "sup", "sub")
+	sanitizer.AllowElements("h1", "h2", "h3", "h4", "h5", "h6")
+	sanitizer.AllowElements("ul", "ol", "li", "dl", "dt", "dd")
+	sanitizer.AllowElements("blockquote", "pre", "code", "div", "span")
+	sanitizer.AllowElements("table", "thead", "tbody", "tfoot", "tr", "th", "td")
+	sanitizer.AllowAttrs("href", "title").OnElements("a")
+	sanitizer.AllowAttrs("src", "alt", "title", "width", "height").OnElements("img")
+	sanitizer.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input") // Task lists
+	sanitizer.AllowAttrs("checked", "disabled").OnElements("input")
+
+	// Classes for highlighting, footnotes and Mermaid; IDs for headings, the targets of
+	// TOC and permalink anchors
+	sanitizer.AllowAttrs("class", "id").Globally()
+	sanitizer.AllowRelativeURLs(true)
+	sanitizer.AllowURLSchemes("http", "https", "mailto") // Checking relative URLs rejects every absolute one otherwise
+
+	var embeds []string
+	if name == PolicyStrict {
+		sanitizer.RequireNoFollowOnFullyQualifiedLinks(true)
+	}
+
+	if atLeast(PolicyStandard) {
+		sanitizer.AllowElements("figure", "figcaption", "section", "article", "aside", "header", "footer", "main")
+		sanitizer.AllowElements("details", "summary", "abbr", "kbd", "small")
+		sanitizer.AllowAttrs("open").OnElements("details")
+		sanitizer.AllowAttrs("title").OnElements("abbr")
+		sanitizer.AllowAttrs("src", "controls", "loop", "muted", "preload").OnElements("audio", "video")
+		sanitizer.AllowAttrs("poster", "width", "height", "playsinline").OnElements("video")
+		sanitizer.AllowAttrs("src", "type").OnElements("source")
+		sanitizer.AllowAttrs("style").OnElements("pre", "code", "span")
+		sanitizer.AllowStyles("color", "background-color", "font-weight", "font-style", "text-decoration").Globally()
+
+		// YouTube embeds, as rendered by the youtube shortcode
+		embeds = append(embeds, youtubeEmbedPattern.String())
+	}
+
+	if atLeast(PolicyTrusted) {
+		sanitizer.AllowAttrs("style").Globally()
+		sanitizer.AllowStyles("text-align", "margin", "padding", "width", "max-width", "height", "border", "display").Globally()
+		sanitizer.AllowDataAttributes()
+		sanitizer.AllowAttrs("target", "rel").OnElements("a")
+		embeds = append(embeds, `^https://`)
+	}
+
+	for _, allow := range allowlists {
+		sanitizer.AllowElements(allow.Elements...)
+		for element, attrs := range allow.Attributes {
+			if element == "*" {
+				sanitizer.AllowAttrs(attrs...).Globally()
+			} else {
+				sanitizer.AllowAttrs(attrs...).OnElements(element)
+			}
+		}
+		for _, embed := range allow.Embeds {
+			embeds = append(embeds, "^"+regexp.QuoteMeta(embed))
+		}
+	}
+
+	if len(embeds) > 0 {
+		sanitizer.AllowAttrs("src").Matching(regexp.MustCompile(strings.Join(embeds, "|"))).OnElements("iframe")
+		sanitizer.AllowAttrs("title", "width", "height", "allow", "allowfullscreen", "loading", "referrerpolicy").OnElements("iframe")
+	}
+
+	return sanitizer
+}
+
+// stripped describes what sanitizer removes from rendered, one entry per distinct element
+// or attribute in the order they appear, with how many times it was removed
+func stripped(sanitizer *bluemonday.Policy, rendered string) []string {
+	var removals []string
+	counts := make(map[string]int)
+	seen := make(map[string][]string) // Removals by tag, as the same tags recur in highlighted code
+
+	tokenizer := html.NewTokenizer(strings.NewReader(rendered))
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		tag := tokenizer.Token()
+		source := tag.String()
+		removed, ok := seen[source]
+		if !ok {
+			removed = removedFrom(tag, sanitizer.Sanitize(source))
+			seen[source] = removed
+		}
+
+		for _, removal := range removed {
+			if counts[removal] == 0 {
+				removals = append(removals, removal)
+			}
+			counts[removal]++
+		}
+	}
+
+	for i, removal := range removals {
+		if counts[removal] > 1 {
+			removals[i] = fmt.Sprintf("%s (%d times)", removal, counts[removal])
+		}
+	}
+	return removals
+}
+
+// removedFrom compares a tag with what the sanitizer kept of it, naming URLs so authors can
+// tell which embed or link went missing
+func removedFrom(tag html.Token, kept string) []string {
+	describe := func(attr html.Attribute) string {
+		if attr.Key == "src" || attr.Key == "href" {
+			return fmt.Sprintf("%s=%q", attr.Key, attr.Val)
+		}
+		return "the " + attr.Key + " attribute"
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(kept))
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		var removed []string
+		keptTag := tokenizer.Token()
+		for _, attr := range tag.Attr {
+			if !slices.ContainsFunc(keptTag.Attr, func(a html.Attribute) bool { return a.Key == attr.Key }) {
+				removed = append(removed, fmt.Sprintf("%s from <%s>", describe(attr), tag.Data))
+			}
+		}
+		return removed
+	}
+
+	element := "<" + tag.Data
+	for _, attr := range tag.Attr {
+		if attr.Key == "src" || attr.Key == "href" {
+			element += " " + describe(attr)
+		}
+	}
+	return []string{element + ">"}
+}
@@ -0,0 +1,236 @@
+package content
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitizerPolicies(t *testing.T) {
+	config := &SanitizerConfig{
+		Directories: map[string]string{"blog/guests": PolicyStrict},
+		Authors:     map[string]string{"jane": PolicyTrusted, "sam": PolicyStandard},
+		Policies: map[string]Allowlist{
+			PolicyStrict:   {Elements: []string{"cite"}},
+			PolicyStandard: {Embeds: []string{"https://player.vimeo.com/video/"}},
+		},
+	}
+	loader := NewLoader(WithSanitizer(config))
+
+	// load reads a post through a store, which matches directory rules within its data
+	// directory, kept under parent
+	load := func(t *testing.T, parent, name, frontmatter, body string) *Content {
+		t.Helper()
+		dataDir := filepath.Join(t.TempDir(), parent)
+		writeContentFile(t, filepath.Join(dataDir, name), "---\ntitle: Test\ndate: 2024-01-15T10:00:00Z\n"+frontmatter+"---\n\n"+body)
+
+		store := NewStore(loader, dataDir, slog.New(slog.DiscardHandler))
+		if err := store.Load(); err != nil {
+			t.Fatal(err)
+		}
+		parsed, found := store.Post("post")
+		if !found {
+			t.Fatal("Expected the post to load")
+		}
+		return parsed
+	}
+
+	vimeo := `<iframe src="https://player.vimeo.com/video/1" title="Demo"></iframe>`
+	maps := `<iframe src="https://maps.example.com/embed" title="Map"></iframe>`
+
+	tests := []struct {
+		name        string
+		parent      string
+		file        string
+		frontmatter string
+		body        string
+		want, lose  []string
+		warnings    []string
+	}{
+		{
+			name:     "Standard keeps configured embeds and details",
+			file:     "blog/post.md",
+			body:     vimeo + "\n\n<details><summary>More</summary>Hidden</details>\n\n<cite>Cited</cite>\n",
+			want:     []string{`src="https://player.vimeo.com/video/1"`, "<details>", "<cite>"},
+			warnings: []string{},
+		},
+		{
+			name: "Standard drops style elements and unknown embeds",
+			file: "blog/post.md",
+			body: "<style>body { display: none }</style>\n\n" + maps + "\n",
+			lose: []string{"<style>", "display: none", "maps.example.com"},
+			warnings: []string{
+				"the standard sanitizer policy removed <style>",
+				`the standard sanitizer policy removed src="https://maps.example.com/embed" from <iframe>`,
+			},
+		},
+		{
+			name: "Strict applies to its directory",
+			file: "blog/guests/post.md",
+			body: vimeo + "\n\n<details><summary>More</summary>Hidden</details>\n\n[Site](https://example.com) and [another](https://example.org)\n",
+			want: []string{"Hidden", `rel="nofollow"`},
+			lose: []string{"<iframe", "<details>"},
+			warnings: []string{
+				`the strict sanitizer policy removed <iframe src="https://player.vimeo.com/video/1">`,
+				"the strict sanitizer policy removed <details>",
+				"the strict sanitizer policy removed <summary>",
+			},
+		},
+		{
+			name:   "Directory rules match within the data directory only",
+			parent: "blog/guests",
+			file:   "blog/post.md",
+			body:   vimeo + "\n",
+			want:   []string{`src="https://player.vimeo.com/video/1"`},
+		},
+		{
+			name:        "Authors win over directories",
+			file:        "blog/guests/post.md",
+			frontmatter: "author: jane\n",
+			body:        maps + "\n\n<p style=\"text-align: center\">Centered</p>\n",
+			want:        []string{`src="https://maps.example.com/embed"`, `style="text-align: center"`},
+		},
+		{
+			name:        "The strictest author's policy applies",
+			file:        "blog/post.md",
+			frontmatter: "authors: [jane, sam]\n",
+			body:        maps + "\n",
+			lose:        []string{"maps.example.com"},
+		},
+		{
+			name:     "Repeated removals are counted",
+			file:     "blog/post.md",
+			body:     "<p onclick=\"steal()\">One</p>\n\n<p onclick=\"steal()\">Two</p>\n",
+			warnings: []string{"the standard sanitizer policy removed the onclick attribute from <p> (2 times)"},
+		},
+		{
+			name:     "Mermaid diagrams pass without warnings",
+			file:     "blog/post.md",
+			body:     "```mermaid\ngraph TD\n  A --> B\n```\n",
+			want:     []string{`<pre class="mermaid">`},
+			lose:     []string{"<script"},
+			warnings: []string{},
+		},
+		{
+			name: "Task lists keep their checkboxes",
+			file: "blog/post.md",
+			body: "- [x] Done\n- [ ] Todo\n",
+			want: []string{`<input checked="" disabled="" type="checkbox"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := load(t, tt.parent, tt.file, tt.frontmatter, tt.body)
+			for _, want := range tt.want {
+				if !strings.Contains(parsed.HTML, want) {
+					t.Errorf("Expected %q in:\n%s", want, parsed.HTML)
+				}
+			}
+			for _, lose := range tt.lose {
+				if strings.Contains(parsed.HTML, lose) {
+					t.Errorf("Expected no %q in:\n%s", lose, parsed.HTML)
+				}
+			}
+			if tt.warnings == nil {
+				return
+			}
+
+			var warnings []string
+			for _, warning := range parsed.Warnings {
+				warnings = append(warnings, warning.String())
+			}
+			if strings.Join(warnings, "\n") != strings.Join(tt.warnings, "\n") {
+				t.Errorf("Expected warnings:\n%s\ngot:\n%s", strings.Join(tt.warnings, "\n"), strings.Join(warnings, "\n"))
+			}
+		})
+	}
+}
+
+func TestSanitizerConfig_Policy(t *testing.T) {
+	config := &SanitizerConfig{
+		Default:     PolicyStandard,
+		Directories: map[string]string{"blog/guests/": PolicyStrict, "/blog/guests/vetted": PolicyTrusted},
+	}
+
+	tests := []struct {
+		dataPath string
+		want     string
+	}{
+		{"blog/guests/post.md", PolicyStrict},
+		{"blog/guests/2024/post.md", PolicyStrict},
+		{"blog/guests/vetted/post.md", PolicyTrusted},
+		{"blog/guestbook/post.md", PolicyStandard},
+		{"pages/blog/guests/post.md", PolicyStandard},
+		{"blog/post.md", PolicyStandard},
+		{"", PolicyStandard},
+	}
+	for _, tt := range tests {
+		if got := config.policy(tt.dataPath, nil); got != tt.want {
+			t.Errorf("policy(%q) = %s, expected %s", tt.dataPath, got, tt.want)
+		}
+	}
+}
+
+func TestLoadSanitizerConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  string
+	}{
+		{
+			name: "Valid",
+			body: "default: strict\ndirectories:\n  blog/guests: strict\nauthors:\n  jane: trusted\npolicies:\n  standard:\n    elements: [cite]\n    attributes:\n      \"*\": [lang]\n    embeds: [\"https://player.vimeo.com/video/\"]\n",
+		},
+		{
+			name: "Unknown policy for an author",
+			body: "authors:\n  jane: lenient\n",
+			err:  `unknown policy "lenient" for author "jane"`,
+		},
+		{
+			name: "Unknown allowlist",
+			body: "policies:\n  lenient:\n    elements: [cite]\n",
+			err:  `unknown policy "lenient", expected one of strict, standard, trusted`,
+		},
+		{
+			name: "Scripts",
+			body: "policies:\n  trusted:\n    elements: [script]\n",
+			err:  `policy "trusted" cannot allow <script> elements`,
+		},
+		{
+			name: "Event handlers",
+			body: "policies:\n  trusted:\n    attributes:\n      img: [onload]\n",
+			err:  `policy "trusted" cannot allow the event handler onload on img`,
+		},
+		{
+			name: "Embeds without a host",
+			body: "policies:\n  standard:\n    embeds: [\"https://\"]\n",
+			err:  `policy "standard" embed "https://" must be an https:// URL prefix with a host`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "sanitizer.yaml")
+			if err := os.WriteFile(path, []byte(tt.body), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := LoadSanitizerConfig(path)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if config.policy("", nil) != PolicyStrict || config.policy("blog/guests/post.md", []string{"jane"}) != PolicyTrusted {
+					t.Errorf("Expected the rules to be loaded, got %+v", config)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package content
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldType is the type of a frontmatter field declared in a schema
+type FieldType string
+
+const (
+	FieldString FieldType = "string"
+	FieldBool   FieldType = "bool"
+	FieldInt    FieldType = "int"
+	FieldFloat  FieldType = "float" // Integers are accepted too
+	FieldDate   FieldType = "date"  // A YAML timestamp, or a string in RFC 3339 or 2006-01-02 format
+	FieldList   FieldType = "list"
+	FieldMap    FieldType = "map"
+	FieldAny    FieldType = "any"
+)
+
+// fieldTypes lists every known field type
+var fieldTypes = []FieldType{FieldString, FieldBool, FieldInt, FieldFloat, FieldDate, FieldList, FieldMap, FieldAny}
+
+// FieldSchema describes a frontmatter field
+type FieldSchema struct {
+	Type        FieldType `yaml:"type"`
+	Required    bool      `yaml:"required"`
+	Default     any       `yaml:"default"` // Only for custom fields, set in Params when the field is missing
+	Description string    `yaml:"description"`
+}
+
+// Schema declares the frontmatter fields a theme relies on, usually custom fields kept in
+// Content.Params, but built-in fields can be made required or type checked too.
+// Themes declare it in frontmatter.yaml, for example
+//
+//	fields:
+//	  subtitle:
+//	    type: string
+//	  featured:
+//	    type: bool
+//	    default: false
+//	  description:
+//	    type: string
+//	    required: true
+type Schema struct {
+	Fields map[string]FieldSchema `yaml:"fields"`
+}
+
+// builtinFields holds the frontmatter keys decoded into Frontmatter, everything else goes into Params
+var builtinFields = yamlKeys(reflect.TypeFor[Frontmatter]())
+
+func yamlKeys(t reflect.Type) map[string]bool {
+	keys := make(map[string]bool, t.NumField())
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// WithSchema checks the frontmatter of every post and page against schema and fills in
+// the defaults it declares
+func WithSchema(schema *Schema) ParserOption {
+	return func(c *parserConfig) {
+		c.schema = schema
+	}
+}
+
+// LoadSchema reads a frontmatter schema from a YAML file and checks that it is well formed
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema Schema
+	err = yaml.Unmarshal(data, &schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse frontmatter schema %s: %w", path, err)
+	}
+
+	for _, name := range schema.names() {
+		field := schema.Fields[name]
+		if field.Type == "" {
+			field.Type = FieldAny
+			schema.Fields[name] = field
+		}
+
+		switch {
+		case !containsType(field.Type):
+			return nil, fmt.Errorf("frontmatter schema %s: field %q has unknown type %q", path, name, field.Type)
+		case field.Default != nil && builtinFields[name]:
+			return nil, fmt.Errorf("frontmatter schema %s: built-in field %q cannot have a default", path, name)
+		case field.Default != nil && field.Required:
+			return nil, fmt.Errorf("frontmatter schema %s: required field %q cannot have a default", path, name)
+		}
+
+		if field.Default != nil {
+			field.Default, err = field.convert(field.Default)
+			if err != nil {
+				return nil, fmt.Errorf("frontmatter schema %s: default of %q %w", path, name, err)
+			}
+			schema.Fields[name] = field
+		}
+	}
+
+	return &schema, nil
+}
+
+func containsType(t FieldType) bool {
+	for _, known := range fieldTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// names returns the declared field names in order, so problems are reported consistently
+func (s *Schema) names() []string {
+	names := make([]string, 0, len(s.Fields))
+	for name := range s.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply checks the raw frontmatter against the schema and sets the defaults of missing
+// custom fields in params. Every problem found is reported in a single error.
+func (s *Schema) apply(raw, params map[string]any) error {
+	if s == nil {
+		return nil
+	}
+
+	var problems []string
+	for _, name := range s.names() {
+		field := s.Fields[name]
+
+		value, present := raw[name]
+		if !present || value == nil {
+			if field.Required {
+				problems = append(problems, fmt.Sprintf("%q is required", name))
+			} else if field.Default != nil {
+				params[name] = field.Default
+			}
+			continue
+		}
+
+		converted, err := field.convert(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%q %s", name, err))
+			continue
+		}
+		if !builtinFields[name] {
+			params[name] = converted
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid frontmatter: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// convert checks that a decoded YAML value has the field's type, parsing dates given as strings
+func (f FieldSchema) convert(value any) (any, error) {
+	ok := false
+	switch f.Type {
+	case FieldString:
+		_, ok = value.(string)
+	case FieldBool:
+		_, ok = value.(bool)
+	case FieldInt:
+		_, ok = value.(int)
+	case FieldFloat:
+		switch v := value.(type) {
+		case float64:
+			ok = true
+		case int:
+			return float64(v), nil
+		}
+	case FieldDate:
+		switch v := value.(type) {
+		case time.Time:
+			ok = true
+		case string:
+			for _, layout := range []string{time.RFC3339, time.DateOnly} {
+				if t, err := time.Parse(layout, v); err == nil {
+					return t, nil
+				}
+			}
+		}
+	case FieldList:
+		_, ok = value.([]any)
+	case FieldMap:
+		_, ok = value.(map[string]any)
+	case FieldAny:
+		ok = true
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("must be a %s, got %s", f.Type, describeValue(value))
+	}
+	return value, nil
+}
+
+// describeValue names the YAML type of a decoded value for error messages
+func describeValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case bool, int, float64:
+		return fmt.Sprintf("%T %v", v, v)
+	case time.Time:
+		return "date"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "map"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,140 @@
+package content
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSchema(t *testing.T, body string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "frontmatter.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMarkdownParser_Params(t *testing.T) {
+	parsed, err := NewMarkdownParser().Parse([]byte("---\ntitle: Test\nsubtitle: More\nfeatured: true\nhero_color: \"#fff\"\nrank: 3\n---\n\nBody"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Frontmatter.Title != "Test" {
+		t.Errorf("Expected title Test, got %q", parsed.Frontmatter.Title)
+	}
+	if len(parsed.Params) != 4 || parsed.Params["subtitle"] != "More" || parsed.Params["featured"] != true || parsed.Params["hero_color"] != "#fff" || parsed.Params["rank"] != 3 {
+		t.Errorf("Expected only the unknown fields in Params, got %v", parsed.Params)
+	}
+	if _, found := parsed.Params["title"]; found {
+		t.Error("Expected built-in fields to stay out of Params")
+	}
+
+	parsed, err = NewMarkdownParser().Parse([]byte("No frontmatter"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Params == nil {
+		t.Error("Expected empty Params without frontmatter")
+	}
+}
+
+func TestLoadSchema(t *testing.T) {
+	t.Run("Loads fields and converts defaults", func(t *testing.T) {
+		schema, err := LoadSchema(writeSchema(t, "fields:\n  rating:\n    type: float\n    default: 1\n  extra:\n    description: Anything\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if schema.Fields["rating"].Default != 1.0 {
+			t.Errorf("Expected the default to be a float, got %#v", schema.Fields["rating"].Default)
+		}
+		if schema.Fields["extra"].Type != FieldAny {
+			t.Errorf("Expected fields without a type to accept anything, got %q", schema.Fields["extra"].Type)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		schema string
+		err    string
+	}{
+		{"Unknown type", "fields:\n  featured:\n    type: boolean\n", `field "featured" has unknown type "boolean"`},
+		{"Default on a built-in field", "fields:\n  draft:\n    type: bool\n    default: true\n", `built-in field "draft" cannot have a default`},
+		{"Default on a required field", "fields:\n  subtitle:\n    type: string\n    required: true\n    default: x\n", `required field "subtitle" cannot have a default`},
+		{"Default of the wrong type", "fields:\n  featured:\n    type: bool\n    default: \"no\"\n", `default of "featured" must be a bool, got string "no"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadSchema(writeSchema(t, tt.schema))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestMarkdownParser_Schema(t *testing.T) {
+	schema, err := LoadSchema(writeSchema(t, "fields:\n"+
+		"  subtitle:\n    type: string\n    required: true\n"+
+		"  featured:\n    type: bool\n    default: false\n"+
+		"  launch:\n    type: date\n"+
+		"  ratio:\n    type: float\n"+
+		"  description:\n    type: string\n    required: true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser := NewMarkdownParser(WithSchema(schema))
+
+	t.Run("Applies defaults and converts values", func(t *testing.T) {
+		parsed, err := parser.Parse([]byte("---\ntitle: Test\ndescription: Desc\nsubtitle: Sub\nlaunch: \"2024-05-01\"\nratio: 2\n---\n\nBody"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed.Params["featured"] != false {
+			t.Errorf("Expected featured to default to false, got %#v", parsed.Params["featured"])
+		}
+		if launch, ok := parsed.Params["launch"].(time.Time); !ok || !launch.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected launch to be parsed as a date, got %#v", parsed.Params["launch"])
+		}
+		if parsed.Params["ratio"] != 2.0 {
+			t.Errorf("Expected ratio to be a float, got %#v", parsed.Params["ratio"])
+		}
+		if _, found := parsed.Params["description"]; found {
+			t.Error("Expected built-in fields to stay out of Params")
+		}
+	})
+
+	t.Run("Reports every problem", func(t *testing.T) {
+		_, err := parser.Parse([]byte("---\ntitle: Test\nfeatured: \"yes\"\nlaunch: soon\n---\n\nBody"))
+		want := `invalid frontmatter: "description" is required; "featured" must be a bool, got string "yes"; "launch" must be a date, got string "soon"; "subtitle" is required`
+		if err == nil || err.Error() != want {
+			t.Errorf("Expected error %q, got %v", want, err)
+		}
+	})
+}
+
+func TestStore_Schema(t *testing.T) {
+	schema, err := LoadSchema(writeSchema(t, "fields:\n  subtitle:\n    type: string\n    required: true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataDir := t.TempDir()
+	store := NewStore(NewLoader(WithSchema(schema)), dataDir, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	path := filepath.Join(dataDir, "blog", "first.md")
+	writeContentFile(t, path, "---\ntitle: First\ndate: 2024-01-15T10:00:00Z\n---\n\nHello")
+
+	err = store.Load()
+	if err == nil || !strings.Contains(err.Error(), path) || !strings.Contains(err.Error(), `"subtitle" is required`) {
+		t.Errorf("Expected the load to fail naming the file and field, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package content
+
+import (
+	"vellum.forge/internal/search"
+)
+
+// SearchResult is a published post or page matching a search query
+type SearchResult struct {
+	Content *Content
+	Kind    Kind
+	URL     string
+	Score   float64 // Relevance, higher is better
+	Snippet string  // HTML-escaped excerpt with matching words wrapped in <mark>
+}
+
+// IsPost reports whether the result is a blog post rather than a page
+func (r *SearchResult) IsPost() bool {
+	return r.Kind == KindPost
+}
+
+// searchEntry is an indexed document's content and where it is served
+type searchEntry struct {
+	content *Content
+	kind    Kind
+	url     string
+}
+
+// buildSearchIndex indexes the titles, tags, descriptions and text of published posts and pages
+func buildSearchIndex(urlPrefix string, posts, pages []*Content) (*search.Index, []searchEntry) {
+	entries := make([]searchEntry, 0, len(posts)+len(pages))
+	for _, post := range posts {
+		entries = append(entries, searchEntry{post, KindPost, urlPrefix + "/blog/" + post.Frontmatter.Slug})
+	}
+	for _, page := range pages {
+		entries = append(entries, searchEntry{page, KindPage, urlPrefix + "/" + page.Frontmatter.Slug})
+	}
+
+	docs := make([]search.Document, len(entries))
+	for i, entry := range entries {
+		docs[i] = search.Document{
+			Title:       entry.content.Frontmatter.Title,
+			Description: entry.content.Frontmatter.Description,
+			Tags:        entry.content.Frontmatter.Tags,
+			Body:        plainText(entry.content.HTML),
+		}
+	}
+
+	return search.NewIndex(docs), entries
+}
+
+// Search returns up to limit published posts and pages matching every word of the query, best first.
+// A limit that is not positive returns up to search.DefaultLimit results.
+func (s *Store) Search(query string, limit int) []*SearchResult {
+	s.rlock()
+	defer s.mu.RUnlock()
+
+	if s.searchIndex == nil {
+		return nil
+	}
+
+	var results []*SearchResult
+	for _, hit := range s.searchIndex.Search(query, limit) {
+		entry := s.searchEntries[hit.Doc]
+		results = append(results, &SearchResult{
+			Content: entry.content,
+			Kind:    entry.kind,
+			URL:     entry.url,
+			Score:   hit.Score,
+			Snippet: hit.Snippet,
+		})
+	}
+	return results
+}
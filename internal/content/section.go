@@ -0,0 +1,131 @@
+package content
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SectionIndex is the name, without extension, of the file that holds a section's landing page,
+// so data/pages/docs/_index.md is served at /docs above the pages in data/pages/docs
+const SectionIndex = "_index"
+
+// nestPageSlug gives a page in a subdirectory of pagesDir a slug under that directory, such as
+// docs/install for pages/docs/install.md, and a section's _index.md the slug of its directory.
+// A slug set in frontmatter replaces the last segment only. File names may end in a language
+// suffix, such as _index.fr.md.
+func nestPageSlug(content *Content, pagesDir string, languages Languages) {
+	rel, err := filepath.Rel(pagesDir, content.Path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return
+	}
+	rel = filepath.ToSlash(rel)
+
+	dir := path.Dir(rel)
+	stem, _ := languages.split(strings.TrimSuffix(path.Base(rel), path.Ext(rel)))
+	slug := content.Frontmatter.Slug // The loader sets the file's stem unless frontmatter has one
+
+	if stem == SectionIndex {
+		content.IsSection = true
+		if slug == stem {
+			slug = strings.TrimPrefix(path.Base(dir), ".")
+		}
+		dir = path.Dir(dir)
+	}
+
+	if dir != "." && slug != "" {
+		slug = dir + "/" + slug
+	}
+	content.Frontmatter.Slug = slug
+}
+
+// pageSlugForFile returns the slug of the page in a file within pagesDir, as nestPageSlug
+// would set it unless the frontmatter sets one
+func pageSlugForFile(pagesDir, filePath string) string {
+	base := filepath.Base(filePath)
+	content := &Content{Path: filePath}
+	content.Frontmatter.Slug = strings.TrimSuffix(base, filepath.Ext(base))
+	nestPageSlug(content, pagesDir, nil)
+	return content.Frontmatter.Slug
+}
+
+// parentSlugs returns the slugs above a page's, nearest first, so docs/guides/setup has
+// docs/guides and docs
+func parentSlugs(slug string) []string {
+	var parents []string
+	for i := strings.LastIndex(slug, "/"); i > 0; i = strings.LastIndex(slug, "/") {
+		slug = slug[:i]
+		parents = append(parents, slug)
+	}
+	return parents
+}
+
+// linkPageTree fills in the parent, children and siblings of every page in pages, drafts
+// included so previews show them. A page's parent is the nearest published page above it, so
+// pages in a directory without an _index.md hang off the section above. Only published pages
+// are listed as children and siblings.
+func linkPageTree(pages map[string]*Content, pageList []*Content) {
+	published := make(map[string]*Content, len(pageList))
+	for _, page := range pageList {
+		published[page.Frontmatter.Slug] = page
+	}
+
+	parentOf := func(page *Content) *Content {
+		for _, slug := range parentSlugs(page.Frontmatter.Slug) {
+			if parent, found := published[slug]; found {
+				return parent
+			}
+		}
+		return nil
+	}
+
+	children := make(map[*Content][]*Content) // Top-level pages are keyed by nil
+	for _, page := range pageList {
+		parent := parentOf(page)
+		children[parent] = append(children[parent], page)
+	}
+	for _, list := range children {
+		sortByWeight(list)
+	}
+
+	for _, page := range pages {
+		page.Parent = parentOf(page)
+		page.Children = children[page]
+
+		page.Siblings, page.PrevSibling, page.NextSibling = nil, nil, nil
+		siblings := children[page.Parent]
+		for i, sibling := range siblings {
+			if sibling != page {
+				page.Siblings = append(page.Siblings, sibling)
+				continue
+			}
+			if i > 0 {
+				page.PrevSibling = siblings[i-1]
+			}
+			if i < len(siblings)-1 {
+				page.NextSibling = siblings[i+1]
+			}
+		}
+	}
+}
+
+// sortByWeight orders pages by weight, then title
+func sortByWeight(pages []*Content) {
+	sort.SliceStable(pages, func(i, j int) bool {
+		a, b := pages[i].Frontmatter, pages[j].Frontmatter
+		if a.Weight != b.Weight {
+			return a.Weight < b.Weight
+		}
+		return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+	})
+}
+
+// Breadcrumbs returns the pages above this one, top-level first
+func (c *Content) Breadcrumbs() []*Content {
+	var crumbs []*Content
+	for parent := c.Parent; parent != nil; parent = parent.Parent {
+		crumbs = append([]*Content{parent}, crumbs...)
+	}
+	return crumbs
+}
@@ -0,0 +1,92 @@
+package content
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func pageSlugs(pages []*Content) []string {
+	var slugs []string
+	for _, page := range pages {
+		slugs = append(slugs, page.Frontmatter.Slug)
+	}
+	return slugs
+}
+
+func TestStore_Sections(t *testing.T) {
+	store, dataDir := newTestStore(t)
+
+	writeContentFile(t, filepath.Join(dataDir, "pages", "install.md"), "---\ntitle: Install\n---\n\nTop-level install")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "docs", "_index.md"), "---\ntitle: Docs\n---\n\nDocs home")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "docs", "install.md"), "---\ntitle: Installing\nweight: 1\n---\n\nSee [[configure]]")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "docs", "configure.md"), "---\ntitle: Configure\nweight: 2\n---\n\nConfigure")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "docs", "faq.md"), "---\ntitle: FAQ\nweight: 2\nslug: questions\n---\n\nFAQ")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "docs", "draft.md"), "---\ntitle: Draft\ndraft: true\n---\n\nDraft")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "docs", "guides", "setup.md"), "---\ntitle: Setup\nweight: 3\n---\n\nSee [[pages/docs/_index]] and [[install]]")
+
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	top, found := store.Page("install")
+	if !found || top.Frontmatter.Title != "Install" {
+		t.Fatalf("Expected the top-level install page, got %v", top)
+	}
+
+	docs, found := store.Page("docs")
+	if !found || !docs.IsSection || docs.Parent != nil {
+		t.Fatalf("Expected docs to be a top-level section, got %+v", docs)
+	}
+	if got, want := pageSlugs(docs.Children), []string{"docs/install", "docs/configure", "docs/questions", "docs/guides/setup"}; !slices.Equal(got, want) {
+		t.Errorf("Expected children %v, got %v", want, got)
+	}
+
+	install, found := store.Page("docs/install")
+	if !found || install.Parent != docs {
+		t.Fatalf("Expected docs/install under docs, got %+v", install)
+	}
+	if install.PrevSibling != nil || install.NextSibling == nil || install.NextSibling.Frontmatter.Slug != "docs/configure" {
+		t.Errorf("Expected docs/configure after docs/install, got %v and %v", install.PrevSibling, install.NextSibling)
+	}
+	if got, want := pageSlugs(install.Siblings), []string{"docs/configure", "docs/questions", "docs/guides/setup"}; !slices.Equal(got, want) {
+		t.Errorf("Expected siblings %v, got %v", want, got)
+	}
+	if got, want := install.Links, []string{"/docs/configure"}; !slices.Equal(got, want) {
+		t.Errorf("Expected wiki-links to resolve within sections, got %v", got)
+	}
+
+	// Without a docs/guides/_index.md, the page hangs off the nearest section above it
+	setup, found := store.Page("docs/guides/setup")
+	if !found || setup.Parent != docs {
+		t.Fatalf("Expected docs/guides/setup under docs, got %+v", setup)
+	}
+	if got, want := pageSlugs(setup.Breadcrumbs()), []string{"docs"}; !slices.Equal(got, want) {
+		t.Errorf("Expected breadcrumbs %v, got %v", want, got)
+	}
+	if got, want := setup.Links, []string{"/docs", "/install"}; !slices.Equal(got, want) {
+		t.Errorf("Expected links by name to prefer the page nearest the top, got %v", got)
+	}
+
+	draft, found := store.PreviewPage("docs/draft")
+	if !found || draft.Parent != docs {
+		t.Errorf("Expected drafts to have a parent for previews, got %+v", draft)
+	}
+}
+
+func TestLoader_LoadPage(t *testing.T) {
+	dataDir := t.TempDir()
+	writeContentFile(t, filepath.Join(dataDir, "pages", "install.md"), "---\ntitle: Install\n---\n\nTop-level")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "docs", "install.md"), "---\ntitle: Installing\n---\n\nNested")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "docs", "_index.md"), "---\ntitle: Docs\n---\n\nDocs")
+
+	for slug, title := range map[string]string{"install": "Install", "docs/install": "Installing", "docs": "Docs"} {
+		page, _, err := NewLoader().LoadPage(dataDir, slug)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Frontmatter.Title != title || page.Frontmatter.Slug != slug {
+			t.Errorf("Expected %q for %s, got %q at %s", title, slug, page.Frontmatter.Title, page.Frontmatter.Slug)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// ShortcodeRenderer renders shortcodes, usually from theme templates
+type ShortcodeRenderer interface {
+	// HasShortcode reports whether a shortcode with the given name can be rendered
+	HasShortcode(name string) bool
+
+	// RenderShortcode writes the HTML for the named shortcode, with call, a *ShortcodeCall,
+	// as its template data
+	RenderShortcode(w io.Writer, name string, call any) error
+}
+
+// WithShortcodes renders {{< name args >}} shortcodes with r. Without a renderer
+// shortcode tags are dropped, keeping the content between paired tags.
+func WithShortcodes(r ShortcodeRenderer) ParserOption {
+	return func(c *parserConfig) {
+		c.shortcodes = r
+	}
+}
+
+// ShortcodeCall is a shortcode as written in markdown, the data its template is rendered with.
+//
+// A shortcode takes up a whole line and is either self-closing
+//
+//	{{< youtube dQw4w9WgXcQ >}}
+//	{{< figure src="/images/cat.jpg" caption="A cat" >}}
+//
+// or paired with a closing tag, with markdown in between
+//
+//	{{< callout type="warn" >}}
+//	Back up your **data** first.
+//	{{< /callout >}}
+type ShortcodeCall struct {
+	Name   string
+	Args   []string          // Positional arguments
+	Params map[string]string // Named arguments
+	Inner  string            // Rendered HTML between paired tags
+	Line   int               // Line of the opening tag in the source file
+}
+
+// Arg returns the positional argument at index i, or an empty string
+func (c *ShortcodeCall) Arg(i int) string {
+	if i < 0 || i >= len(c.Args) {
+		return ""
+	}
+	return c.Args[i]
+}
+
+// Get returns the named argument, or an empty string
+func (c *ShortcodeCall) Get(name string) string {
+	return c.Params[name]
+}
+
+// shortcodeNamePattern matches the names shortcodes can have, which also keeps them
+// from escaping the theme's shortcodes directory
+var shortcodeNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// KindShortcode is the goldmark node kind of shortcodes
+var KindShortcode = ast.NewNodeKind("Shortcode")
+
+// shortcodeNode is a shortcode block, holding the markdown between paired tags as children
+type shortcodeNode struct {
+	ast.BaseBlock
+	call   *ShortcodeCall
+	paired bool
+}
+
+func (n *shortcodeNode) Kind() ast.NodeKind {
+	return KindShortcode
+}
+
+func (n *shortcodeNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Name": n.call.Name}, nil)
+}
+
+// shortcodeExtension registers the shortcode block parser and renderer with goldmark
+type shortcodeExtension struct {
+	shortcodes ShortcodeRenderer
+}
+
+func (e *shortcodeExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithBlockParsers(
+		util.Prioritized(&shortcodeParser{shortcodes: e.shortcodes}, 150),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&shortcodeRenderer{shortcodes: e.shortcodes, markdown: m}, 150),
+	))
+}
+
+// shortcodeParser parses shortcode tags at the start of a block
+type shortcodeParser struct {
+	shortcodes ShortcodeRenderer
+}
+
+func (p *shortcodeParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (p *shortcodeParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+
+	name, args, closing, selfClosing, ok := parseShortcodeTag(line[pos:])
+	if !ok || closing {
+		return nil, parser.NoChildren
+	}
+
+	source := reader.Source()
+	call := &ShortcodeCall{
+		Name:   name,
+		Params: make(map[string]string),
+		Line:   lineNumber(source, segment.Start),
+	}
+	for _, arg := range args {
+		if key, value, named := strings.Cut(arg, "="); named {
+			call.Params[key] = unquote(value)
+		} else {
+			call.Args = append(call.Args, unquote(arg))
+		}
+	}
+
+	if p.shortcodes != nil && !p.shortcodes.HasShortcode(name) {
+		addWarning(pc, call.Line, "unknown shortcode %q", name)
+	}
+
+	node := &shortcodeNode{call: call}
+	reader.AdvanceToEOL()
+
+	if !selfClosing && hasClosingTag(source[segment.Stop:], name) {
+		node.paired = true
+		return node, parser.HasChildren
+	}
+	return node, parser.NoChildren
+}
+
+func (p *shortcodeParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*shortcodeNode)
+	if !n.paired {
+		return parser.Close
+	}
+
+	line, _ := reader.PeekLine()
+	name, _, closing, _, ok := parseShortcodeTag(bytes.TrimLeft(line, " \t"))
+	if ok && closing && name == n.call.Name {
+		reader.AdvanceToEOL()
+		return parser.Close
+	}
+
+	return parser.Continue | parser.HasChildren
+}
+
+func (p *shortcodeParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (p *shortcodeParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p *shortcodeParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// shortcodeRenderer renders shortcode nodes through the ShortcodeRenderer
+type shortcodeRenderer struct {
+	shortcodes ShortcodeRenderer
+	markdown   goldmark.Markdown // renders the markdown between paired tags into Inner
+}
+
+func (r *shortcodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindShortcode, r.render)
+}
+
+func (r *shortcodeRenderer) render(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*shortcodeNode)
+
+	// Unknown shortcodes were reported while parsing, only their content is kept
+	if r.shortcodes == nil || !r.shortcodes.HasShortcode(n.call.Name) {
+		return ast.WalkContinue, nil
+	}
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	var inner bytes.Buffer
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if err := r.markdown.Renderer().Render(&inner, source, child); err != nil {
+			return ast.WalkStop, err
+		}
+	}
+	n.call.Inner = inner.String()
+
+	if err := r.shortcodes.RenderShortcode(w, n.call.Name, n.call); err != nil {
+		return ast.WalkStop, fmt.Errorf("shortcode %q on line %d: %w", n.call.Name, n.call.Line, err)
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// parseShortcodeTag parses a line holding nothing but a shortcode tag: {{< name args >}},
+// {{< name args />}} or {{< /name >}}. Arguments are separated by spaces and may be quoted.
+func parseShortcodeTag(line []byte) (name string, args []string, closing, selfClosing, ok bool) {
+	tag := strings.TrimSpace(string(line))
+	inside, found := strings.CutPrefix(tag, "{{<")
+	if !found {
+		return "", nil, false, false, false
+	}
+	inside, found = strings.CutSuffix(inside, ">}}")
+	if !found {
+		return "", nil, false, false, false
+	}
+
+	inside = strings.TrimSpace(inside)
+	if rest, found := strings.CutSuffix(inside, "/"); found {
+		inside, selfClosing = strings.TrimSpace(rest), true
+	}
+	if rest, found := strings.CutPrefix(inside, "/"); found {
+		inside, closing = strings.TrimSpace(rest), true
+	}
+
+	fields, ok := splitShortcodeArgs(inside)
+	if !ok || len(fields) == 0 || !shortcodeNamePattern.MatchString(fields[0]) {
+		return "", nil, false, false, false
+	}
+	if closing && (len(fields) > 1 || selfClosing) {
+		return "", nil, false, false, false
+	}
+
+	return fields[0], fields[1:], closing, selfClosing, true
+}
+
+// splitShortcodeArgs splits s on spaces outside of double quotes, keeping the quotes.
+// It fails if a quote is left open.
+func splitShortcodeArgs(s string) ([]string, bool) {
+	var fields []string
+	var field strings.Builder
+	quoted, escaped := false, false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t'):
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+			continue
+		}
+		field.WriteRune(r)
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+
+	return fields, !quoted
+}
+
+// unquote strips the double quotes around a shortcode argument and unescapes what they held
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(s)
+}
+
+// hasClosingTag reports whether source has a line closing the shortcode name
+func hasClosingTag(source []byte, name string) bool {
+	for len(source) > 0 {
+		line := source
+		if i := bytes.IndexByte(source, '\n'); i >= 0 {
+			line, source = source[:i], source[i+1:]
+		} else {
+			source = nil
+		}
+
+		tagName, _, closing, _, ok := parseShortcodeTag(line)
+		if ok && closing && tagName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// lineNumber returns the 1-based line of the byte at offset in source
+func lineNumber(source []byte, offset int) int {
+	return bytes.Count(source[:offset], []byte{'\n'}) + 1
+}
@@ -0,0 +1,139 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"testing"
+)
+
+// testShortcodes renders every call as a div listing its arguments, caption, type and inner content
+type testShortcodes map[string]bool
+
+func (s testShortcodes) HasShortcode(name string) bool {
+	return s[name]
+}
+
+func (s testShortcodes) RenderShortcode(w io.Writer, name string, data any) error {
+	call := data.(*ShortcodeCall)
+	if name == "broken" {
+		return errors.New("template failed")
+	}
+	_, err := fmt.Fprintf(w, `<div class="sc-%s">%s|%s|%s|%s</div>`,
+		call.Name, html.EscapeString(strings.Join(call.Args, ",")), html.EscapeString(call.Get("caption")), call.Get("type"), call.Inner)
+	return err
+}
+
+func TestMarkdownParser_Shortcodes(t *testing.T) {
+	shortcodes := testShortcodes{"youtube": true, "figure": true, "callout": true, "broken": true}
+	parser := NewMarkdownParser(WithShortcodes(shortcodes))
+
+	t.Run("Renders self-closing shortcodes with positional and named arguments", func(t *testing.T) {
+		parsed, err := parser.Parse([]byte("Intro\n{{< youtube dQw4w9WgXcQ >}}\n\n{{< figure src=\"/a.png\" caption=\"A \\\"quoted\\\" cat\" />}}\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, want := range []string{
+			"<p>Intro</p>",
+			`<div class="sc-youtube">dQw4w9WgXcQ|||</div>`,
+			`<div class="sc-figure">|A &#34;quoted&#34; cat||</div>`,
+		} {
+			if !strings.Contains(parsed.HTML, want) {
+				t.Errorf("Expected HTML to contain %s, got %s", want, parsed.HTML)
+			}
+		}
+		if len(parsed.Warnings) != 0 {
+			t.Errorf("Expected no warnings, got %v", parsed.Warnings)
+		}
+	})
+
+	t.Run("Renders markdown between paired tags as the inner content", func(t *testing.T) {
+		parsed, err := parser.Parse([]byte("{{< callout type=\"warn\" >}}\nBack up **first**.\n\n- one\n{{< /callout >}}\nAfter\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := `<div class="sc-callout">||warn|<p>Back up <strong>first</strong>.</p>` + "\n<ul>\n<li>one</li>\n</ul>\n</div>"
+		if !strings.Contains(parsed.HTML, want) {
+			t.Errorf("Expected HTML to contain %s, got %s", want, parsed.HTML)
+		}
+		if !strings.Contains(parsed.HTML, "<p>After</p>") {
+			t.Errorf("Expected content after the closing tag to be rendered normally, got %s", parsed.HTML)
+		}
+	})
+
+	t.Run("Reports unknown shortcodes with their line and keeps their content", func(t *testing.T) {
+		parsed, err := parser.Parse([]byte("---\ntitle: Test\n---\n\n{{< gallery dir=\"cats\" >}}\nSome **text**\n{{< /gallery >}}\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(parsed.Warnings) != 1 || parsed.Warnings[0].Line != 5 || !strings.Contains(parsed.Warnings[0].Message, `"gallery"`) {
+			t.Errorf("Expected one warning for line 5, got %v", parsed.Warnings)
+		}
+		if strings.Contains(parsed.HTML, "{{&lt;") || !strings.Contains(parsed.HTML, "<p>Some <strong>text</strong></p>") {
+			t.Errorf("Expected tags dropped and content kept, got %s", parsed.HTML)
+		}
+	})
+
+	t.Run("Leaves shortcodes in code and mid-paragraph alone", func(t *testing.T) {
+		parsed, err := parser.Parse([]byte("```\n{{< youtube abc >}}\n```\n\nSee {{< youtube abc >}} here\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Contains(parsed.HTML, "sc-youtube") {
+			t.Errorf("Expected no rendered shortcodes, got %s", parsed.HTML)
+		}
+		if strings.Count(parsed.HTML, "{{&lt; youtube abc &gt;}}") != 2 {
+			t.Errorf("Expected both tags kept as text, got %s", parsed.HTML)
+		}
+	})
+
+	t.Run("Fails with the line of a shortcode whose template fails", func(t *testing.T) {
+		_, err := parser.Parse([]byte("Text\n\n{{< broken >}}\n"))
+		if err == nil || !strings.Contains(err.Error(), "line 3") {
+			t.Errorf("Expected an error mentioning line 3, got %v", err)
+		}
+	})
+
+	t.Run("Drops tags without warnings when no renderer is configured", func(t *testing.T) {
+		parsed, err := NewMarkdownParser().Parse([]byte("{{< callout >}}\nKept\n{{< /callout >}}\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.TrimSpace(parsed.HTML) != "<p>Kept</p>" || len(parsed.Warnings) != 0 {
+			t.Errorf("Unexpected result %q with warnings %v", parsed.HTML, parsed.Warnings)
+		}
+	})
+}
+
+func TestParseShortcodeTag(t *testing.T) {
+	tests := []struct {
+		line    string
+		name    string
+		args    []string
+		closing bool
+		ok      bool
+	}{
+		{`{{< youtube abc >}}`, "youtube", []string{"abc"}, false, true},
+		{`  {{<figure src="a b.png" caption="x" >}}  `, "figure", []string{`src="a b.png"`, `caption="x"`}, false, true},
+		{`{{< /callout >}}`, "callout", []string{}, true, true},
+		{`{{< /callout extra >}}`, "", nil, false, false},
+		{`{{< figure caption="open >}}`, "", nil, false, false},
+		{`{{< ../etc/passwd >}}`, "", nil, false, false},
+		{`{{< youtube abc >}} trailing`, "", nil, false, false},
+		{`{{ .Title }}`, "", nil, false, false},
+	}
+
+	for _, tt := range tests {
+		name, args, closing, _, ok := parseShortcodeTag([]byte(tt.line))
+		if ok != tt.ok || name != tt.name || closing != tt.closing || (ok && strings.Join(args, "|") != strings.Join(tt.args, "|")) {
+			t.Errorf("parseShortcodeTag(%q) = %q, %q, %v, %v", tt.line, name, args, closing, ok)
+		}
+	}
+}
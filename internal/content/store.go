@@ -0,0 +1,892 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vellum.forge/internal/search"
+)
+
+// Kind identifies which section of the data directory a content file belongs to
+type Kind string
+
+const (
+	KindPost   Kind = "blog"
+	KindPage   Kind = "pages"
+	KindAuthor Kind = "authors"
+)
+
+// Store keeps every post and page in memory and indexes them for fast lookups.
+// It is populated once by Load and kept up to date through HandleFileChange.
+type Store struct {
+	loader  *Loader
+	dataDir string
+	logger  *slog.Logger
+	mu      sync.RWMutex
+	now     func() time.Time // clock used to decide what is published, replaceable in tests
+
+	languages Languages // every language content is written in, nil if there's only one
+	lang      string    // language of the content kept, empty to keep everything
+	urlPrefix string    // path the content is served under, such as /fr
+
+	relatedCount      int  // related posts kept for each post
+	contentSimilarity bool // relate posts by text similarity as well as tags
+
+	writeMu sync.Mutex // serializes updates, which build new indexes without holding mu
+
+	files       map[string]*Content // all loaded content keyed by absolute file path
+	kinds       map[string]Kind     // section of each loaded file keyed by absolute file path
+	authorFiles map[string]*Author  // all loaded author profiles keyed by absolute file path
+
+	links *linkTargets // slugs and attachments wiki-links are resolved against
+
+	storeIndexes
+
+	generation uint64 // bumped whenever the indexes are rebuilt
+}
+
+// storeIndexes holds everything the store derives from its files. Updates build a new set
+// from copies of the files without holding the lock, and swap it in whole.
+type storeIndexes struct {
+	posts        map[string]*Content   // posts keyed by slug
+	pages        map[string]*Content   // pages keyed by slug
+	postList     []*Content            // published posts, newest first
+	pageList     []*Content            // published pages, newest first
+	allPosts     []*Content            // every post including drafts, newest first
+	allPages     []*Content            // every page including drafts, newest first
+	byTag        map[string][]*Content // published posts keyed by tag slug
+	byDate       map[string][]*Content // published posts keyed by "2006", "2006-01" and "2006-01-02"
+	archive      []*ArchiveYear        // post counts by year and month, newest first
+	nextChange   time.Time             // next scheduled publish or expiry, zero if none
+	tags         map[string]*Tag       // tags keyed by slug
+	tagList      []*Tag                // tags sorted by name
+	tagConflicts []Problem             // tags merged into another with the same slug
+	authors      map[string]*Author    // author profiles keyed by slug
+	authorList   []*Author             // author profiles sorted by name
+	byAuthor     map[string][]*Content // published posts keyed by author slug
+
+	searchIndex   *search.Index // full-text index of published posts and pages
+	searchEntries []searchEntry // indexed content, in search.Hit.Doc order
+
+	graph *Graph // links between published posts and pages
+
+	aliases        map[string]string // URLs of published posts and pages keyed by alias path
+	aliasConflicts []Problem         // aliases left out of aliases
+}
+
+// NewStore creates an empty content store for the given data directory
+func NewStore(loader *Loader, dataDir string, logger *slog.Logger, opts ...StoreOption) *Store {
+	s := &Store{
+		loader:       loader,
+		dataDir:      dataDir,
+		logger:       logger,
+		files:        make(map[string]*Content),
+		kinds:        make(map[string]Kind),
+		authorFiles:  make(map[string]*Author),
+		now:          time.Now,
+		relatedCount: DefaultRelatedCount,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Load walks the blog, pages and authors directories, parses every markdown file and builds the indexes.
+// If any file fails to load the store is left unchanged and the errors of every failed file are returned.
+func (s *Store) Load() error {
+	read, err := s.readFiles()
+	if err != nil {
+		return err
+	}
+	if len(read.failed) > 0 {
+		return read.err()
+	}
+
+	s.replaceFiles(read)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, content := range s.files {
+		s.logWarnings(content, s.authors)
+	}
+
+	s.logger.Info("Content store loaded", "posts", len(s.postList), "pages", len(s.pageList), "authors", len(s.authorList))
+	return nil
+}
+
+// storeFiles holds the files read from the data directory, keyed by absolute path
+type storeFiles struct {
+	files       map[string]*Content
+	kinds       map[string]Kind
+	authorFiles map[string]*Author
+	failed      map[string]error // files that failed to load
+}
+
+// err joins the errors of the files that failed to load, in path order
+func (f *storeFiles) err() error {
+	paths := make([]string, 0, len(f.failed))
+	for path := range f.failed {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	errs := make([]error, 0, len(paths))
+	for _, path := range paths {
+		errs = append(errs, f.failed[path])
+	}
+	return errors.Join(errs...)
+}
+
+// readFiles parses every markdown file in the blog, pages and authors directories,
+// carrying on past files that fail to load
+func (s *Store) readFiles() (*storeFiles, error) {
+	read := &storeFiles{
+		files:       make(map[string]*Content),
+		kinds:       make(map[string]Kind),
+		authorFiles: make(map[string]*Author),
+		failed:      make(map[string]error),
+	}
+
+	links := s.linkResolver()
+	for _, kind := range []Kind{KindPost, KindPage} {
+		err := s.walkMarkdown(kind, func(absPath string) error {
+			content, err := s.loadFile(absPath, kind, links)
+			if err != nil {
+				read.failed[absPath] = err
+				return nil
+			}
+			if !s.inLanguage(content) {
+				return nil
+			}
+
+			read.files[absPath] = content
+			read.kinds[absPath] = kind
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.walkMarkdown(KindAuthor, func(absPath string) error {
+		author, err := s.loader.LoadAuthor(absPath)
+		if err != nil {
+			read.failed[absPath] = err
+			return nil
+		}
+
+		read.authorFiles[absPath] = author
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return read, nil
+}
+
+// replaceFiles swaps in freshly read files and rebuilds everything derived from them
+func (s *Store) replaceFiles(read *storeFiles) {
+	s.update(func(files *storeFiles) {
+		files.files = read.files
+		files.kinds = read.kinds
+		files.authorFiles = read.authorFiles
+	})
+}
+
+// update applies change to copies of the store's files, re-resolves wiki-links and builds the
+// indexes from the copies, then swaps everything in. Readers are only blocked for the swap.
+// It returns the content re-parsed to re-resolve its wiki-links.
+func (s *Store) update(change func(files *storeFiles)) []*Content {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.updateLocked(change)
+}
+
+// updateLocked is update for callers already holding writeMu
+func (s *Store) updateLocked(change func(files *storeFiles)) []*Content {
+	s.mu.RLock()
+	files := &storeFiles{files: maps.Clone(s.files), kinds: maps.Clone(s.kinds), authorFiles: maps.Clone(s.authorFiles)}
+	links := s.links
+	s.mu.RUnlock()
+
+	if change != nil {
+		change(files)
+	}
+	links, relinked := s.updateLinks(files, links)
+	indexes := s.buildIndexes(files, s.now())
+
+	s.mu.Lock()
+	s.files = files.files
+	s.kinds = files.kinds
+	s.authorFiles = files.authorFiles
+	s.links = links
+	s.storeIndexes = *indexes
+	s.generation++
+	s.mu.Unlock()
+
+	return relinked
+}
+
+// loadFile loads a post or page, working out its language and nesting the slugs of pages
+// under their directories
+func (s *Store) loadFile(absPath string, kind Kind, links LinkResolver) (*Content, error) {
+	content, _, err := s.loader.loadContent(absPath, s.dataPath(absPath), links)
+	if err != nil {
+		return nil, err
+	}
+
+	stem := strings.TrimSuffix(filepath.Base(absPath), filepath.Ext(absPath))
+	s.languages.setLanguage(content, stem)
+
+	if kind == KindPage {
+		pagesDir, err := filepath.Abs(filepath.Join(s.dataDir, string(KindPage)))
+		if err != nil {
+			return nil, err
+		}
+		nestPageSlug(content, pagesDir, s.languages)
+	}
+	return content, nil
+}
+
+// linkResolver returns the targets wiki-links currently resolve against, nil before the first Load
+func (s *Store) linkResolver() LinkResolver {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.links == nil {
+		return nil
+	}
+	return s.links
+}
+
+// updateLinks recomputes the wiki-link targets of files and, if posts, pages or attachments
+// were added, removed or renamed since links, re-parses every file with wiki-links so broken links to them
+// resolve and links to removed content break. It returns the new targets and the re-parsed
+// content, which replaces the previous version in files.
+func (s *Store) updateLinks(files *storeFiles, links *linkTargets) (*linkTargets, []*Content) {
+	updated := newLinkTargets(files.files, files.kinds, s.dataDir, s.urlPrefix)
+	if updated.equal(links) {
+		return links, nil
+	}
+
+	var relinked []*Content
+	for path, content := range files.files {
+		if !content.hasWikiLinks {
+			continue
+		}
+
+		reparsed, err := s.loadFile(path, files.kinds[path], updated)
+		if err != nil {
+			s.logger.Warn("Failed to re-resolve wiki-links, keeping previous version", "path", path, "error", err)
+			continue
+		}
+		files.files[path] = reparsed
+		relinked = append(relinked, reparsed)
+	}
+	return updated, relinked
+}
+
+// logWarnings reports the problems found while loading a content file: its parse warnings and
+// the authors it names without a profile in authors
+func (s *Store) logWarnings(content *Content, authors map[string]*Author) {
+	for _, warning := range content.Warnings {
+		s.logger.Warn("Content warning", "path", content.Path, "line", warning.Line, "warning", warning.Message)
+	}
+	for _, slug := range unknownAuthors(content, authors) {
+		s.logger.Warn("Unknown author referenced in content", "author", slug, "path", content.Path)
+	}
+}
+
+// dataPath returns a file's path within the data directory, empty if it lies outside it
+func (s *Store) dataPath(filePath string) string {
+	absDataDir, err := filepath.Abs(s.dataDir)
+	if err != nil {
+		return ""
+	}
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return ""
+	}
+
+	rel, err := filepath.Rel(absDataDir, absPath)
+	if err != nil || !filepath.IsLocal(rel) {
+		return ""
+	}
+	return rel
+}
+
+// walkMarkdown calls fn with the absolute path of every markdown file in a section directory.
+// A missing section directory is not an error.
+func (s *Store) walkMarkdown(kind Kind, fn func(absPath string) error) error {
+	dir := filepath.Join(s.dataDir, string(kind))
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !isMarkdownFile(path) {
+			return nil
+		}
+
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+
+		return fn(absPath)
+	})
+
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to load %s content: %w", kind, err)
+	}
+
+	return nil
+}
+
+// HandleFileChange re-parses a single changed file, or forgets it if it has been removed.
+// Changed attachments re-resolve embeds, other files outside the blog, pages and authors
+// directories are ignored.
+func (s *Store) HandleFileChange(path string) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+
+	// Embeds of an added or removed attachment need resolving again
+	attachmentsDir, err := filepath.Abs(filepath.Join(s.dataDir, "attachments"))
+	if err != nil {
+		return
+	}
+	if rel, err := filepath.Rel(attachmentsDir, absPath); err == nil && filepath.IsLocal(rel) {
+		s.mu.RLock()
+		loaded := s.links != nil
+		s.mu.RUnlock()
+		if loaded {
+			s.update(nil)
+		}
+		return
+	}
+
+	if !isMarkdownFile(path) {
+		return
+	}
+
+	kind, ok := s.kindForPath(absPath)
+	if !ok {
+		return
+	}
+
+	if _, err := os.Stat(absPath); os.IsNotExist(err) {
+		s.removeFile(absPath)
+		return
+	}
+
+	if kind == KindAuthor {
+		author, err := s.loader.LoadAuthor(absPath)
+		if err != nil {
+			s.logger.Warn("Failed to reload author, keeping previous version", "path", absPath, "error", err)
+			return
+		}
+
+		s.update(func(files *storeFiles) {
+			files.authorFiles[absPath] = author
+		})
+
+		s.logger.Info("Author reloaded in store", "path", absPath, "slug", author.Slug)
+		return
+	}
+
+	content, err := s.loadFile(absPath, kind, s.linkResolver())
+	if err != nil {
+		s.logger.Warn("Failed to reload content, keeping previous version", "path", absPath, "error", err)
+		return
+	}
+
+	// Content moved to another language is another store's now
+	if !s.inLanguage(content) {
+		s.mu.RLock()
+		_, loaded := s.files[absPath]
+		s.mu.RUnlock()
+		if loaded {
+			s.removeFile(absPath)
+		}
+		return
+	}
+
+	relinked := s.update(func(files *storeFiles) {
+		files.files[absPath] = content
+		files.kinds[absPath] = kind
+	})
+
+	s.mu.RLock()
+	content = s.files[absPath]
+	authors := s.authors
+	s.mu.RUnlock()
+
+	s.logWarnings(content, authors)
+	for _, other := range relinked {
+		if other != content {
+			s.logWarnings(other, authors)
+		}
+	}
+
+	s.logger.Info("Content reloaded in store", "path", absPath, "slug", content.Frontmatter.Slug)
+}
+
+// removeFile forgets a file that has been removed or no longer belongs in the store
+func (s *Store) removeFile(absPath string) {
+	relinked := s.update(func(files *storeFiles) {
+		delete(files.files, absPath)
+		delete(files.kinds, absPath)
+		delete(files.authorFiles, absPath)
+	})
+
+	s.mu.RLock()
+	authors := s.authors
+	s.mu.RUnlock()
+
+	for _, content := range relinked {
+		s.logWarnings(content, authors)
+	}
+
+	s.logger.Info("Content removed from store", "path", absPath)
+}
+
+// Generation returns a number that changes whenever anything the store serves does, so
+// listings can be cached without looking at the files they list
+func (s *Store) Generation() uint64 {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.generation
+}
+
+// NextChange returns the next time a scheduled post is published or a post expires,
+// or the zero time if nothing is scheduled. Anything rendered from the store is stale after it.
+func (s *Store) NextChange() time.Time {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.nextChange
+}
+
+// Posts returns all published posts, newest first. The returned slice must not be modified.
+func (s *Store) Posts() []*Content {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.postList
+}
+
+// Pages returns all published pages, newest first. The returned slice must not be modified.
+func (s *Store) Pages() []*Content {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.pageList
+}
+
+// AllPosts returns every post including drafts, scheduled and expired posts, newest first.
+// The returned slice must not be modified.
+func (s *Store) AllPosts() []*Content {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.allPosts
+}
+
+// AllPages returns every page including drafts, scheduled and expired pages, newest first.
+// The returned slice must not be modified.
+func (s *Store) AllPages() []*Content {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.allPages
+}
+
+// Post returns the published post with the given slug
+func (s *Store) Post(slug string) (*Content, bool) {
+	s.rlock()
+	defer s.mu.RUnlock()
+	post, ok := s.posts[slug]
+	if !ok || !post.IsPublished(s.now()) {
+		return nil, false
+	}
+	return post, true
+}
+
+// Page returns the published page with the given slug
+func (s *Store) Page(slug string) (*Content, bool) {
+	s.rlock()
+	defer s.mu.RUnlock()
+	page, ok := s.pages[slug]
+	if !ok || !page.IsPublished(s.now()) {
+		return nil, false
+	}
+	return page, true
+}
+
+// PreviewPost returns the post with the given slug, including drafts, scheduled and expired posts
+func (s *Store) PreviewPost(slug string) (*Content, bool) {
+	s.rlock()
+	defer s.mu.RUnlock()
+	post, ok := s.posts[slug]
+	return post, ok
+}
+
+// PreviewPage returns the page with the given slug, including drafts, scheduled and expired pages
+func (s *Store) PreviewPage(slug string) (*Content, bool) {
+	s.rlock()
+	defer s.mu.RUnlock()
+	page, ok := s.pages[slug]
+	return page, ok
+}
+
+// PostsByTag returns the published posts carrying the tag with the given slug, newest first
+func (s *Store) PostsByTag(slug string) []*Content {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.byTag[slug]
+}
+
+// Tag returns the tag with the given slug
+func (s *Store) Tag(slug string) (*Tag, bool) {
+	s.rlock()
+	defer s.mu.RUnlock()
+	tag, ok := s.tags[slug]
+	return tag, ok
+}
+
+// Tags returns every tag used by a published post with its post count, sorted by name.
+// The returned slice must not be modified.
+func (s *Store) Tags() []*Tag {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.tagList
+}
+
+// Author returns the author profile with the given slug
+func (s *Store) Author(slug string) (*Author, bool) {
+	s.rlock()
+	defer s.mu.RUnlock()
+	author, ok := s.authors[slug]
+	return author, ok
+}
+
+// Authors returns every author profile, sorted by name. The returned slice must not be modified.
+func (s *Store) Authors() []*Author {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.authorList
+}
+
+// PostsByAuthor returns the published posts written by the given author, newest first
+func (s *Store) PostsByAuthor(slug string) []*Content {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.byAuthor[slug]
+}
+
+// PostsByYear returns the published posts dated in the given year, newest first
+func (s *Store) PostsByYear(year int) []*Content {
+	return s.postsByDateKey(fmt.Sprintf("%04d", year))
+}
+
+// PostsByMonth returns the published posts dated in the given month, newest first
+func (s *Store) PostsByMonth(year int, month time.Month) []*Content {
+	return s.postsByDateKey(fmt.Sprintf("%04d-%02d", year, int(month)))
+}
+
+// PostsByDay returns the published posts dated on the same day as t, newest first
+func (s *Store) PostsByDay(t time.Time) []*Content {
+	return s.postsByDateKey(t.Format("2006-01-02"))
+}
+
+// Archive returns the published post counts grouped by year and month, newest first.
+// The returned slice must not be modified.
+func (s *Store) Archive() []*ArchiveYear {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.archive
+}
+
+func (s *Store) postsByDateKey(key string) []*Content {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.byDate[key]
+}
+
+// rlock acquires the read lock, first rebuilding the indexes if a scheduled post
+// has been published or a post has expired since they were last built
+func (s *Store) rlock() {
+	s.mu.RLock()
+	if !s.changeDue() {
+		return
+	}
+	s.mu.RUnlock()
+
+	s.writeMu.Lock()
+	s.mu.RLock()
+	due := s.changeDue() // Another reader may have rebuilt them meanwhile
+	s.mu.RUnlock()
+	if due {
+		s.updateLocked(nil)
+	}
+	s.writeMu.Unlock()
+
+	s.mu.RLock()
+}
+
+// changeDue reports whether the published set has changed on its own since the indexes were
+// built (must be called with lock held)
+func (s *Store) changeDue() bool {
+	return !s.nextChange.IsZero() && !s.now().Before(s.nextChange)
+}
+
+// kindForPath works out which section an absolute path belongs to
+func (s *Store) kindForPath(absPath string) (Kind, bool) {
+	absDataDir, err := filepath.Abs(s.dataDir)
+	if err != nil {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(absDataDir, absPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+
+	section, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	switch Kind(section) {
+	case KindPost:
+		return KindPost, true
+	case KindPage:
+		return KindPage, true
+	case KindAuthor:
+		return KindAuthor, true
+	}
+
+	return "", false
+}
+
+// buildIndexes computes the lookup tables from files as of now, without touching the store.
+// Fresh maps, slices and Content copies are built so that callers holding anything
+// returned before the rebuild keep seeing a consistent, unchanging view.
+func (s *Store) buildIndexes(files *storeFiles, now time.Time) *storeIndexes {
+	authors := s.buildAuthors(files.authorFiles)
+
+	paths := make([]string, 0, len(files.files))
+	for path := range files.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var nextChange time.Time
+
+	posts := make(map[string]*Content)
+	pages := make(map[string]*Content)
+	var postList, pageList, allPosts, allPages []*Content
+
+	for _, path := range paths {
+		// Work on a copy so fields derived from other files can be filled in safely
+		content := new(Content)
+		*content = *files.files[path]
+		content.Authors = s.resolveAuthors(content, authors)
+		slug := content.Frontmatter.Slug
+
+		bySlug, list, all := posts, &postList, &allPosts
+		if files.kinds[path] == KindPage {
+			bySlug, list, all = pages, &pageList, &allPages
+		}
+
+		if slug == "" {
+			s.logger.Warn("Page without a slug, ignoring file as the home page is served at /", "path", path)
+			continue
+		}
+		if existing, exists := bySlug[slug]; exists {
+			s.logger.Warn("Duplicate content slug, ignoring file", "slug", slug, "path", path, "existing", existing.Path)
+			continue
+		}
+		bySlug[slug] = content
+		*all = append(*all, content)
+
+		if content.IsPublished(now) {
+			*list = append(*list, content)
+		}
+
+		// Track when the published set next changes on its own
+		if !content.Frontmatter.Draft {
+			for _, t := range []time.Time{content.Frontmatter.Date, content.Frontmatter.Expires} {
+				if t.After(now) && (nextChange.IsZero() || t.Before(nextChange)) {
+					nextChange = t
+				}
+			}
+		}
+	}
+
+	sortByDateDesc(postList)
+	sortByDateDesc(pageList)
+	sortByDateDesc(allPosts)
+	sortByDateDesc(allPages)
+
+	// Related posts are only ever drawn from published posts, but drafts get them too for previews
+	scorer := newRelatedScorer(postList, s.contentSimilarity)
+	for _, post := range posts {
+		post.Related = scorer.related(post, s.relatedCount)
+	}
+
+	linkPageTree(pages, pageList)
+	graph := buildGraph(s.urlPrefix, posts, pages, postList, pageList)
+
+	byTag := make(map[string][]*Content)
+	byDate := make(map[string][]*Content)
+	byAuthor := make(map[string][]*Content)
+	tags := make(map[string]*Tag)
+	var tagConflicts []Problem
+	reported := make(map[string]bool) // Tag names already reported as conflicting
+	for _, post := range postList {
+		for _, author := range post.Authors {
+			byAuthor[author.Slug] = append(byAuthor[author.Slug], post)
+		}
+
+		for _, tag := range post.Tags() {
+			if existing, exists := tags[tag.Slug]; !exists {
+				tags[tag.Slug] = tag
+			} else {
+				if !strings.EqualFold(existing.Name, tag.Name) && !reported[tag.Name] {
+					reported[tag.Name] = true
+					tagConflicts = append(tagConflicts, Problem{
+						Path:     s.relPath(post.Path),
+						Severity: SeverityWarning,
+						Message:  fmt.Sprintf("tag %q has the same slug %q as %q and is listed with it", tag.Name, tag.Slug, existing.Name),
+					})
+				}
+				if posts := byTag[tag.Slug]; posts[len(posts)-1] == post {
+					continue // Same tag listed twice on one post
+				}
+			}
+			tags[tag.Slug].Count++
+			byTag[tag.Slug] = append(byTag[tag.Slug], post)
+		}
+
+		date := post.GetDate()
+		for _, layout := range []string{"2006", "2006-01", "2006-01-02"} {
+			key := date.Format(layout)
+			byDate[key] = append(byDate[key], post)
+		}
+	}
+
+	tagList := make([]*Tag, 0, len(tags))
+	minCount, maxCount := 0, 0
+	for _, tag := range tags {
+		if minCount == 0 || tag.Count < minCount {
+			minCount = tag.Count
+		}
+		maxCount = max(maxCount, tag.Count)
+		tagList = append(tagList, tag)
+	}
+	for _, tag := range tagList {
+		tag.Weight = tagWeight(tag.Count, minCount, maxCount)
+	}
+	sort.Slice(tagList, func(i, j int) bool {
+		return strings.ToLower(tagList[i].Name) < strings.ToLower(tagList[j].Name)
+	})
+
+	ix := &storeIndexes{
+		posts:        posts,
+		pages:        pages,
+		postList:     postList,
+		pageList:     pageList,
+		allPosts:     allPosts,
+		allPages:     allPages,
+		byTag:        byTag,
+		byDate:       byDate,
+		archive:      buildArchive(postList),
+		nextChange:   nextChange,
+		tags:         tags,
+		tagList:      tagList,
+		tagConflicts: tagConflicts,
+		authors:      authors,
+		authorList:   sortedAuthors(authors),
+		byAuthor:     byAuthor,
+		graph:        graph,
+	}
+	ix.searchIndex, ix.searchEntries = buildSearchIndex(s.urlPrefix, postList, pageList)
+	ix.aliases, ix.aliasConflicts = s.buildAliases(postList, pageList)
+	return ix
+}
+
+// buildAuthors indexes author profiles, keyed by file path, by slug
+func (s *Store) buildAuthors(authorFiles map[string]*Author) map[string]*Author {
+	paths := make([]string, 0, len(authorFiles))
+	for path := range authorFiles {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	authors := make(map[string]*Author)
+	for _, path := range paths {
+		author := authorFiles[path]
+		if existing, exists := authors[author.Slug]; exists {
+			s.logger.Warn("Duplicate author slug, ignoring file", "slug", author.Slug, "path", path, "existing", existing.Path)
+			continue
+		}
+		authors[author.Slug] = author
+	}
+
+	return authors
+}
+
+// resolveAuthors maps the author slugs in a content's frontmatter to profiles.
+// Slugs without a profile file get a minimal profile so templates can still show a byline;
+// they are reported when the file is loaded rather than on every rebuild.
+func (s *Store) resolveAuthors(content *Content, authors map[string]*Author) []*Author {
+	var resolved []*Author
+	for _, slug := range content.Frontmatter.AuthorSlugs() {
+		author, ok := authors[slug]
+		if !ok {
+			author = &Author{Slug: slug, Name: slug}
+		}
+		resolved = append(resolved, author)
+	}
+	return resolved
+}
+
+// unknownAuthors returns the author slugs in a content's frontmatter without a profile in authors
+func unknownAuthors(content *Content, authors map[string]*Author) []string {
+	var unknown []string
+	for _, slug := range content.Frontmatter.AuthorSlugs() {
+		if _, ok := authors[slug]; !ok {
+			unknown = append(unknown, slug)
+		}
+	}
+	return unknown
+}
+
+// sortedAuthors returns the author profiles sorted by display name
+func sortedAuthors(authors map[string]*Author) []*Author {
+	list := make([]*Author, 0, len(authors))
+	for _, author := range authors {
+		list = append(list, author)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].DisplayName()) < strings.ToLower(list[j].DisplayName())
+	})
+	return list
+}
+
+// sortByDateDesc sorts content by date, newest first
+func sortByDateDesc(contents []*Content) {
+	sort.SliceStable(contents, func(i, j int) bool {
+		return contents[i].GetDate().After(contents[j].GetDate())
+	})
+}
+
+// isMarkdownFile reports whether the path points to a markdown file
+func isMarkdownFile(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), ".md")
+}
@@ -0,0 +1,304 @@
+package content
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T, opts ...StoreOption) (*Store, string) {
+	t.Helper()
+
+	dataDir := t.TempDir()
+	for _, dir := range []string{"blog", "pages"} {
+		if err := os.MkdirAll(filepath.Join(dataDir, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewStore(NewLoader(), dataDir, logger, opts...), dataDir
+}
+
+func writeContentFile(t *testing.T, path, body string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStore_Load(t *testing.T) {
+	store, dataDir := newTestStore(t)
+
+	writeContentFile(t, filepath.Join(dataDir, "blog", "first.md"), "---\ntitle: First\ndate: 2024-01-15T10:00:00Z\ntags: [\"Go\", \"web\"]\n---\n\nHello")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "second.md"), "---\ntitle: Second\ndate: 2024-02-01T10:00:00Z\ntags: [\"go\"]\nslug: custom-second\n---\n\nWorld")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "draft.md"), "---\ntitle: Draft\ndate: 2024-03-01T10:00:00Z\ndraft: true\n---\n\nWIP")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "about.md"), "---\ntitle: About\n---\n\nAbout me")
+
+	if err := store.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	posts := store.Posts()
+	if len(posts) != 2 {
+		t.Fatalf("Expected 2 published posts, got %d", len(posts))
+	}
+	if posts[0].Frontmatter.Slug != "custom-second" {
+		t.Errorf("Expected newest post first, got %s", posts[0].Frontmatter.Slug)
+	}
+
+	if _, found := store.Post("custom-second"); !found {
+		t.Error("Expected to find post by frontmatter slug")
+	}
+	if _, found := store.Page("about"); !found {
+		t.Error("Expected to find page by filename slug")
+	}
+	if _, found := store.Post("draft"); found {
+		t.Error("Expected drafts to be hidden from direct lookups")
+	}
+	if _, found := store.PreviewPost("draft"); !found {
+		t.Error("Expected drafts to be available for previews")
+	}
+
+	if got := len(store.PostsByTag("go")); got != 2 {
+		t.Errorf("Expected 2 posts tagged go, got %d", got)
+	}
+	tag, found := store.Tag("go")
+	if !found {
+		t.Fatal("Expected to find tag by slug")
+	}
+	if tag.Count != 2 || tag.Weight != 5 {
+		t.Errorf("Expected go tag with count 2 and weight 5, got count %d and weight %d", tag.Count, tag.Weight)
+	}
+	if got := len(store.Tags()); got != 2 {
+		t.Errorf("Expected 2 tags, got %d", got)
+	}
+
+	if got := len(store.PostsByYear(2024)); got != 2 {
+		t.Errorf("Expected 2 posts in 2024, got %d", got)
+	}
+	if got := len(store.PostsByMonth(2024, time.February)); got != 1 {
+		t.Errorf("Expected 1 post in February 2024, got %d", got)
+	}
+	if got := len(store.PostsByDay(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))); got != 1 {
+		t.Errorf("Expected 1 post on 2024-01-15, got %d", got)
+	}
+
+	archive := store.Archive()
+	if len(archive) != 1 || archive[0].Year != 2024 || archive[0].Count != 2 {
+		t.Fatalf("Expected one archive year 2024 with 2 posts, got %+v", archive)
+	}
+	if months := archive[0].Months; len(months) != 2 || months[0].Month != time.February || months[0].URL() != "/blog/2024/02" {
+		t.Errorf("Expected February then January 2024 in the archive, got %+v", months)
+	}
+}
+
+func TestStore_HandleFileChange(t *testing.T) {
+	store, dataDir := newTestStore(t)
+
+	path := filepath.Join(dataDir, "blog", "post.md")
+	writeContentFile(t, path, "---\ntitle: Original\ndate: 2024-01-15T10:00:00Z\n---\n\nBody")
+
+	if err := store.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	loaded := store.Generation()
+
+	// Modify the file
+	writeContentFile(t, path, "---\ntitle: Updated\ndate: 2024-01-15T10:00:00Z\ntags: [\"new\"]\n---\n\nBody")
+	store.HandleFileChange(path)
+
+	if store.Generation() == loaded {
+		t.Error("Expected the generation to change with the file")
+	}
+
+	post, found := store.Post("post")
+	if !found {
+		t.Fatal("Expected post to still exist after modification")
+	}
+	if post.Frontmatter.Title != "Updated" {
+		t.Errorf("Expected updated title, got %s", post.Frontmatter.Title)
+	}
+	if got := len(store.PostsByTag("new")); got != 1 {
+		t.Errorf("Expected tag index to be updated, got %d posts", got)
+	}
+
+	// Add a new file
+	newPath := filepath.Join(dataDir, "blog", "another.md")
+	writeContentFile(t, newPath, "---\ntitle: Another\ndate: 2024-01-16T10:00:00Z\n---\n\nBody")
+	store.HandleFileChange(newPath)
+
+	if got := len(store.Posts()); got != 2 {
+		t.Errorf("Expected 2 posts after adding a file, got %d", got)
+	}
+
+	// Remove the original file
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	store.HandleFileChange(path)
+
+	if _, found := store.Post("post"); found {
+		t.Error("Expected post to be removed from the store")
+	}
+	if got := len(store.PostsByTag("new")); got != 0 {
+		t.Errorf("Expected tag index to drop removed post, got %d posts", got)
+	}
+
+	// Files outside the content sections are ignored
+	store.HandleFileChange(filepath.Join(dataDir, "attachments", "notes.md"))
+	if got := len(store.Posts()); got != 1 {
+		t.Errorf("Expected 1 post, got %d", got)
+	}
+}
+
+func TestStore_ConcurrentFileChanges(t *testing.T) {
+	store, dataDir := newTestStore(t)
+	if err := store.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// Changes build their indexes from copies, so simultaneous ones must not drop each other
+	var wg sync.WaitGroup
+	for i := range 10 {
+		path := filepath.Join(dataDir, "blog", fmt.Sprintf("post-%d.md", i))
+		writeContentFile(t, path, fmt.Sprintf("---\ntitle: Post %d\ndate: 2024-01-%02dT10:00:00Z\n---\n\nBody", i, i+1))
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			store.HandleFileChange(path)
+		}()
+		go func() {
+			defer wg.Done()
+			store.Posts()
+		}()
+	}
+	wg.Wait()
+
+	if got := len(store.Posts()); got != 10 {
+		t.Errorf("Expected 10 posts, got %d", got)
+	}
+	if got := len(store.Search("body", 20)); got != 10 {
+		t.Errorf("Expected 10 search hits, got %d", got)
+	}
+}
+
+func TestStore_UnknownAuthors(t *testing.T) {
+	store, dataDir := newTestStore(t)
+	var logs strings.Builder
+	store.logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	writeContentFile(t, filepath.Join(dataDir, "blog", "post.md"), "---\ntitle: Post\ndate: 2024-01-15T10:00:00Z\nauthor: ghost\n---\n\nBody")
+	if err := store.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// Other files changing rebuilds the indexes without reloading the post
+	other := filepath.Join(dataDir, "blog", "other.md")
+	writeContentFile(t, other, "---\ntitle: Other\ndate: 2024-01-16T10:00:00Z\n---\n\nBody")
+	store.HandleFileChange(other)
+
+	if got := strings.Count(logs.String(), "Unknown author referenced in content"); got != 1 {
+		t.Errorf("Expected the unknown author to be reported once, got %d times:\n%s", got, logs.String())
+	}
+
+	post, _ := store.Post("post")
+	if len(post.Authors) != 1 || post.Authors[0].Name != "ghost" {
+		t.Errorf("Expected a minimal profile for the unknown author, got %+v", post.Authors)
+	}
+}
+
+func TestStore_ScheduledAndExpired(t *testing.T) {
+	store, dataDir := newTestStore(t)
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	writeContentFile(t, filepath.Join(dataDir, "blog", "live.md"), "---\ntitle: Live\ndate: 2024-05-01T10:00:00Z\n---\n\nBody")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "scheduled.md"), "---\ntitle: Scheduled\ndate: 2024-06-02T09:00:00Z\ntags: [\"soon\"]\n---\n\nBody")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "expiring.md"), "---\ntitle: Expiring\ndate: 2024-05-02T10:00:00Z\nexpires: 2024-06-03T00:00:00Z\n---\n\nBody")
+
+	if err := store.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if got := len(store.Posts()); got != 2 {
+		t.Errorf("Expected 2 published posts before the schedule, got %d", got)
+	}
+	if _, found := store.Post("scheduled"); found {
+		t.Error("Expected scheduled post to be hidden from direct lookups")
+	}
+	if _, found := store.Tag("soon"); found {
+		t.Error("Expected tags of scheduled posts to be hidden")
+	}
+	if want := time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC); !store.NextChange().Equal(want) {
+		t.Errorf("Expected next change at %v, got %v", want, store.NextChange())
+	}
+
+	// The scheduled post goes live without any file change
+	scheduled := store.Generation()
+	now = time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC)
+	if store.Generation() == scheduled {
+		t.Error("Expected the generation to change when the scheduled post goes live")
+	}
+	if _, found := store.Post("scheduled"); !found {
+		t.Error("Expected scheduled post to be published once its date has passed")
+	}
+	if got := len(store.Posts()); got != 3 {
+		t.Errorf("Expected 3 published posts after the schedule, got %d", got)
+	}
+
+	// And the expiring post is unpublished
+	now = time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	if _, found := store.Post("expiring"); found {
+		t.Error("Expected expired post to be hidden from direct lookups")
+	}
+	if got := len(store.Posts()); got != 2 {
+		t.Errorf("Expected 2 published posts after expiry, got %d", got)
+	}
+	if !store.NextChange().IsZero() {
+		t.Errorf("Expected nothing left to schedule, got %v", store.NextChange())
+	}
+}
+
+func TestStore_Search(t *testing.T) {
+	store, dataDir := newTestStore(t)
+
+	writeContentFile(t, filepath.Join(dataDir, "blog", "caching.md"), "---\ntitle: Caching\ndate: 2024-01-15T10:00:00Z\ntags: [\"performance\"]\n---\n\nAn LRU cache in front of the renderer.")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "draft.md"), "---\ntitle: Draft about caching\ndate: 2024-02-01T10:00:00Z\ndraft: true\n---\n\nWIP")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "about.md"), "---\ntitle: About\n---\n\nWe write about performance.")
+
+	if err := store.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	results := store.Search("caching", 10)
+	if len(results) != 1 || results[0].URL != "/blog/caching" || !results[0].IsPost() {
+		t.Fatalf("Expected only the published post, got %+v", results)
+	}
+
+	results = store.Search("performance", 10)
+	if len(results) != 2 || results[0].URL != "/blog/caching" || results[1].URL != "/about" {
+		t.Errorf("Expected the tagged post ranked above the page, got %+v", results)
+	}
+
+	path := filepath.Join(dataDir, "pages", "about.md")
+	writeContentFile(t, path, "---\ntitle: About\n---\n\nWe write about caching.")
+	store.HandleFileChange(path)
+
+	if results := store.Search("caching", 10); len(results) != 2 {
+		t.Errorf("Expected the index to be updated after a file change, got %d results", len(results))
+	}
+}
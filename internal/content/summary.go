@@ -0,0 +1,75 @@
+package content
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark/parser"
+)
+
+const (
+	// MoreMarker separates a hand-picked excerpt from the rest of the body
+	MoreMarker = "<!--more-->"
+
+	// ExcerptWords is the length of excerpts generated from the start of the body
+	ExcerptWords = 50
+
+	// WordsPerMinute is the reading speed used to estimate reading time
+	WordsPerMinute = 200
+)
+
+// textPolicy strips every tag, leaving only text
+var textPolicy = bluemonday.StrictPolicy()
+
+// moreKey is the parser context key under which the sanitized HTML above a <!--more--> marker
+// is kept
+var moreKey = parser.NewContextKey()
+
+// headingAnchorPattern matches the permalinks added by WithHeadingAnchors, which aren't part of the text
+var headingAnchorPattern = regexp.MustCompile(`<a [^>]*class="heading-anchor"[^>]*>#</a>`)
+
+// keepExcerpt keeps the part of the rendered HTML above a <!--more--> marker, sanitized the way
+// the whole is, since the sanitizer drops the marker with every other comment
+func keepExcerpt(ctx parser.Context, sanitizer *bluemonday.Policy, rendered string) {
+	if before, _, found := strings.Cut(rendered, MoreMarker); found {
+		ctx.Set(moreKey, sanitizer.Sanitize(before))
+	}
+}
+
+// summarize fills in the word count, reading time and excerpt of parsed content.
+// The excerpt comes from the excerpt frontmatter field, the HTML kept by keepExcerpt above a
+// <!--more--> marker, or else the first ExcerptWords words of the body.
+func summarize(content *Content, ctx parser.Context) {
+	words := strings.Fields(plainText(content.HTML))
+
+	content.WordCount = len(words)
+	content.ReadingTime = (len(words) + WordsPerMinute - 1) / WordsPerMinute
+
+	if excerpt := strings.TrimSpace(content.Frontmatter.Excerpt); excerpt != "" {
+		content.Excerpt = excerpt
+		return
+	}
+
+	if before, found := ctx.Get(moreKey).(string); found {
+		content.Excerpt = strings.Join(strings.Fields(plainText(before)), " ")
+		return
+	}
+
+	content.Excerpt = truncateWords(words, ExcerptWords)
+}
+
+// plainText strips HTML tags and heading anchors, and decodes entities
+func plainText(s string) string {
+	s = headingAnchorPattern.ReplaceAllString(s, "")
+	return html.UnescapeString(textPolicy.Sanitize(s))
+}
+
+// truncateWords joins the first n words, adding an ellipsis if any were left out
+func truncateWords(words []string, n int) string {
+	if len(words) <= n {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(words[:n], " ") + "…"
+}
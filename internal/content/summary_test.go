@@ -0,0 +1,78 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownParser_Summary(t *testing.T) {
+	parser := NewMarkdownParser()
+
+	t.Run("Counts words and estimates reading time", func(t *testing.T) {
+		body := strings.Repeat("word ", 450)
+		parsed, err := parser.Parse([]byte("---\ntitle: Long\n---\n\n" + body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed.WordCount != 450 {
+			t.Errorf("Expected 450 words, got %d", parsed.WordCount)
+		}
+		if parsed.ReadingTime != 3 {
+			t.Errorf("Expected 3 minutes reading time, got %d", parsed.ReadingTime)
+		}
+		if got := len(strings.Fields(parsed.Excerpt)); got != ExcerptWords {
+			t.Errorf("Expected a %d word excerpt, got %d words", ExcerptWords, got)
+		}
+		if !strings.HasSuffix(parsed.Excerpt, "…") {
+			t.Errorf("Expected truncated excerpt to end with an ellipsis, got %q", parsed.Excerpt)
+		}
+	})
+
+	t.Run("Uses the text above the more marker with HTML stripped", func(t *testing.T) {
+		parsed, err := parser.Parse([]byte("---\ntitle: More\n---\n\nThe **intro** & [a link](/x).\n\n<!--more-->\n\nThe rest."))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed.Excerpt != "The intro & a link." {
+			t.Errorf("Expected excerpt from above the marker, got %q", parsed.Excerpt)
+		}
+	})
+
+	t.Run("Ignores more markers shown in code", func(t *testing.T) {
+		parsed, err := parser.Parse([]byte("---\ntitle: Code\n---\n\nPut `<!--more-->` where the excerpt ends.\n\n```\n<!--more-->\n```\n\nThe rest."))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed.Excerpt != "Put <!--more--> where the excerpt ends. <!--more--> The rest." {
+			t.Errorf("Expected excerpt from the whole body, got %q", parsed.Excerpt)
+		}
+	})
+
+	t.Run("Renders the excerpt with the document's wiki-links and sanitizer", func(t *testing.T) {
+		parsed, err := parser.ParseWithLinks([]byte("---\ntitle: Links\n---\n\nSee [[hello|the intro]] <span onclick=\"steal()\">now</span>.\n\n<!--more-->\n\nThe rest."), testLinks{"hello": true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed.Excerpt != "See the intro now." {
+			t.Errorf("Expected excerpt from above the marker, got %q", parsed.Excerpt)
+		}
+		if len(parsed.Warnings) != 1 {
+			t.Errorf("Expected the removed handler to be reported once, got %v", parsed.Warnings)
+		}
+	})
+
+	t.Run("Prefers the excerpt frontmatter field", func(t *testing.T) {
+		parsed, err := parser.Parse([]byte("---\ntitle: Explicit\nexcerpt: Hand written\n---\n\nIntro\n\n<!--more-->\n\nRest"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed.Excerpt != "Hand written" {
+			t.Errorf("Expected excerpt from frontmatter, got %q", parsed.Excerpt)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package content
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Tag represents a tag used by one or more published posts
+type Tag struct {
+	Name   string // Display name, as written in frontmatter
+	Slug   string // URL-safe identifier used by /tag/{slug}
+	Count  int    // Number of published posts carrying the tag
+	Weight int    // Relative popularity from 1 to 5, for tag clouds
+}
+
+// URL returns the path of the tag's archive page
+func (t *Tag) URL() string {
+	return "/tag/" + t.Slug
+}
+
+// TagSlug returns the slug for a tag name: its letters and digits in any script, lowercased and
+// joined by dashes, with + and # spelled out so "C++" and "C#" don't both become "c"
+func TagSlug(name string) string {
+	var b strings.Builder
+	separate := false
+	write := func(s string) {
+		if separate && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		separate = false
+		b.WriteString(s)
+	}
+
+	for _, r := range norm.NFC.String(strings.ToLower(name)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			write(string(r))
+		case r == '+':
+			write("plus")
+		case r == '#':
+			write("sharp")
+		default:
+			separate = true
+		}
+	}
+	return b.String()
+}
+
+// emptyTagWarnings reports the tags in frontmatter without a letter or digit to make a slug of,
+// which Content.Tags leaves out
+func emptyTagWarnings(fm Frontmatter) []Warning {
+	var warnings []Warning
+	for _, name := range fm.Tags {
+		if TagSlug(name) == "" {
+			warnings = append(warnings, Warning{Message: fmt.Sprintf("tag %q has no letters or digits for a slug, leaving it out", name)})
+		}
+	}
+	return warnings
+}
+
+// TagConflicts returns the tags whose names differ (other than by case) but share a slug,
+// whose posts are listed together under the name that came first
+func (s *Store) TagConflicts() []Problem {
+	s.rlock()
+	defer s.mu.RUnlock()
+	return s.tagConflicts
+}
+
+// Tags returns the content's tags with their slugs, in frontmatter order.
+// Counts and weights are only populated on tags returned by the Store.
+func (c *Content) Tags() []*Tag {
+	tags := make([]*Tag, 0, len(c.Frontmatter.Tags))
+	for _, name := range c.Frontmatter.Tags {
+		slug := TagSlug(name)
+		if slug == "" {
+			continue
+		}
+		tags = append(tags, &Tag{Name: name, Slug: slug})
+	}
+	return tags
+}
+
+// tagWeight scales a tag's count to a 1-5 weight between the least and most used tags
+func tagWeight(count, minCount, maxCount int) int {
+	if maxCount <= minCount {
+		return 1
+	}
+	return 1 + (count-minCount)*4/(maxCount-minCount)
+}
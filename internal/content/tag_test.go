@@ -0,0 +1,64 @@
+package content
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestTagSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Go", "go"},
+		{"Web Dev", "web-dev"},
+		{"  web -- dev  ", "web-dev"},
+		{"C++", "cplusplus"},
+		{"C#", "csharp"},
+		{"F# & C#", "fsharp-csharp"},
+		{"Été", "été"},
+		{"Été", "été"},
+		{"日本語", "日本語"},
+		{"Москва 2024", "москва-2024"},
+		{"node.js", "node-js"},
+		{"!!!", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TagSlug(tt.name); got != tt.want {
+				t.Errorf("TagSlug(%q) = %q, expected %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_TagProblems(t *testing.T) {
+	store, dataDir := newTestStore(t)
+
+	writeContentFile(t, filepath.Join(dataDir, "blog", "newer.md"), "---\ntitle: Newer\ndate: 2024-02-15T10:00:00Z\ntags: [\"C++\", \"Web Dev\", \"!!!\"]\n---\n\nBody")
+	writeContentFile(t, filepath.Join(dataDir, "blog", "older.md"), "---\ntitle: Older\ndate: 2024-01-15T10:00:00Z\ntags: [\"cplusplus\", \"web dev\", \"C#\"]\n---\n\nBody")
+
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	for slug, count := range map[string]int{"cplusplus": 2, "web-dev": 2, "csharp": 1} {
+		tag, found := store.Tag(slug)
+		if !found || tag.Count != count {
+			t.Errorf("Expected tag %q on %d posts, got %+v", slug, count, tag)
+		}
+	}
+
+	got := problemStrings(store.TagConflicts(), dataDir)
+	want := []string{`blog/older.md: warning: tag "cplusplus" has the same slug "cplusplus" as "C++" and is listed with it`}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected tag conflicts\n%v\ngot\n%v", want, got)
+	}
+
+	newer, _ := store.Post("newer")
+	if len(newer.Warnings) != 1 || newer.Warnings[0].Message != `tag "!!!" has no letters or digits for a slug, leaving it out` {
+		t.Errorf("Expected a warning for the empty tag slug, got %v", newer.Warnings)
+	}
+}
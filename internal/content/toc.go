@@ -0,0 +1,120 @@
+package content
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// DefaultTOCDepth is the deepest heading level included in a table of contents by default
+const DefaultTOCDepth = 3
+
+// TOCEntry is a heading in a content's table of contents
+type TOCEntry struct {
+	ID       string
+	Text     string
+	Level    int
+	Children []*TOCEntry // Subheadings nested under this heading
+}
+
+// URL returns the in-page link to the heading
+func (e *TOCEntry) URL() string {
+	return "#" + e.ID
+}
+
+// heading is a heading collected from a document while it is parsed
+type heading struct {
+	level int
+	id    string
+	text  string
+}
+
+var headingsKey = parser.NewContextKey()
+
+// headingTransformer records every heading with its generated ID so a table of contents
+// can be built, and optionally appends a permalink anchor to each heading
+type headingTransformer struct {
+	anchors bool
+}
+
+func (t *headingTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var headings []heading
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		id, ok := h.AttributeString("id")
+		if !ok {
+			return ast.WalkSkipChildren, nil
+		}
+		idStr := string(id.([]byte))
+
+		headings = append(headings, heading{level: h.Level, id: idStr, text: nodeText(h, reader.Source())})
+
+		if t.anchors {
+			link := ast.NewLink()
+			link.Destination = []byte("#" + idStr)
+			link.Title = []byte("Permalink")
+			link.SetAttributeString("class", []byte("heading-anchor"))
+			link.AppendChild(link, ast.NewString([]byte("#")))
+			h.AppendChild(h, link)
+		}
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	pc.Set(headingsKey, headings)
+}
+
+// nodeText returns the plain text of a node's inline children
+func nodeText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			b.Write(c.Segment.Value(source))
+			if c.SoftLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(c.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(b.String())
+}
+
+// buildTOC nests the headings no deeper than depth under their closest preceding parent heading
+func buildTOC(headings []heading, depth int) []*TOCEntry {
+	var toc []*TOCEntry
+	var stack []*TOCEntry
+
+	for _, h := range headings {
+		if h.level > depth {
+			continue
+		}
+
+		entry := &TOCEntry{ID: h.id, Text: h.text, Level: h.level}
+		for len(stack) > 0 && stack[len(stack)-1].Level >= h.level {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) == 0 {
+			toc = append(toc, entry)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, entry)
+		}
+		stack = append(stack, entry)
+	}
+
+	return toc
+}
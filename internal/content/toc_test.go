@@ -0,0 +1,103 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+const tocDocument = `---
+title: Guide
+---
+
+## Install
+
+### On Linux
+
+### On macOS
+
+## Usage
+
+### On Linux
+
+#### Flags
+`
+
+func TestMarkdownParser_TOC(t *testing.T) {
+	t.Run("Builds a nested TOC with unique heading IDs", func(t *testing.T) {
+		parsed, err := NewMarkdownParser().Parse([]byte(tocDocument))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(parsed.TOC) != 2 {
+			t.Fatalf("Expected 2 top level entries, got %d", len(parsed.TOC))
+		}
+
+		install, usage := parsed.TOC[0], parsed.TOC[1]
+		if install.ID != "install" || install.Text != "Install" || len(install.Children) != 2 {
+			t.Errorf("Unexpected first entry %+v", install)
+		}
+		if len(usage.Children) != 1 || usage.Children[0].ID != "on-linux-1" {
+			t.Errorf("Expected repeated heading to get a unique ID, got %+v", usage.Children)
+		}
+		if len(usage.Children[0].Children) != 0 {
+			t.Error("Expected headings deeper than the default depth to be left out")
+		}
+
+		for _, id := range []string{`id="install"`, `id="on-linux"`, `id="on-linux-1"`} {
+			if !strings.Contains(parsed.HTML, id) {
+				t.Errorf("Expected sanitized HTML to keep %s", id)
+			}
+		}
+	})
+
+	t.Run("Frontmatter can set the depth or disable the TOC", func(t *testing.T) {
+		deep := strings.Replace(tocDocument, "title: Guide", "title: Guide\ntoc_depth: 4", 1)
+		parsed, err := NewMarkdownParser().Parse([]byte(deep))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := parsed.TOC[1].Children[0].Children; len(got) != 1 || got[0].Text != "Flags" {
+			t.Errorf("Expected level 4 heading with toc_depth 4, got %+v", got)
+		}
+
+		off := strings.Replace(tocDocument, "title: Guide", "title: Guide\ntoc: false", 1)
+		parsed, err = NewMarkdownParser().Parse([]byte(off))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.TOC != nil {
+			t.Errorf("Expected no TOC when disabled, got %+v", parsed.TOC)
+		}
+	})
+
+	t.Run("Adds permalink anchors when enabled", func(t *testing.T) {
+		parsed, err := NewMarkdownParser(WithHeadingAnchors(true)).Parse([]byte(tocDocument))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(parsed.HTML, `href="#install"`) || !strings.Contains(parsed.HTML, `class="heading-anchor"`) {
+			t.Errorf("Expected heading anchors in HTML, got %s", parsed.HTML)
+		}
+		if parsed.TOC[0].Text != "Install" {
+			t.Errorf("Expected anchors to stay out of TOC text, got %q", parsed.TOC[0].Text)
+		}
+		if parsed.WordCount != 9 {
+			t.Errorf("Expected anchors to stay out of the word count, got %d", parsed.WordCount)
+		}
+	})
+
+	t.Run("Keeps absolute link URLs alongside in-page links", func(t *testing.T) {
+		parsed, err := NewMarkdownParser().Parse([]byte("[Docs](https://example.com/docs) and [mail](mailto:me@example.com)"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, href := range []string{`href="https://example.com/docs"`, `href="mailto:me@example.com"`} {
+			if !strings.Contains(parsed.HTML, href) {
+				t.Errorf("Expected HTML to keep %s, got %s", href, parsed.HTML)
+			}
+		}
+	})
+}
@@ -0,0 +1,357 @@
+package content
+
+import (
+	"bytes"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// LinkResolver turns the targets of wiki-links into URLs
+type LinkResolver interface {
+	// ResolveLink returns the URL of the post or page a [[target]] refers to
+	ResolveLink(target string) (string, bool)
+
+	// ResolveAttachment returns the URL of the attachment an ![[embed]] refers to
+	ResolveAttachment(name string) (string, bool)
+}
+
+// WithLinkResolver resolves wiki-links with r. Without a resolver every wiki-link is
+// rendered as unresolved. Content loaded through a Store is resolved against the store's
+// posts, pages and attachments instead.
+func WithLinkResolver(r LinkResolver) ParserOption {
+	return func(c *parserConfig) {
+		c.links = r
+	}
+}
+
+// linksKey is the parser context key holding the LinkResolver for the document being parsed
+var linksKey = parser.NewContextKey()
+
+// wikiLinksKey is set in the parser context once the document has a wiki-link
+var wikiLinksKey = parser.NewContextKey()
+
+// imageExtensions are the attachments embedded as images rather than linked to
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".svg": true, ".avif": true,
+}
+
+// KindWikiLink is the goldmark node kind of wiki-links
+var KindWikiLink = ast.NewNodeKind("WikiLink")
+
+// wikiLinkNode is an Obsidian style [[target|label]] link or ![[target|label]] embed
+type wikiLinkNode struct {
+	ast.BaseInline
+	target   string
+	label    string
+	embed    bool
+	url      string // Empty if the target could not be resolved
+	image    bool   // The target is an image attachment
+	resolved bool
+}
+
+func (n *wikiLinkNode) Kind() ast.NodeKind {
+	return KindWikiLink
+}
+
+func (n *wikiLinkNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Target": n.target, "URL": n.url}, nil)
+}
+
+// wikiLinkExtension adds wiki-links and Obsidian callouts to goldmark
+type wikiLinkExtension struct{}
+
+func (e *wikiLinkExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		// Ahead of the standard link parser, which also starts at '[' and '!'
+		parser.WithInlineParsers(util.Prioritized(&wikiLinkParser{}, 199)),
+		parser.WithASTTransformers(util.Prioritized(&calloutTransformer{}, 110)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&wikiLinkRenderer{}, 150),
+	))
+}
+
+// wikiLinkParser parses [[target]], [[target|label]] and ![[target]]
+type wikiLinkParser struct{}
+
+func (p *wikiLinkParser) Trigger() []byte {
+	return []byte{'!', '['}
+}
+
+func (p *wikiLinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+
+	embed := line[0] == '!'
+	open := 2
+	if embed {
+		open = 3
+	}
+	if len(line) < open || !bytes.HasPrefix(line[open-2:], []byte("[[")) {
+		return nil
+	}
+
+	end := bytes.Index(line[open:], []byte("]]"))
+	if end <= 0 {
+		return nil
+	}
+	inner := string(line[open : open+end])
+	if strings.ContainsAny(inner, "[]\n") {
+		return nil
+	}
+
+	target, label, _ := strings.Cut(inner, "|")
+	target, label = strings.TrimSpace(target), strings.TrimSpace(label)
+	if target == "" {
+		return nil
+	}
+
+	node := &wikiLinkNode{target: target, label: label, embed: embed}
+	links, _ := pc.Get(linksKey).(LinkResolver)
+	node.resolve(links)
+	pc.Set(wikiLinksKey, true)
+
+	if !node.resolved {
+		addWarning(pc, lineNumber(block.Source(), segment.Start), "unresolved wiki-link %q", target)
+	}
+
+	block.Advance(open + end + 2)
+	return node
+}
+
+// resolve finds the URL of the node's target, embeds of anything but attachments
+// fall back to a plain link
+func (n *wikiLinkNode) resolve(links LinkResolver) {
+	if links == nil {
+		return
+	}
+
+	if n.embed {
+		if u, ok := links.ResolveAttachment(n.target); ok {
+			n.url, n.resolved = u, true
+			n.image = imageExtensions[strings.ToLower(path.Ext(n.target))]
+			return
+		}
+	}
+
+	target, fragment, _ := strings.Cut(n.target, "#")
+	if target == "" {
+		// [[#Heading]] links within the current document
+		n.url, n.resolved = "#"+headingID(fragment), true
+		return
+	}
+	if u, ok := links.ResolveLink(target); ok {
+		n.url, n.resolved = u, true
+		if fragment != "" {
+			n.url += "#" + headingID(fragment)
+		}
+	}
+}
+
+// headingID returns the ID goldmark's automatic heading IDs give a heading reading text, so
+// links to headings find them whatever characters they hold
+func headingID(text string) string {
+	return string(parser.NewContext().IDs().Generate([]byte(text), ast.KindHeading))
+}
+
+// text returns what the link reads as: the label, or the target without its heading
+func (n *wikiLinkNode) text() string {
+	if n.label != "" {
+		return n.label
+	}
+	target, fragment, _ := strings.Cut(n.target, "#")
+	if target == "" {
+		return fragment
+	}
+	return target
+}
+
+// wikiLinkRenderer renders wiki-links as links or images, and unresolved ones as
+// a span with the wikilink-unresolved class for themes to style. It also renders callouts.
+type wikiLinkRenderer struct{}
+
+func (r *wikiLinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindWikiLink, r.render)
+	reg.Register(KindCallout, renderCallout)
+}
+
+func (r *wikiLinkRenderer) render(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*wikiLinkNode)
+
+	switch {
+	case !n.resolved:
+		w.WriteString(`<span class="wikilink wikilink-unresolved">`)
+		w.Write(util.EscapeHTML([]byte(n.text())))
+		w.WriteString(`</span>`)
+
+	case n.image:
+		// Obsidian uses a numeric label for the width: ![[photo.png|300]]
+		alt, width := n.label, 0
+		if px, err := strconv.Atoi(alt); err == nil && px > 0 {
+			alt, width = "", px
+		}
+		if alt == "" {
+			alt = path.Base(n.target)
+		}
+
+		w.WriteString(`<img class="wikilink-embed" src="`)
+		w.Write(util.EscapeHTML(util.URLEscape([]byte(n.url), false)))
+		w.WriteString(`" alt="`)
+		w.Write(util.EscapeHTML([]byte(alt)))
+		if width > 0 {
+			w.WriteString(`" width="` + strconv.Itoa(width))
+		}
+		w.WriteString(`">`)
+
+	default:
+		w.WriteString(`<a class="wikilink" href="`)
+		w.Write(util.EscapeHTML(util.URLEscape([]byte(n.url), false)))
+		w.WriteString(`">`)
+		w.Write(util.EscapeHTML([]byte(n.text())))
+		w.WriteString(`</a>`)
+	}
+
+	return ast.WalkSkipChildren, nil
+}
+
+// calloutTypes maps Obsidian callout types to the callout classes themes style,
+// anything else is a note
+var calloutTypes = map[string]string{
+	"tip": "tip", "hint": "tip", "success": "tip", "check": "tip", "done": "tip",
+	"warning": "warn", "warn": "warn", "caution": "warn", "attention": "warn",
+	"danger": "danger", "error": "danger", "bug": "danger", "failure": "danger", "fail": "danger",
+}
+
+// KindCallout is the goldmark node kind of Obsidian callouts
+var KindCallout = ast.NewNodeKind("Callout")
+
+// calloutNode is a blockquote starting with [!type] Title, holding the rest of the blockquote
+type calloutNode struct {
+	ast.BaseBlock
+	class string
+	title string
+}
+
+func (n *calloutNode) Kind() ast.NodeKind {
+	return KindCallout
+}
+
+func (n *calloutNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Class": n.class, "Title": n.title}, nil)
+}
+
+// calloutTransformer turns blockquotes like
+//
+//	> [!warning] Mind the gap
+//	> Text of the callout
+//
+// into callouts rendered like the callout shortcode
+type calloutTransformer struct{}
+
+func (t *calloutTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	var quotes []*ast.Blockquote
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if quote, ok := node.(*ast.Blockquote); ok && entering {
+			quotes = append(quotes, quote)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, quote := range quotes {
+		paragraph, ok := quote.FirstChild().(*ast.Paragraph)
+		if !ok || paragraph.Lines().Len() == 0 {
+			continue
+		}
+
+		first := paragraph.Lines().At(0)
+		kind, title, ok := parseCalloutLine(first.Value(source))
+		if !ok {
+			continue
+		}
+
+		callout := &calloutNode{class: calloutTypes[kind], title: title}
+		if callout.class == "" {
+			callout.class = "note"
+		}
+		if callout.title == "" {
+			callout.title = strings.ToUpper(kind[:1]) + kind[1:]
+		}
+
+		// Drop the inline nodes of the [!type] line, the title is rendered separately
+		for child := paragraph.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			if start, ok := inlineStart(child); ok && start >= first.Stop {
+				break
+			}
+			paragraph.RemoveChild(paragraph, child)
+			child = next
+		}
+		if !paragraph.HasChildren() {
+			quote.RemoveChild(quote, paragraph)
+		}
+
+		for child := quote.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			callout.AppendChild(callout, child)
+			child = next
+		}
+		quote.Parent().ReplaceChild(quote.Parent(), quote, callout)
+	}
+}
+
+// parseCalloutLine parses "[!type] Title", where the type may be followed by + or -
+// as Obsidian uses them to mark foldable callouts
+func parseCalloutLine(line []byte) (kind, title string, ok bool) {
+	rest, found := strings.CutPrefix(strings.TrimSpace(string(line)), "[!")
+	if !found {
+		return "", "", false
+	}
+	kind, rest, found = strings.Cut(rest, "]")
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if !found || kind == "" || strings.ContainsAny(kind, " \t") {
+		return "", "", false
+	}
+
+	rest = strings.TrimLeft(rest, "+-")
+	return kind, strings.TrimSpace(rest), true
+}
+
+// inlineStart returns the source offset an inline node starts at, if it has any text
+func inlineStart(node ast.Node) (int, bool) {
+	if t, ok := node.(*ast.Text); ok {
+		return t.Segment.Start, true
+	}
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if start, ok := inlineStart(child); ok {
+			return start, true
+		}
+	}
+	return 0, false
+}
+
+// renderCallout renders callouts with the same markup as the callout shortcode
+func renderCallout(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*calloutNode)
+	if !entering {
+		w.WriteString("</div>\n")
+		return ast.WalkContinue, nil
+	}
+
+	w.WriteString(`<div class="callout callout-` + n.class + `">` + "\n")
+	w.WriteString(`<p class="callout-title">`)
+	w.Write(util.EscapeHTML([]byte(n.title)))
+	w.WriteString("</p>\n")
+	return ast.WalkContinue, nil
+}
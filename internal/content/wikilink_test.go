@@ -0,0 +1,176 @@
+package content
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testLinks resolves the slugs it holds as posts and every .png as an attachment
+type testLinks map[string]bool
+
+func (l testLinks) ResolveLink(target string) (string, bool) {
+	if l[target] {
+		return "/blog/" + target, true
+	}
+	return "", false
+}
+
+func (l testLinks) ResolveAttachment(name string) (string, bool) {
+	if strings.HasSuffix(name, ".png") {
+		return "/images/" + name, true
+	}
+	return "", false
+}
+
+func TestMarkdownParser_WikiLinks(t *testing.T) {
+	parser := NewMarkdownParser(WithLinkResolver(testLinks{"hello": true}))
+
+	t.Run("Resolves links, labels, headings and embeds", func(t *testing.T) {
+		parsed, err := parser.Parse([]byte("See [[hello]], [[hello|the intro]] and [[hello#Getting Started]].\n\n![[cat.png]] ![[dog.png|300]]\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, want := range []string{
+			`<a class="wikilink" href="/blog/hello">hello</a>`,
+			`<a class="wikilink" href="/blog/hello">the intro</a>`,
+			`<a class="wikilink" href="/blog/hello#getting-started">hello</a>`,
+			`<img class="wikilink-embed" src="/images/cat.png" alt="cat.png">`,
+			`<img class="wikilink-embed" src="/images/dog.png" alt="dog.png" width="300">`,
+		} {
+			if !strings.Contains(parsed.HTML, want) {
+				t.Errorf("Expected HTML to contain %s, got %s", want, parsed.HTML)
+			}
+		}
+		if len(parsed.Warnings) != 0 || !parsed.hasWikiLinks {
+			t.Errorf("Expected wiki-links without warnings, got %v", parsed.Warnings)
+		}
+	})
+
+	t.Run("Marks and reports unresolved links", func(t *testing.T) {
+		parsed, err := parser.Parse([]byte("---\ntitle: Test\n---\n\nFirst line\nA [[missing|link]] and ![[notes.pdf]]\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(parsed.HTML, `<span class="wikilink wikilink-unresolved">link</span>`) {
+			t.Errorf("Expected an unresolved link, got %s", parsed.HTML)
+		}
+		if len(parsed.Warnings) != 2 || parsed.Warnings[0].Line != 6 || !strings.Contains(parsed.Warnings[0].Message, `"missing"`) {
+			t.Errorf("Expected two warnings on line 6, got %v", parsed.Warnings)
+		}
+	})
+
+	t.Run("Links headings by the IDs they are given", func(t *testing.T) {
+		parsed, err := parser.Parse([]byte("## Don't_panic: v2\n\nSee [[#Don't_panic: v2]] and [[hello#Don't_panic: v2]].\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, want := range []string{
+			`<h2 id="dont-panic-v2">`,
+			`<a class="wikilink" href="#dont-panic-v2">Don&#39;t_panic: v2</a>`,
+			`<a class="wikilink" href="/blog/hello#dont-panic-v2">hello</a>`,
+		} {
+			if !strings.Contains(parsed.HTML, want) {
+				t.Errorf("Expected HTML to contain %s, got %s", want, parsed.HTML)
+			}
+		}
+	})
+
+	t.Run("Leaves code and regular links alone", func(t *testing.T) {
+		parsed, err := parser.Parse([]byte("`[[hello]]` and [a link](/about) and ![img](/a.png)\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Contains(parsed.HTML, "wikilink") || parsed.hasWikiLinks {
+			t.Errorf("Expected no wiki-links, got %s", parsed.HTML)
+		}
+		if !strings.Contains(parsed.HTML, `<code>[[hello]]</code>`) || !strings.Contains(parsed.HTML, `<a href="/about">a link</a>`) {
+			t.Errorf("Expected code and links untouched, got %s", parsed.HTML)
+		}
+	})
+}
+
+func TestMarkdownParser_Callouts(t *testing.T) {
+	parsed, err := NewMarkdownParser().Parse([]byte("> [!warning] Mind the gap\n> Between **train** and platform.\n\n> [!tip]-\n> Folded.\n\n> [!faq]\n\n> Just a quote\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"<div class=\"callout callout-warn\">\n<p class=\"callout-title\">Mind the gap</p>\n<p>Between <strong>train</strong> and platform.</p>\n</div>",
+		"<div class=\"callout callout-tip\">\n<p class=\"callout-title\">Tip</p>\n<p>Folded.</p>\n</div>",
+		"<div class=\"callout callout-note\">\n<p class=\"callout-title\">Faq</p>\n</div>",
+		"<blockquote>\n<p>Just a quote</p>\n</blockquote>",
+	} {
+		if !strings.Contains(parsed.HTML, want) {
+			t.Errorf("Expected HTML to contain %q, got %s", want, parsed.HTML)
+		}
+	}
+}
+
+func TestStore_WikiLinks(t *testing.T) {
+	store, dataDir := newTestStore(t)
+
+	writeContentFile(t, filepath.Join(dataDir, "blog", "first.md"), "---\ntitle: First\ndate: 2024-01-15T10:00:00Z\n---\n\nSee [[second]], [[About Me]] and ![[cat.png]]")
+	writeContentFile(t, filepath.Join(dataDir, "pages", "about-me.md"), "---\ntitle: About\n---\n\nAbout")
+	writeContentFile(t, filepath.Join(dataDir, "attachments", "photos", "cat.png"), "png")
+
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := store.Post("first")
+	if !strings.Contains(first.HTML, `href="/about-me"`) || !strings.Contains(first.HTML, `src="/images/photos/cat.png"`) {
+		t.Errorf("Expected links to the page and attachment, got %s", first.HTML)
+	}
+	if !strings.Contains(first.HTML, "wikilink-unresolved") {
+		t.Errorf("Expected the link to the missing post to be unresolved, got %s", first.HTML)
+	}
+
+	second := filepath.Join(dataDir, "blog", "second.md")
+	writeContentFile(t, second, "---\ntitle: Second\ndate: 2024-02-15T10:00:00Z\n---\n\nSecond")
+	store.HandleFileChange(second)
+
+	first, _ = store.Post("first")
+	if !strings.Contains(first.HTML, `href="/blog/second"`) || strings.Contains(first.HTML, "wikilink-unresolved") {
+		t.Errorf("Expected the link to resolve once the post exists, got %s", first.HTML)
+	}
+
+	if err := os.Remove(second); err != nil {
+		t.Fatal(err)
+	}
+	store.HandleFileChange(second)
+
+	first, _ = store.Post("first")
+	if !strings.Contains(first.HTML, "wikilink-unresolved") {
+		t.Errorf("Expected the link to break once the post is removed, got %s", first.HTML)
+	}
+}
+
+func TestStore_WikiLinkAttachments(t *testing.T) {
+	store, dataDir := newTestStore(t)
+
+	writeContentFile(t, filepath.Join(dataDir, "blog", "first.md"), "---\ntitle: First\ndate: 2024-01-15T10:00:00Z\n---\n\n![[dog.png]]")
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := store.Post("first")
+	if strings.Contains(first.HTML, `src="/images/`) {
+		t.Errorf("Expected the embed of a missing attachment to be unresolved, got %s", first.HTML)
+	}
+
+	attachment := filepath.Join(dataDir, "attachments", "pets", "dog.png")
+	writeContentFile(t, attachment, "png")
+	store.HandleFileChange(attachment)
+
+	first, _ = store.Post("first")
+	if !strings.Contains(first.HTML, `src="/images/pets/dog.png"`) {
+		t.Errorf("Expected the embed to resolve once the attachment exists, got %s", first.HTML)
+	}
+}
@@ -111,15 +111,21 @@ func GenerateRSS(posts []*content.Content, config Config, feedURL string, limit
 
 	// Add items
 	for _, post := range posts {
-		// Skip draft posts
-		if post.Frontmatter.Draft {
+		// Skip drafts, scheduled and expired posts
+		if !post.IsPublished(time.Now()) {
 			continue
 		}
 
+		// Fall back to the generated excerpt when there's no description
+		description := post.Frontmatter.Description
+		if description == "" {
+			description = post.Excerpt
+		}
+
 		item := &Item{
 			Title:       post.Frontmatter.Title,
 			Link:        fmt.Sprintf("%s/blog/%s", config.Link, post.Frontmatter.Slug),
-			Description: post.Frontmatter.Description,
+			Description: description,
 			PubDate:     formatRFC822(post.Frontmatter.Date),
 			GUID: &GUID{
 				IsPermaLink: true,
@@ -162,6 +162,33 @@ func TestGenerateRSSLimit(t *testing.T) {
 	}
 }
 
+func TestGenerateRSSExcerptFallback(t *testing.T) {
+	posts := []*content.Content{
+		{
+			Frontmatter: content.Frontmatter{
+				Title: "No Description",
+				Slug:  "no-description",
+				Date:  time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+			},
+			Excerpt: "Generated from the body",
+		},
+	}
+
+	rssData, err := GenerateRSS(posts, Config{Title: "Test Blog", Link: "https://example.com"}, "https://example.com/rss", 20)
+	if err != nil {
+		t.Fatalf("GenerateRSS failed: %v", err)
+	}
+
+	var rss RSS
+	if err := xml.Unmarshal(rssData, &rss); err != nil {
+		t.Fatalf("Failed to parse generated RSS: %v", err)
+	}
+
+	if got := rss.Channel.Items[0].Description; got != "Generated from the body" {
+		t.Errorf("Expected description to fall back to the excerpt, got '%s'", got)
+	}
+}
+
 func TestGenerateRSSValidXML(t *testing.T) {
 	posts := []*content.Content{
 		{
@@ -0,0 +1,335 @@
+// Package i18n loads a theme's translation catalogs and formats messages, dates, relative
+// times and numbers for a language with golang.org/x/text/message.
+//
+// Catalogs are YAML files named after their language, such as i18n/fr.yaml, mapping message
+// keys to a format string, or to plural forms keyed by zero, one, two, few, many, other, =N
+// or <N:
+//
+//	back_to_blog: Retour au blog
+//	post_count:
+//	  one: "%d article"
+//	  other: "%d articles"
+//	months: [janvier, février, mars, …]
+//
+// The lists months, months_short, days and days_short (Sunday first) name months and
+// weekdays in formatted dates. Messages missing from a language fall back to its parent
+// language, such as fr for fr-ca, then to the English catalog.
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+	"golang.org/x/text/number"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceLanguage is the language themes are written in, whose catalog every other falls back to
+const SourceLanguage = "en"
+
+// dateLists are the catalog lists naming months and weekdays, the layout element each
+// replaces, its English names and the index into it of a time's month or weekday
+var dateLists = []struct {
+	list    string
+	element string
+	names   []string
+	index   func(time.Time) int
+}{
+	{"months", "January", monthNames(time.Month.String), monthIndex},
+	{"months_short", "Jan", monthNames(func(m time.Month) string { return m.String()[:3] }), monthIndex},
+	{"days", "Monday", dayNames(time.Weekday.String), dayIndex},
+	{"days_short", "Mon", dayNames(func(d time.Weekday) string { return d.String()[:3] }), dayIndex},
+}
+
+func monthNames(name func(time.Month) string) []string {
+	names := make([]string, 12)
+	for m := time.January; m <= time.December; m++ {
+		names[m-1] = name(m)
+	}
+	return names
+}
+
+func dayNames(name func(time.Weekday) string) []string {
+	names := make([]string, 7)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		names[d] = name(d)
+	}
+	return names
+}
+
+func monthIndex(t time.Time) int { return int(t.Month()) - 1 }
+
+func dayIndex(t time.Time) int { return int(t.Weekday()) }
+
+// dateKey returns the catalog key of the i-th name in one of the dateLists
+func dateKey(list string, i int) string {
+	return "date." + list + "." + strconv.Itoa(i)
+}
+
+// Bundle holds the messages of a theme's catalogs in every language
+type Bundle struct {
+	catalog *catalog.Builder
+	keys    map[string]bool
+}
+
+// New returns a bundle holding only the built-in English messages for relative times and
+// date names
+func New() *Bundle {
+	b := &Bundle{
+		catalog: catalog.NewBuilder(),
+		keys:    make(map[string]bool),
+	}
+
+	b.set(language.Und, "ago", catalog.String("%s ago"))
+	for key, unit := range map[string]string{
+		"duration.years":   "year",
+		"duration.days":    "day",
+		"duration.hours":   "hour",
+		"duration.minutes": "minute",
+		"duration.seconds": "second",
+	} {
+		b.set(language.Und, key, plural.Selectf(1, "%d", "=1", "%d "+unit, "other", "%d "+unit+"s"))
+	}
+	b.set(language.Und, "duration.moment", catalog.String("less than 1 second"))
+
+	for _, dates := range dateLists {
+		b.setDateNames(language.Und, dates.list, dates.names)
+	}
+	return b
+}
+
+// Load reads the catalogs in each directory in turn, so messages in later directories
+// override those in earlier ones. Directories that don't exist are skipped.
+func Load(dirs ...string) (*Bundle, error) {
+	b := New()
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if err := b.loadFile(file); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b, nil
+}
+
+// loadFile adds the messages of the catalog in path, whose name is its language. The source
+// language's messages are what every language falls back to, so they replace the built-ins.
+func (b *Bundle) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read translations %s: %w", path, err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	tag, err := language.Parse(name)
+	if err != nil {
+		return fmt.Errorf("translations %s: invalid language %q: %w", path, name, err)
+	}
+	if tag == language.Make(SourceLanguage) {
+		tag = language.Und
+	}
+
+	var messages map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &messages); err != nil {
+		return fmt.Errorf("failed to parse translations %s: %w", path, err)
+	}
+
+	for key, node := range messages {
+		if err := b.setNode(tag, key, &node); err != nil {
+			return fmt.Errorf("translations %s: %s: %w", path, key, err)
+		}
+	}
+	return nil
+}
+
+// setNode adds the message for key from its YAML value
+func (b *Bundle) setNode(tag language.Tag, key string, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return b.set(tag, key, catalog.String(node.Value))
+
+	case yaml.SequenceNode:
+		for _, dates := range dateLists {
+			if dates.list != key {
+				continue
+			}
+			var names []string
+			if err := node.Decode(&names); err != nil {
+				return err
+			}
+			if len(names) != len(dates.names) {
+				return fmt.Errorf("expected %d names, got %d", len(dates.names), len(names))
+			}
+			return b.setDateNames(tag, key, names)
+		}
+		return errors.New("only months, months_short, days and days_short can be lists")
+
+	case yaml.MappingNode:
+		cases, err := pluralCases(tag, node)
+		if err != nil {
+			return err
+		}
+		return b.set(tag, key, plural.Selectf(1, "", cases...))
+	}
+
+	return fmt.Errorf("unexpected YAML %s", node.Tag)
+}
+
+// pluralCases returns the selectors and messages of a plural message for plural.Selectf,
+// exact matches first and other last so each applies before the catch-alls. The source
+// language's messages are kept under language.Und, which only has the other form, so its
+// one form becomes =1.
+func pluralCases(tag language.Tag, node *yaml.Node) ([]any, error) {
+	var forms map[string]string
+	if err := node.Decode(&forms); err != nil {
+		return nil, err
+	}
+	if _, found := forms["other"]; !found {
+		return nil, errors.New("plural forms need an other form")
+	}
+
+	if msg, found := forms["one"]; found && tag == language.Und {
+		forms["=1"] = msg
+		delete(forms, "one")
+	}
+
+	var exact, categories []any
+	for _, selector := range []string{"zero", "one", "two", "few", "many", "other"} {
+		if msg, found := forms[selector]; found {
+			categories = append(categories, selector, msg)
+			delete(forms, selector)
+		}
+	}
+	for selector, msg := range forms {
+		if len(selector) < 2 || (selector[0] != '=' && selector[0] != '<') {
+			return nil, fmt.Errorf("unknown plural form %q", selector)
+		}
+		if _, err := strconv.Atoi(selector[1:]); err != nil {
+			return nil, fmt.Errorf("unknown plural form %q", selector)
+		}
+		exact = append(exact, selector, msg)
+	}
+	return append(exact, categories...), nil
+}
+
+func (b *Bundle) set(tag language.Tag, key string, msg catalog.Message) error {
+	if err := b.catalog.Set(tag, key, msg); err != nil {
+		return err
+	}
+	b.keys[key] = true
+	return nil
+}
+
+// setDateNames adds the names in one of the dateLists
+func (b *Bundle) setDateNames(tag language.Tag, list string, names []string) error {
+	for i, name := range names {
+		if err := b.set(tag, dateKey(list, i), catalog.String(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Locale returns the formatting functions for lang, falling back to English for messages
+// the catalogs don't translate
+func (b *Bundle) Locale(lang string) *Locale {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		tag = language.Make(SourceLanguage)
+	}
+	return &Locale{
+		bundle:  b,
+		printer: message.NewPrinter(tag, message.Catalog(b.catalog)),
+	}
+}
+
+// Locale formats messages, dates, relative times and numbers in one language
+type Locale struct {
+	bundle  *Bundle
+	printer *message.Printer
+}
+
+// T returns the message for key formatted with args, choosing plural forms by the first
+// argument. Keys missing from every catalog are returned as they are.
+func (l *Locale) T(key string, args ...any) string {
+	if !l.bundle.keys[key] {
+		return key
+	}
+	return l.printer.Sprintf(key, args...)
+}
+
+// FormatDate formats t with a time.Format layout, naming months and weekdays in the locale's
+// language
+func (l *Locale) FormatDate(t time.Time, layout string) string {
+	var sb strings.Builder
+	for layout != "" {
+		i, j := nextDateName(layout)
+		sb.WriteString(t.Format(layout[:i]))
+		if j < 0 {
+			break
+		}
+		dates := dateLists[j]
+		sb.WriteString(l.printer.Sprintf(dateKey(dates.list, dates.index(t))))
+		layout = layout[i+len(dates.element):]
+	}
+	return sb.String()
+}
+
+// nextDateName returns the index in layout of its first month or weekday name element and
+// the index of the list naming it, or the length of layout and -1 if it has none
+func nextDateName(layout string) (int, int) {
+	for i := range layout {
+		for j, dates := range dateLists {
+			if strings.HasPrefix(layout[i:], dates.element) {
+				return i, j
+			}
+		}
+	}
+	return len(layout), -1
+}
+
+// HumanizeTime returns how long ago t was, such as 3 days ago
+func (l *Locale) HumanizeTime(t time.Time) string {
+	return l.T("ago", l.Duration(time.Since(t)))
+}
+
+// Duration returns an approximation of d in its largest whole unit, such as 2 hours
+func (l *Locale) Duration(d time.Duration) string {
+	const (
+		day  = 24 * time.Hour
+		year = 365 * day
+	)
+
+	switch {
+	case d >= year:
+		return l.T("duration.years", int(d/year))
+	case d >= day:
+		return l.T("duration.days", int(d/day))
+	case d >= time.Hour:
+		return l.T("duration.hours", int(d/time.Hour))
+	case d >= time.Minute:
+		return l.T("duration.minutes", int(d/time.Minute))
+	case d >= time.Second:
+		return l.T("duration.seconds", int(d/time.Second))
+	default:
+		return l.T("duration.moment")
+	}
+}
+
+// FormatNumber formats n with the locale's digit grouping and decimal separator
+func (l *Locale) FormatNumber(n any) string {
+	return l.printer.Sprint(number.Decimal(n))
+}
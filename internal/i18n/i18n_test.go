@@ -0,0 +1,147 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeCatalog(t *testing.T, dir, lang, data string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, lang+".yaml"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	defaultDir := filepath.Join(t.TempDir(), "default")
+	themeDir := filepath.Join(t.TempDir(), "theme")
+	writeCatalog(t, defaultDir, "en", "back: Back to Blog\nhome: Home\nposts:\n  one: \"%d post\"\n  other: \"%d posts\"\n")
+	writeCatalog(t, defaultDir, "fr", "back: Retour\nposts:\n  \"=0\": aucun article\n  one: \"%d article\"\n  other: \"%d articles\"\n")
+	writeCatalog(t, themeDir, "fr", "back: Retour au blog\n")
+
+	bundle, err := Load(defaultDir, themeDir, filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	en, fr, frCA := bundle.Locale("en-us"), bundle.Locale("fr"), bundle.Locale("fr-ca")
+	tests := []struct {
+		locale *Locale
+		key    string
+		args   []any
+		want   string
+	}{
+		{en, "back", nil, "Back to Blog"},
+		{fr, "back", nil, "Retour au blog"},
+		{frCA, "back", nil, "Retour au blog"},
+		{fr, "home", nil, "Home"},
+		{en, "posts", []any{1}, "1 post"},
+		{en, "posts", []any{1200}, "1,200 posts"},
+		{fr, "posts", []any{0}, "aucun article"},
+		{fr, "posts", []any{1}, "1 article"},
+		{fr, "posts", []any{3}, "3 articles"},
+		{fr, "missing", []any{3}, "missing"},
+	}
+	for _, tt := range tests {
+		if got := tt.locale.T(tt.key, tt.args...); got != tt.want {
+			t.Errorf("T(%q, %v) = %q, want %q", tt.key, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name, lang, data, want string
+	}{
+		{"invalid language", "not a language", "a: b", "invalid language"},
+		{"plural without other", "fr", "a:\n  one: un\n", "need an other form"},
+		{"unknown plural form", "fr", "a:\n  some: un\n  other: autres\n", `unknown plural form "some"`},
+		{"plural form the language lacks", "de", "a:\n  few: wenige\n  other: andere\n", `form "few" not supported`},
+		{"unknown list", "fr", "a: [b, c]\n", "can be lists"},
+		{"short month list", "fr", "months: [janvier]\n", "expected 12 names, got 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeCatalog(t, dir, tt.lang, tt.data)
+			_, err := Load(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLocale_FormatDate(t *testing.T) {
+	dir := t.TempDir()
+	writeCatalog(t, dir, "fr", `months: [janvier, février, mars, avril, mai, juin, juillet, août, septembre, octobre, novembre, décembre]
+months_short: [janv., févr., mars, avr., mai, juin, juil., août, sept., oct., nov., déc.]
+days: [dimanche, lundi, mardi, mercredi, jeudi, vendredi, samedi]
+`)
+	bundle, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := time.Date(2024, time.February, 5, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		lang, layout, want string
+	}{
+		{"en", "January 2, 2006", "February 5, 2024"},
+		{"en", "Mon, 02 Jan 2006", "Mon, 05 Feb 2024"},
+		{"fr", "Monday 2 January 2006", "lundi 5 février 2024"},
+		{"fr", "2 Jan 2006 15:04", "5 févr. 2024 09:30"},
+		{"fr", "Mon 2006-01-02", "Mon 2024-02-05"},
+		{"fr", "2006", "2024"},
+	}
+	for _, tt := range tests {
+		if got := bundle.Locale(tt.lang).FormatDate(date, tt.layout); got != tt.want {
+			t.Errorf("FormatDate(%q) in %s = %q, want %q", tt.layout, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestLocale_HumanizeTime(t *testing.T) {
+	dir := t.TempDir()
+	writeCatalog(t, dir, "fr", "ago: il y a %s\nduration.days:\n  one: \"%d jour\"\n  other: \"%d jours\"\n")
+	bundle, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	threeDays := time.Now().Add(-3*24*time.Hour - time.Minute)
+	if got, want := bundle.Locale("en").HumanizeTime(threeDays), "3 days ago"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got, want := bundle.Locale("fr").HumanizeTime(threeDays), "il y a 3 jours"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got, want := bundle.Locale("fr").Duration(time.Hour), "1 hour"; got != want {
+		t.Errorf("Expected untranslated units in English, got %q", got)
+	}
+}
+
+func TestLocale_FormatNumber(t *testing.T) {
+	bundle := New()
+	tests := []struct {
+		lang string
+		n    any
+		want string
+	}{
+		{"en", 1234567, "1,234,567"},
+		{"en", 1234.5, "1,234.5"},
+		{"de", 1234.5, "1.234,5"},
+		{"fr", 12, "12"},
+	}
+	for _, tt := range tests {
+		if got := bundle.Locale(tt.lang).FormatNumber(tt.n); got != tt.want {
+			t.Errorf("FormatNumber(%v) in %s = %q, want %q", tt.n, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package pagination
+
+import "fmt"
+
+// DefaultPerPage is used when a non-positive page size is configured
+const DefaultPerPage = 10
+
+// PageLink represents a single numbered link in a page navigation
+type PageLink struct {
+	Number  int
+	URL     string
+	Current bool
+}
+
+// Pagination describes one page of a paginated listing for templates
+type Pagination struct {
+	CurrentPage int
+	TotalPages  int
+	PerPage     int
+	TotalItems  int
+	HasPrev     bool
+	HasNext     bool
+	PrevPage    int
+	NextPage    int
+	PrevURL     string
+	NextURL     string
+	Pages       []PageLink // Page numbers to show around the current page
+
+	baseURL string
+}
+
+// New builds the pagination for the given page of a listing served at baseURL.
+// The first page lives at baseURL and later pages at baseURL/page/{n}.
+// context controls how many page links are shown either side of the current page.
+// A listing with no items still has a single, empty first page.
+func New(baseURL string, totalItems, perPage, currentPage, context int) *Pagination {
+	if perPage <= 0 {
+		perPage = DefaultPerPage
+	}
+
+	totalPages := (totalItems + perPage - 1) / perPage
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	p := &Pagination{
+		CurrentPage: currentPage,
+		TotalPages:  totalPages,
+		PerPage:     perPage,
+		TotalItems:  totalItems,
+		HasPrev:     currentPage > 1,
+		HasNext:     currentPage < totalPages,
+		baseURL:     baseURL,
+	}
+
+	if p.HasPrev {
+		p.PrevPage = currentPage - 1
+		p.PrevURL = p.URL(p.PrevPage)
+	}
+	if p.HasNext {
+		p.NextPage = currentPage + 1
+		p.NextURL = p.URL(p.NextPage)
+	}
+
+	first := max(1, currentPage-context)
+	last := min(totalPages, currentPage+context)
+	for n := first; n <= last; n++ {
+		p.Pages = append(p.Pages, PageLink{Number: n, URL: p.URL(n), Current: n == currentPage})
+	}
+
+	return p
+}
+
+// URL returns the path of the given page number
+func (p *Pagination) URL(page int) string {
+	if page <= 1 {
+		if p.baseURL == "" {
+			return "/"
+		}
+		return p.baseURL
+	}
+	return fmt.Sprintf("%s/page/%d", p.baseURL, page)
+}
+
+// FirstURL returns the path of the first page
+func (p *Pagination) FirstURL() string {
+	return p.URL(1)
+}
+
+// LastURL returns the path of the last page
+func (p *Pagination) LastURL() string {
+	return p.URL(p.TotalPages)
+}
+
+// IsValid reports whether the current page is within the listing
+func (p *Pagination) IsValid() bool {
+	return p.CurrentPage >= 1 && p.CurrentPage <= p.TotalPages
+}
+
+// Offset returns the index of the first item on the current page
+func (p *Pagination) Offset() int {
+	return (p.CurrentPage - 1) * p.PerPage
+}
+
+// Paginate returns the items on the current page of p
+func Paginate[T any](items []T, p *Pagination) []T {
+	start := p.Offset()
+	if start < 0 || start >= len(items) {
+		return nil
+	}
+	end := min(start+p.PerPage, len(items))
+	return items[start:end]
+}
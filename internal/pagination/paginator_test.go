@@ -0,0 +1,63 @@
+package pagination
+
+import "testing"
+
+func TestNew(t *testing.T) {
+	p := New("/blog", 25, 10, 2, 2)
+
+	if p.TotalPages != 3 {
+		t.Errorf("Expected 3 pages, got %d", p.TotalPages)
+	}
+	if !p.HasPrev || p.PrevURL != "/blog" {
+		t.Errorf("Expected previous page to link to /blog, got %q", p.PrevURL)
+	}
+	if !p.HasNext || p.NextURL != "/blog/page/3" {
+		t.Errorf("Expected next page to link to /blog/page/3, got %q", p.NextURL)
+	}
+	if len(p.Pages) != 3 || !p.Pages[1].Current {
+		t.Errorf("Expected 3 page links with the second current, got %+v", p.Pages)
+	}
+	if !p.IsValid() {
+		t.Error("Expected page 2 of 3 to be valid")
+	}
+}
+
+func TestNew_Bounds(t *testing.T) {
+	tests := []struct {
+		name       string
+		totalItems int
+		perPage    int
+		current    int
+		wantPages  int
+		wantValid  bool
+	}{
+		{"empty listing has one page", 0, 10, 1, 1, true},
+		{"exact multiple", 20, 10, 2, 2, true},
+		{"past the last page", 20, 10, 3, 2, false},
+		{"page zero", 20, 10, 0, 2, false},
+		{"default page size", 15, 0, 2, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New("/blog", tt.totalItems, tt.perPage, tt.current, 2)
+			if p.TotalPages != tt.wantPages {
+				t.Errorf("Expected %d pages, got %d", tt.wantPages, p.TotalPages)
+			}
+			if p.IsValid() != tt.wantValid {
+				t.Errorf("Expected IsValid() = %t", tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	if got := Paginate(items, New("/blog", len(items), 2, 3, 2)); len(got) != 1 || got[0] != 5 {
+		t.Errorf("Expected last page to contain [5], got %v", got)
+	}
+	if got := Paginate(items, New("/blog", len(items), 2, 4, 2)); got != nil {
+		t.Errorf("Expected no items past the last page, got %v", got)
+	}
+}
@@ -0,0 +1,68 @@
+package preview
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryParam is the query string parameter carrying a preview token
+const QueryParam = "preview"
+
+var (
+	ErrInvalidToken = errors.New("invalid preview token")
+	ErrExpiredToken = errors.New("preview token has expired")
+)
+
+// Sign returns a token granting preview access to path until expires.
+// The token is the expiry as a unix timestamp followed by an HMAC-SHA256 of the path and expiry.
+func Sign(path string, expires time.Time, secretKey string) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + "." + base64.RawURLEncoding.EncodeToString(signature(path, exp, secretKey))
+}
+
+// Verify checks that token was signed for path and has not expired at now
+func Verify(path, token string, now time.Time, secretKey string) error {
+	exp, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidToken
+	}
+
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	if !hmac.Equal(got, signature(path, exp, secretKey)) {
+		return ErrInvalidToken
+	}
+
+	if !now.Before(time.Unix(unix, 0)) {
+		return ErrExpiredToken
+	}
+
+	return nil
+}
+
+// URL returns a shareable link previewing path on baseURL until expires
+func URL(baseURL, path string, expires time.Time, secretKey string) string {
+	query := url.Values{QueryParam: {Sign(path, expires, secretKey)}}
+	return strings.TrimRight(baseURL, "/") + path + "?" + query.Encode()
+}
+
+func signature(path, exp, secretKey string) []byte {
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write([]byte(path))
+	mac.Write([]byte(exp))
+	return mac.Sum(nil)
+}
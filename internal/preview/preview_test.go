@@ -0,0 +1,58 @@
+package preview
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"vellum.forge/internal/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret := "test-secret-key"
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	expires := now.Add(time.Hour)
+
+	t.Run("Accepts a token for the signed path before it expires", func(t *testing.T) {
+		token := Sign("/blog/draft", expires, secret)
+		assert.Nil(t, Verify("/blog/draft", token, now, secret))
+	})
+
+	t.Run("Rejects a token used for another path", func(t *testing.T) {
+		token := Sign("/blog/draft", expires, secret)
+		assert.Equal(t, Verify("/blog/other", token, now, secret), ErrInvalidToken)
+	})
+
+	t.Run("Rejects a token signed with another key", func(t *testing.T) {
+		token := Sign("/blog/draft", expires, "another-key")
+		assert.Equal(t, Verify("/blog/draft", token, now, secret), ErrInvalidToken)
+	})
+
+	t.Run("Rejects a token with a tampered expiry", func(t *testing.T) {
+		token := Sign("/blog/draft", expires, secret)
+		_, sig, _ := strings.Cut(token, ".")
+		assert.Equal(t, Verify("/blog/draft", "9999999999."+sig, now, secret), ErrInvalidToken)
+	})
+
+	t.Run("Rejects an expired token", func(t *testing.T) {
+		token := Sign("/blog/draft", expires, secret)
+		assert.Equal(t, Verify("/blog/draft", token, expires, secret), ErrExpiredToken)
+	})
+
+	t.Run("Rejects malformed tokens", func(t *testing.T) {
+		for _, token := range []string{"", "nodot", "abc.def", "123.!!!"} {
+			assert.Equal(t, Verify("/blog/draft", token, now, secret), ErrInvalidToken)
+		}
+	})
+}
+
+func TestURL(t *testing.T) {
+	link := URL("https://example.com/", "/blog/draft", time.Now().Add(time.Hour), "secret")
+
+	u, err := url.Parse(link)
+	assert.Nil(t, err)
+	assert.Equal(t, u.Host, "example.com")
+	assert.Equal(t, u.Path, "/blog/draft")
+	assert.Nil(t, Verify(u.Path, u.Query().Get(QueryParam), time.Now(), "secret"))
+}
@@ -0,0 +1,192 @@
+package redirect
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// statuses are the response codes a rule may use. Netlify's rewrites (200) and custom
+// 404s aren't supported.
+var statuses = []int{
+	http.StatusMovedPermanently,
+	http.StatusFound,
+	http.StatusSeeOther,
+	http.StatusTemporaryRedirect,
+	http.StatusPermanentRedirect,
+	http.StatusGone,
+}
+
+// Rule sends requests for paths matching From to To
+type Rule struct {
+	From   string // Path pattern, where :name matches one segment and a trailing * everything below
+	To     string // Path or URL, where :name and :splat are replaced by what they matched; empty for 410
+	Status int
+	Line   int // 1-based line of the rule in its file
+}
+
+// Rules is a redirects table, checked in order
+type Rules []Rule
+
+// Match returns the target and status of the first rule matching path
+func (rs Rules) Match(path string) (to string, status int, ok bool) {
+	segments := splitPath(path)
+	for _, rule := range rs {
+		params, ok := rule.match(segments)
+		if !ok {
+			continue
+		}
+		return rule.target(params), rule.Status, true
+	}
+	return "", 0, false
+}
+
+// match reports whether the path segments match the rule, returning the placeholder values
+func (r Rule) match(segments []string) (map[string]string, bool) {
+	pattern := splitPath(r.From)
+	params := make(map[string]string)
+
+	for i, part := range pattern {
+		if part == "*" && i == len(pattern)-1 {
+			params["splat"] = strings.Join(segments[min(i, len(segments)):], "/")
+			return params, true
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+		if name, ok := strings.CutPrefix(part, ":"); ok && name != "" {
+			params[name] = segments[i]
+			continue
+		}
+		if part != segments[i] {
+			return nil, false
+		}
+	}
+
+	return params, len(pattern) == len(segments)
+}
+
+// target fills the placeholders of the rule's destination in. Placeholders must make up a
+// whole segment, so /blog/:slug is filled in but /blog/:slug.html is left alone.
+func (r Rule) target(params map[string]string) string {
+	parts := strings.Split(r.To, "/")
+	for i, part := range parts {
+		if name, ok := strings.CutPrefix(part, ":"); ok {
+			if value, found := params[name]; found {
+				parts[i] = value
+			}
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// splitPath returns the segments of a path, ignoring a trailing slash
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// Parse reads rules in the format of Netlify's _redirects files, one per line as
+// "from to [status]" with # starting a comment, for example
+//
+//	/old-post         /blog/new-post
+//	/news/*           /blog/:splat     302
+//	/blog/:year/:slug /blog/:slug      301!
+//	/retired          410
+//
+// The status defaults to 301, and the trailing ! Netlify uses to force a rule is accepted but
+// changes nothing, since rules are only ever checked for paths nothing else serves.
+func Parse(r io.Reader) (Rules, error) {
+	var rules Rules
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		rule, err := parseRule(fields)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rule.Line = line
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// parseRule parses the fields of a single rule
+func parseRule(fields []string) (Rule, error) {
+	rule := Rule{From: fields[0], Status: http.StatusMovedPermanently}
+	if !strings.HasPrefix(rule.From, "/") {
+		return Rule{}, fmt.Errorf("path %q must start with /", rule.From)
+	}
+	if i := strings.Index(rule.From, "*"); i != -1 && (i != len(rule.From)-1 || !strings.HasSuffix(rule.From, "/*")) {
+		return Rule{}, fmt.Errorf("path %q may only end in /*", rule.From)
+	}
+
+	rest := fields[1:]
+	if len(rest) > 0 {
+		if status, ok := parseStatus(rest[len(rest)-1]); ok {
+			rule.Status = status
+			rest = rest[:len(rest)-1]
+		}
+	}
+
+	if !slices.Contains(statuses, rule.Status) {
+		return Rule{}, fmt.Errorf("unsupported status %d", rule.Status)
+	}
+
+	switch {
+	case len(rest) > 1:
+		return Rule{}, fmt.Errorf("unexpected %q, expected from, to and an optional status", rest[1])
+	case len(rest) == 1:
+		rule.To = rest[0]
+	case rule.Status != http.StatusGone:
+		return Rule{}, fmt.Errorf("missing target for %s", rule.From)
+	}
+
+	if rule.To != "" && !strings.HasPrefix(rule.To, "/") && !strings.HasPrefix(rule.To, "http://") && !strings.HasPrefix(rule.To, "https://") {
+		return Rule{}, fmt.Errorf("target %q must be a path or an http(s) URL", rule.To)
+	}
+
+	return rule, nil
+}
+
+// parseStatus reads a status field, which may end in Netlify's ! force marker
+func parseStatus(field string) (int, bool) {
+	status, err := strconv.Atoi(strings.TrimSuffix(field, "!"))
+	if err != nil {
+		return 0, false
+	}
+	return status, true
+}
+
+// LoadFile reads the rules of a _redirects file
+func LoadFile(path string) (Rules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redirects file %s: %w", path, err)
+	}
+	return rules, nil
+}
@@ -0,0 +1,83 @@
+package redirect
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"vellum.forge/internal/assert"
+)
+
+func TestParse(t *testing.T) {
+	rules, err := Parse(strings.NewReader("# Moved posts\n/old-post  /blog/new-post\n\n/news/*  /blog/:splat  302\n/blog/:year/:slug  /blog/:slug  301!  # dated URLs\n/retired  410\n/docs/*  https://docs.example.com/:splat  308\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, rules, Rules{
+		{From: "/old-post", To: "/blog/new-post", Status: http.StatusMovedPermanently, Line: 2},
+		{From: "/news/*", To: "/blog/:splat", Status: http.StatusFound, Line: 4},
+		{From: "/blog/:year/:slug", To: "/blog/:slug", Status: http.StatusMovedPermanently, Line: 5},
+		{From: "/retired", Status: http.StatusGone, Line: 6},
+		{From: "/docs/*", To: "https://docs.example.com/:splat", Status: http.StatusPermanentRedirect, Line: 7},
+	})
+
+	for _, source := range []string{
+		"old /new",
+		"/old",
+		"/old /new 200",
+		"/old /new 302 extra",
+		"/old/*/more /new",
+		"/old new",
+	} {
+		_, err := Parse(strings.NewReader("# Comment\n" + source))
+		if err == nil || !strings.HasPrefix(err.Error(), "line 2: ") {
+			t.Errorf("Expected an error on line 2 for %q, got %v", source, err)
+		}
+	}
+}
+
+func TestRules_Match(t *testing.T) {
+	rules, err := Parse(strings.NewReader("/old-post /blog/new-post\n/news/* /blog/:splat 302\n/blog/:year/:slug /blog/:slug\n/retired 410\n/* /fallback\n"))
+	assert.Nil(t, err)
+
+	tests := []struct {
+		path   string
+		to     string
+		status int
+	}{
+		{"/old-post", "/blog/new-post", http.StatusMovedPermanently},
+		{"/old-post/", "/blog/new-post", http.StatusMovedPermanently},
+		{"/news/2024/launch", "/blog/2024/launch", http.StatusFound},
+		{"/news", "/blog/", http.StatusFound},
+		{"/blog/2024/hello", "/blog/hello", http.StatusMovedPermanently},
+		{"/retired", "", http.StatusGone},
+		{"/blog/hello", "/fallback", http.StatusMovedPermanently},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			to, status, ok := rules.Match(tt.path)
+			assert.True(t, ok)
+			assert.Equal(t, to, tt.to)
+			assert.Equal(t, status, tt.status)
+		})
+	}
+
+	_, _, ok := Rules(rules[:4]).Match("/blog/hello")
+	assert.False(t, ok)
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "_redirects")
+	err := os.WriteFile(path, []byte("/old /new\n"), 0o644)
+	assert.Nil(t, err)
+
+	rules, err := LoadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, len(rules), 1)
+
+	err = os.WriteFile(path, []byte("/old\n"), 0o644)
+	assert.Nil(t, err)
+	_, err = LoadFile(path)
+	assert.NotNil(t, err)
+}
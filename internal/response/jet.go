@@ -1,23 +1,29 @@
 package response
 
 import (
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
+	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/CloudyKit/jet/v6"
 	"vellum.forge/assets"
+	"vellum.forge/internal/i18n"
 	"vellum.forge/internal/version"
 )
 
 // JetRenderer handles Jet template rendering with theme directory support
 type JetRenderer struct {
-	views *jet.Set
+	views        *jet.Set
+	loader       jet.Loader
+	translations *i18n.Bundle
 }
 
 // NewJetRenderer creates a new Jet template renderer with fallback support
@@ -28,8 +34,14 @@ func NewJetRenderer(themeDir string) (*JetRenderer, error) {
 
 	// Create a fallback loader that tries the current theme first, then default
 	loader := &fallbackLoader{
-		primaryDir:   themeDir,
-		fallbackDir:  defaultThemeDir,
+		primaryDir:  themeDir,
+		fallbackDir: defaultThemeDir,
+	}
+
+	// Load the theme's translations over the default theme's
+	translations, err := i18n.Load(filepath.Join(defaultThemeDir, "i18n"), filepath.Join(themeDir, "i18n"))
+	if err != nil {
+		return nil, err
 	}
 
 	// Create a new Jet set with the fallback loader
@@ -39,10 +51,12 @@ func NewJetRenderer(themeDir string) (*JetRenderer, error) {
 	)
 
 	// Add custom functions with proper types
-	addJetFunctions(views)
+	addJetFunctions(views, translations.Locale(i18n.SourceLanguage))
 
 	return &JetRenderer{
-		views: views,
+		views:        views,
+		loader:       loader,
+		translations: translations,
 	}, nil
 }
 
@@ -56,16 +70,28 @@ func NewJetRendererWithEmbedded() (*JetRenderer, error) {
 		jet.InDevelopmentMode(),
 	)
 
+	// Embedded templates have no catalogs, only the built-in English messages
+	translations := i18n.New()
+
 	// Add custom functions with proper types
-	addJetFunctions(views)
+	addJetFunctions(views, translations.Locale(i18n.SourceLanguage))
 
 	return &JetRenderer{
-		views: views,
+		views:        views,
+		loader:       loader,
+		translations: translations,
 	}, nil
 }
 
-// addJetFunctions adds all the custom functions to the Jet view set
-func addJetFunctions(views *jet.Set) {
+// Locale returns the theme's translations into lang, whose T, FormatDate, HumanizeTime and
+// FormatNumber replace the English globals when set as template variables
+func (jr *JetRenderer) Locale(lang string) *i18n.Locale {
+	return jr.translations.Locale(lang)
+}
+
+// addJetFunctions adds all the custom functions to the Jet view set, formatting messages,
+// dates and numbers with locale until a page sets its own
+func addJetFunctions(views *jet.Set, locale *i18n.Locale) {
 	// Basic utility functions
 	views.AddGlobal("version", func() string {
 		return version.Get()
@@ -75,15 +101,11 @@ func addJetFunctions(views *jet.Set) {
 		return time.Now()
 	})
 
-	// Date/time formatting functions
-	views.AddGlobal("formatDate", func(t time.Time, layout string) string {
-		return t.Format(layout)
-	})
-
-	views.AddGlobal("humanizeTime", func(t time.Time) string {
-		duration := time.Since(t)
-		return approxDuration(duration) + " ago"
-	})
+	// Translation, date/time and number formatting functions
+	views.AddGlobal("T", locale.T)
+	views.AddGlobal("formatDate", locale.FormatDate)
+	views.AddGlobal("humanizeTime", locale.HumanizeTime)
+	views.AddGlobal("formatNumber", locale.FormatNumber)
 
 	// String functions
 	views.AddGlobal("safeHTML", func(s string) template.HTML {
@@ -98,49 +120,6 @@ func addJetFunctions(views *jet.Set) {
 	})
 }
 
-// approxDuration returns a human-readable approximation of a duration
-func approxDuration(d time.Duration) string {
-	const (
-		day  = 24 * time.Hour
-		year = 365 * day
-	)
-
-	switch {
-	case d >= year:
-		years := int(d / year)
-		if years == 1 {
-			return "1 year"
-		}
-		return fmt.Sprintf("%d years", years)
-	case d >= day:
-		days := int(d / day)
-		if days == 1 {
-			return "1 day"
-		}
-		return fmt.Sprintf("%d days", days)
-	case d >= time.Hour:
-		hours := int(d / time.Hour)
-		if hours == 1 {
-			return "1 hour"
-		}
-		return fmt.Sprintf("%d hours", hours)
-	case d >= time.Minute:
-		minutes := int(d / time.Minute)
-		if minutes == 1 {
-			return "1 minute"
-		}
-		return fmt.Sprintf("%d minutes", minutes)
-	case d >= time.Second:
-		seconds := int(d / time.Second)
-		if seconds == 1 {
-			return "1 second"
-		}
-		return fmt.Sprintf("%d seconds", seconds)
-	default:
-		return "less than 1 second"
-	}
-}
-
 // RenderPage renders a page template with the given data
 func (jr *JetRenderer) RenderPage(w http.ResponseWriter, status int, data any, templatePath string) error {
 	return jr.RenderPageWithHeaders(w, status, data, nil, templatePath)
@@ -204,6 +183,66 @@ func (jr *JetRenderer) RenderPartial(w io.Writer, templatePath string, data any)
 	return tmpl.Execute(w, vars, nil)
 }
 
+// HasShortcode reports whether the theme, or the default theme, has a shortcodes/{name}.jet template
+func (jr *JetRenderer) HasShortcode(name string) bool {
+	return jr.loader.Exists(shortcodeTemplate(name))
+}
+
+// RenderShortcode renders shortcodes/{name}.jet with the call as the template context,
+// so templates read arguments with .Arg(0) and .Get("name") and paired content with .Inner
+func (jr *JetRenderer) RenderShortcode(w io.Writer, name string, call any) error {
+	tmpl, err := jr.views.GetTemplate(shortcodeTemplate(name))
+	if err != nil {
+		return fmt.Errorf("failed to load shortcode template %s: %w", name, err)
+	}
+
+	return tmpl.Execute(w, nil, call)
+}
+
+func shortcodeTemplate(name string) string {
+	return "shortcodes/" + name + ".jet"
+}
+
+// templateLister is implemented by loaders that can list every template they hold
+type templateLister interface {
+	templates() ([]string, error)
+}
+
+// CompileAll compiles every template the renderer can load, returning each template name
+// with its compile error, nil for those that compile
+func (jr *JetRenderer) CompileAll() (map[string]error, error) {
+	lister, ok := jr.loader.(templateLister)
+	if !ok {
+		return nil, fmt.Errorf("template loader %T cannot list its templates", jr.loader)
+	}
+
+	names, err := lister.templates()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]error, len(names))
+	for _, name := range names {
+		_, results[name] = jr.views.GetTemplate(name)
+	}
+	return results, nil
+}
+
+// listTemplates returns the names of the .jet files under dir, relative to it
+func listTemplates(fsys fs.FS, dir string) ([]string, error) {
+	var names []string
+	err := fs.WalkDir(fsys, dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".jet") {
+			names = append(names, strings.TrimPrefix(path, dir+"/"))
+		}
+		return nil
+	})
+	return names, err
+}
+
 // fallbackLoader implements jet.Loader with fallback support
 // It tries to load templates from the primary directory first,
 // then falls back to a default directory if not found
@@ -230,6 +269,27 @@ func (l *fallbackLoader) Open(name string) (io.ReadCloser, error) {
 	return file, nil
 }
 
+// templates lists the templates of both directories, those of the primary directory overriding
+// the fallback ones of the same name
+func (l *fallbackLoader) templates() ([]string, error) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range []string{l.primaryDir, l.fallbackDir} {
+		found, err := listTemplates(os.DirFS(dir), ".")
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		for _, name := range found {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 func (l *fallbackLoader) Exists(name string) bool {
 	// Check primary directory
 	primaryPath := filepath.Join(l.primaryDir, name)
@@ -263,6 +323,10 @@ func (l *embeddedJetLoader) Open(name string) (io.ReadCloser, error) {
 	return file, nil
 }
 
+func (l *embeddedJetLoader) templates() ([]string, error) {
+	return listTemplates(assets.EmbeddedFiles, "templates")
+}
+
 func (l *embeddedJetLoader) Exists(name string) bool {
 	// Ensure the path starts with templates/
 	if !strings.HasPrefix(name, "templates/") {
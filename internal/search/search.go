@@ -0,0 +1,248 @@
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Field is a part of a document, matches in some fields count for more than others
+type Field int
+
+const (
+	FieldTitle Field = iota
+	FieldTags
+	FieldDescription
+	FieldBody
+)
+
+// fieldWeights is how much a term occurrence counts for in each field
+var fieldWeights = map[Field]float64{
+	FieldTitle:       5,
+	FieldTags:        3,
+	FieldDescription: 2,
+	FieldBody:        1,
+}
+
+const (
+	// prefixWeight scales the score of a term matched only by prefix
+	prefixWeight = 0.5
+
+	// minPrefixLength is the shortest query word also matched as a prefix
+	minPrefixLength = 2
+
+	// maxPrefixTerms caps how many indexed terms a single prefix can expand to
+	maxPrefixTerms = 50
+
+	// DefaultLimit is the number of hits returned when no limit is given
+	DefaultLimit = 20
+
+	// SnippetWords is the length of the text excerpt returned with each hit
+	SnippetWords = 30
+)
+
+// Document is the searchable text of a post or page
+type Document struct {
+	Title       string
+	Description string
+	Tags        []string
+	Body        string // Plain text, used for matching and snippets
+}
+
+// Hit is a document matching a query
+type Hit struct {
+	Doc     int     // Position of the document in the slice passed to NewIndex
+	Score   float64 // Higher is more relevant
+	Snippet string  // HTML-escaped excerpt of the body with matches wrapped in <mark>
+}
+
+// Index is an in-memory inverted index over a fixed set of documents.
+// It is immutable once built and safe for concurrent use.
+type Index struct {
+	docs     []Document
+	postings map[string]map[int]float64 // stemmed term to the field-weighted frequency in each document
+	terms    []string                   // every indexed term, sorted for prefix lookups
+}
+
+// NewIndex tokenizes, stems and indexes the documents
+func NewIndex(docs []Document) *Index {
+	idx := &Index{docs: docs, postings: make(map[string]map[int]float64)}
+
+	for i, doc := range docs {
+		idx.add(i, FieldTitle, doc.Title)
+		idx.add(i, FieldTags, strings.Join(doc.Tags, " "))
+		idx.add(i, FieldDescription, doc.Description)
+		idx.add(i, FieldBody, doc.Body)
+	}
+
+	idx.terms = make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		idx.terms = append(idx.terms, term)
+	}
+	sort.Strings(idx.terms)
+
+	return idx
+}
+
+func (idx *Index) add(doc int, field Field, text string) {
+	for _, word := range Tokenize(text) {
+		term := Stem(word)
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[int]float64)
+		}
+		idx.postings[term][doc] += fieldWeights[field]
+	}
+}
+
+// Len returns the number of indexed documents
+func (idx *Index) Len() int {
+	return len(idx.docs)
+}
+
+// Search returns up to limit documents matching every word of the query, best first,
+// or up to DefaultLimit if limit is not positive.
+// Each query word matches its stemmed form exactly or, at a lower score, any indexed term
+// it is a prefix of, so results appear while a word is still being typed.
+func (idx *Index) Search(query string, limit int) []Hit {
+	words := Tokenize(query)
+	if len(words) == 0 {
+		return nil
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	var scores map[int]float64
+	for _, word := range words {
+		wordScores := idx.scoreWord(word)
+
+		if scores == nil {
+			scores = wordScores
+			continue
+		}
+
+		// Only keep documents matching every word so far
+		for doc, score := range scores {
+			if s, ok := wordScores[doc]; ok {
+				scores[doc] = score + s
+			} else {
+				delete(scores, doc)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for doc, score := range scores {
+		hits = append(hits, Hit{Doc: doc, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Doc < hits[j].Doc
+	})
+
+	hits = hits[:min(limit, len(hits))]
+	for i := range hits {
+		hits[i].Snippet = Snippet(idx.docs[hits[i].Doc].Body, words, SnippetWords)
+	}
+
+	return hits
+}
+
+// scoreWord scores every document containing a query word, weighting each term by how rare it is
+func (idx *Index) scoreWord(word string) map[int]float64 {
+	scores := make(map[int]float64)
+
+	match := func(term string, weight float64) {
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			return
+		}
+		idf := math.Log(1 + float64(len(idx.docs))/float64(len(postings)))
+		for doc, tf := range postings {
+			scores[doc] = max(scores[doc], tf*idf*weight)
+		}
+	}
+
+	stem := Stem(word)
+	match(stem, 1)
+
+	if len([]rune(word)) >= minPrefixLength {
+		start := sort.SearchStrings(idx.terms, word)
+		for i := start; i < len(idx.terms) && i-start < maxPrefixTerms && strings.HasPrefix(idx.terms[i], word); i++ {
+			if idx.terms[i] != stem {
+				match(idx.terms[i], prefixWeight)
+			}
+		}
+	}
+
+	return scores
+}
+
+// Tokenize splits text into lowercase words of letters and digits
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// Snippet returns about n words of text around the first word matching a query word,
+// HTML-escaped with every matching word wrapped in <mark>
+func Snippet(text string, queryWords []string, n int) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	stems := make(map[string]bool, len(queryWords))
+	for _, word := range queryWords {
+		stems[Stem(word)] = true
+	}
+
+	matches := func(field string) bool {
+		for _, word := range Tokenize(field) {
+			if stems[Stem(word)] {
+				return true
+			}
+			for _, q := range queryWords {
+				if len([]rune(q)) >= minPrefixLength && strings.HasPrefix(word, q) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	// Start a little before the first match so it has some context
+	start := 0
+	for i, field := range fields {
+		if matches(field) {
+			start = max(0, min(i-n/3, len(fields)-n))
+			break
+		}
+	}
+	end := min(start+n, len(fields))
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("… ")
+	}
+	for i, field := range fields[start:end] {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		if matches(field) {
+			b.WriteString("<mark>" + html.EscapeString(field) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(field))
+		}
+	}
+	if end < len(fields) {
+		b.WriteString(" …")
+	}
+
+	return b.String()
+}
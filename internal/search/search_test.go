@@ -0,0 +1,107 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		words []string
+		stem  string
+	}{
+		{[]string{"cache", "caches", "cached", "caching"}, "cach"},
+		{[]string{"run", "runs", "running"}, "run"},
+		{[]string{"story", "stories"}, "story"},
+		{[]string{"make", "makes", "making"}, "mak"},
+		{[]string{"class"}, "class"},
+		{[]string{"status"}, "status"},
+		{[]string{"go"}, "go"},
+	}
+
+	for _, tt := range tests {
+		for _, word := range tt.words {
+			if got := Stem(word); got != tt.stem {
+				t.Errorf("Stem(%q) = %q, want %q", word, got, tt.stem)
+			}
+		}
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex([]Document{
+		{Title: "Caching in Go", Tags: []string{"go", "performance"}, Body: "An LRU cache keeps hot pages in memory."},
+		{Title: "Concurrency", Description: "Goroutines and channels", Body: "Channels let goroutines share memory by communicating."},
+		{Title: "About", Body: "This site talks about Go, caching and more."},
+	})
+
+	docs := func(hits []Hit) []int {
+		var docs []int
+		for _, hit := range hits {
+			docs = append(docs, hit.Doc)
+		}
+		return docs
+	}
+
+	t.Run("Matches stemmed words and ranks title matches first", func(t *testing.T) {
+		got := docs(idx.Search("cached", 10))
+		if len(got) != 2 || got[0] != 0 || got[1] != 2 {
+			t.Errorf("Expected documents [0 2], got %v", got)
+		}
+	})
+
+	t.Run("Requires every query word", func(t *testing.T) {
+		got := docs(idx.Search("memory goroutines", 10))
+		if len(got) != 1 || got[0] != 1 {
+			t.Errorf("Expected document [1], got %v", got)
+		}
+	})
+
+	t.Run("Matches prefixes", func(t *testing.T) {
+		got := docs(idx.Search("concur", 10))
+		if len(got) != 1 || got[0] != 1 {
+			t.Errorf("Expected document [1], got %v", got)
+		}
+	})
+
+	t.Run("Limits the number of hits", func(t *testing.T) {
+		if got := idx.Search("go", 1); len(got) != 1 {
+			t.Errorf("Expected 1 hit, got %d", len(got))
+		}
+	})
+
+	t.Run("Returns nothing for empty or unknown queries", func(t *testing.T) {
+		if got := idx.Search("  ", 10); len(got) != 0 {
+			t.Errorf("Expected no hits for an empty query, got %v", got)
+		}
+		if got := idx.Search("rust", 10); len(got) != 0 {
+			t.Errorf("Expected no hits for an unknown word, got %v", got)
+		}
+	})
+
+	t.Run("Highlights matches in the snippet", func(t *testing.T) {
+		hits := idx.Search("channel", 10)
+		if len(hits) != 1 {
+			t.Fatalf("Expected 1 hit, got %d", len(hits))
+		}
+		if want := "<mark>Channels</mark> let"; !strings.Contains(hits[0].Snippet, want) {
+			t.Errorf("Expected snippet to contain %q, got %q", want, hits[0].Snippet)
+		}
+	})
+}
+
+func TestSnippet(t *testing.T) {
+	text := strings.Repeat("filler ", 40) + "the <needle> is here " + strings.Repeat("filler ", 40)
+
+	got := Snippet(text, []string{"needle"}, 10)
+
+	if !strings.HasPrefix(got, "… ") || !strings.HasSuffix(got, " …") {
+		t.Errorf("Expected ellipses around a snippet from the middle of the text, got %q", got)
+	}
+	if !strings.Contains(got, "<mark>&lt;needle&gt;</mark>") {
+		t.Errorf("Expected the escaped match to be highlighted, got %q", got)
+	}
+	if n := len(strings.Fields(strings.Trim(got, "… "))); n != 10 {
+		t.Errorf("Expected 10 words, got %d in %q", n, got)
+	}
+}
@@ -0,0 +1,70 @@
+package search
+
+import "strings"
+
+// suffixes are stripped by Stem, longest first, as long as enough of the word is left
+var suffixes = []struct {
+	suffix      string
+	replacement string
+}{
+	{"ational", "ate"},
+	{"ization", "ize"},
+	{"fulness", "ful"},
+	{"iveness", "ive"},
+	{"ations", "ate"},
+	{"ation", "ate"},
+	{"ments", ""},
+	{"ment", ""},
+	{"ness", ""},
+	{"sses", "ss"},
+	{"ies", "y"},
+	{"ing", ""},
+	{"ed", ""},
+	{"ly", ""},
+	{"s", ""},
+}
+
+// minStemLength is the shortest stem a suffix can be stripped down to
+const minStemLength = 3
+
+// Stem reduces a lowercase English word to a stem shared by its inflections,
+// so "caching", "cached" and "caches" all become "cach". It is a light
+// suffix-stripping stemmer rather than a full Porter implementation.
+func Stem(word string) string {
+	for _, s := range suffixes {
+		stem, found := strings.CutSuffix(word, s.suffix)
+		if !found || len(stem) < minStemLength || (s.suffix == "s" && keepsFinalS(stem)) {
+			continue
+		}
+		if (s.suffix == "ing" || s.suffix == "ed") && !strings.ContainsAny(stem, "aeiouy") {
+			continue
+		}
+
+		word = stem + s.replacement
+		if s.suffix == "ing" || s.suffix == "ed" {
+			word = undouble(word)
+		}
+		break
+	}
+
+	// Drop a silent e so "make" and "making" share a stem
+	if len(word) > minStemLength && strings.HasSuffix(word, "e") {
+		word = word[:len(word)-1]
+	}
+
+	return word
+}
+
+// keepsFinalS reports whether the s after stem belongs to the word rather than marking a plural
+func keepsFinalS(stem string) bool {
+	return strings.HasSuffix(stem, "s") || strings.HasSuffix(stem, "u") || strings.HasSuffix(stem, "i")
+}
+
+// undouble turns the doubled final consonant left by "running" or "stopped" into a single one
+func undouble(word string) string {
+	n := len(word)
+	if n < 2 || word[n-1] != word[n-2] || strings.IndexByte("aeiouylsz", word[n-1]) >= 0 {
+		return word
+	}
+	return word[:n-1]
+}
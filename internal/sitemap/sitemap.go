@@ -3,6 +3,8 @@ package sitemap
 import (
 	"encoding/xml"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"vellum.forge/internal/content"
@@ -10,19 +12,31 @@ import (
 
 // URLSet represents the root element of a sitemap
 type URLSet struct {
-	XMLName xml.Name `xml:"urlset"`
-	XMLNS   string   `xml:"xmlns,attr"`
-	URLs    []*URL   `xml:"url"`
+	XMLName    xml.Name `xml:"urlset"`
+	XMLNS      string   `xml:"xmlns,attr"`
+	XMLNSXHTML string   `xml:"xmlns:xhtml,attr,omitempty"`
+	URLs       []*URL   `xml:"url"`
 }
 
 // URL represents a single URL entry in the sitemap
 type URL struct {
-	Loc        string  `xml:"loc"`
-	LastMod    string  `xml:"lastmod,omitempty"`
-	ChangeFreq string  `xml:"changefreq,omitempty"`
-	Priority   float64 `xml:"priority,omitempty"`
+	Loc        string           `xml:"loc"`
+	LastMod    string           `xml:"lastmod,omitempty"`
+	ChangeFreq string           `xml:"changefreq,omitempty"`
+	Priority   float64          `xml:"priority,omitempty"`
+	Alternates []*AlternateLink `xml:"xhtml:link"`
 }
 
+// AlternateLink represents an xhtml:link element pointing to the same page in another language
+type AlternateLink struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+// XDefault is the hreflang of the version of a page for visitors in no listed language
+const XDefault = "x-default"
+
 // ChangeFreq represents how frequently a page is likely to change
 type ChangeFreq string
 
@@ -47,6 +61,14 @@ type SitemapEntry struct {
 	LastMod    time.Time
 	ChangeFreq ChangeFreq
 	Priority   float64
+	Lang       string
+	Alternates []Alternate // The entry in every language, itself included
+}
+
+// Alternate is the URL of a page in one language
+type Alternate struct {
+	Lang string
+	URL  string
 }
 
 // GenerateSitemap creates an XML sitemap from the provided entries
@@ -70,6 +92,11 @@ func GenerateSitemap(entries []*SitemapEntry) ([]byte, error) {
 			url.ChangeFreq = string(entry.ChangeFreq)
 		}
 
+		for _, alternate := range entry.Alternates {
+			url.Alternates = append(url.Alternates, &AlternateLink{Rel: "alternate", Hreflang: alternate.Lang, Href: alternate.URL})
+			urlset.XMLNSXHTML = "http://www.w3.org/1999/xhtml"
+		}
+
 		urlset.URLs = append(urlset.URLs, url)
 	}
 
@@ -112,8 +139,8 @@ func BuildSitemapFromContent(
 
 	// Add blog posts
 	for _, post := range posts {
-		// Skip draft posts
-		if post.Frontmatter.Draft {
+		// Skip drafts, scheduled and expired posts
+		if !post.IsPublished(time.Now()) {
 			continue
 		}
 
@@ -129,8 +156,8 @@ func BuildSitemapFromContent(
 
 	// Add static pages
 	for _, page := range pages {
-		// Skip draft pages
-		if page.Frontmatter.Draft {
+		// Skip drafts, scheduled and expired pages
+		if !page.IsPublished(time.Now()) {
 			continue
 		}
 
@@ -175,6 +202,55 @@ func AddAuthorPages(baseURL string, authors []string, lastMod time.Time, entries
 	return entries
 }
 
+// SetLanguage marks entries as being in lang
+func SetLanguage(entries []*SitemapEntry, lang string) {
+	for _, entry := range entries {
+		entry.Lang = lang
+	}
+}
+
+// LinkAlternates gives entries in different languages that are the same page the URLs of each
+// other as alternates. Pages are matched by their URL under baseURL once the prefix of their
+// language, given in prefixes, is removed, so /fr/blog/hello is /blog/hello in French. The
+// entry in defaultLang, if any, is also the x-default.
+func LinkAlternates(baseURL, defaultLang string, prefixes map[string]string, entries []*SitemapEntry) {
+	translations := make(map[string][]*SitemapEntry)
+	var paths []string
+	for _, entry := range entries {
+		path := strings.TrimPrefix(entry.URL, baseURL+prefixes[entry.Lang])
+		if path == "" {
+			path = "/"
+		}
+		if _, found := translations[path]; !found {
+			paths = append(paths, path)
+		}
+		translations[path] = append(translations[path], entry)
+	}
+
+	for _, path := range paths {
+		group := translations[path]
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Lang < group[j].Lang })
+
+		alternates := make([]Alternate, 0, len(group)+1)
+		for _, entry := range group {
+			alternates = append(alternates, Alternate{Lang: entry.Lang, URL: entry.URL})
+		}
+		for _, entry := range group {
+			if entry.Lang == defaultLang {
+				alternates = append(alternates, Alternate{Lang: XDefault, URL: entry.URL})
+				break
+			}
+		}
+
+		for _, entry := range group {
+			entry.Alternates = alternates
+		}
+	}
+}
+
 // formatW3CDatetime formats a time.Time to W3C datetime format (required by sitemaps)
 // Format: YYYY-MM-DD or YYYY-MM-DDThh:mm:ss+00:00
 func formatW3CDatetime(t time.Time) string {
@@ -2,6 +2,7 @@ package sitemap
 
 import (
 	"encoding/xml"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -248,3 +249,54 @@ func TestFormatW3CDatetime(t *testing.T) {
 		t.Errorf("Expected empty string for zero time, got %s", zeroFormatted)
 	}
 }
+
+func TestLinkAlternates(t *testing.T) {
+	baseURL := "https://example.com"
+	en := []*SitemapEntry{
+		{URL: baseURL + "/"},
+		{URL: baseURL + "/blog/hello"},
+		{URL: baseURL + "/about"},
+	}
+	fr := []*SitemapEntry{
+		{URL: baseURL + "/fr/"},
+		{URL: baseURL + "/fr/blog/hello"},
+		{URL: baseURL + "/fr/blog/salut"},
+	}
+	SetLanguage(en, "en")
+	SetLanguage(fr, "fr")
+
+	entries := append(en, fr...)
+	LinkAlternates(baseURL, "en", map[string]string{"en": "", "fr": "/fr"}, entries)
+
+	want := []Alternate{
+		{Lang: "en", URL: baseURL + "/blog/hello"},
+		{Lang: "fr", URL: baseURL + "/fr/blog/hello"},
+		{Lang: XDefault, URL: baseURL + "/blog/hello"},
+	}
+	for _, entry := range []*SitemapEntry{en[1], fr[1]} {
+		if !slices.Equal(entry.Alternates, want) {
+			t.Errorf("Expected alternates %v for %s, got %v", want, entry.URL, entry.Alternates)
+		}
+	}
+	if len(en[0].Alternates) != 3 || en[0].Alternates[1].URL != baseURL+"/fr/" {
+		t.Errorf("Expected the home pages to be alternates, got %v", en[0].Alternates)
+	}
+	if en[2].Alternates != nil || fr[2].Alternates != nil {
+		t.Error("Expected no alternates for pages in one language")
+	}
+
+	sitemapData, err := GenerateSitemap(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := string(sitemapData)
+	for _, want := range []string{
+		`xmlns:xhtml="http://www.w3.org/1999/xhtml"`,
+		`<xhtml:link rel="alternate" hreflang="fr" href="https://example.com/fr/blog/hello"></xhtml:link>`,
+		`<xhtml:link rel="alternate" hreflang="x-default" href="https://example.com/blog/hello"></xhtml:link>`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected sitemap to contain %s, got:\n%s", want, output)
+		}
+	}
+}